@@ -0,0 +1,227 @@
+package extension
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/internal/system"
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+type extensionRunResult struct {
+	Path     string        `json:"path"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+func pathExists(p string) bool {
+	_, err := os.Stat(p)
+
+	return err == nil
+}
+
+// resolveExtensionPaths expands the arguments into absolute extension paths.
+// An argument can be an extension folder, a zip file, a glob pattern or a Shopware project.
+func resolveExtensionPaths(ctx context.Context, args []string) ([]string, error) {
+	paths := make([]string, 0, len(args))
+	seen := make(map[string]struct{})
+
+	add := func(p string) error {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := seen[abs]; !ok {
+			seen[abs] = struct{}{}
+			paths = append(paths, abs)
+		}
+
+		return nil
+	}
+
+	for _, arg := range args {
+		matches := []string{arg}
+
+		if strings.ContainsAny(arg, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+			}
+
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("pattern %s matches no files", arg)
+			}
+		}
+
+		for _, match := range matches {
+			stat, err := os.Stat(match)
+			if err != nil {
+				return nil, fmt.Errorf("cannot find path: %w", err)
+			}
+
+			if !stat.IsDir() || !isShopwareProject(match) {
+				if err := add(match); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			projectExtensions, err := findLocalExtensionsOfProject(ctx, match)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range projectExtensions {
+				if err := add(p); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// isShopwareProject checks whether the folder is a Shopware project instead of a single extension.
+func isShopwareProject(dir string) bool {
+	if _, err := extension.GetExtensionByFolder(dir); err == nil {
+		return false
+	}
+
+	return pathExists(filepath.Join(dir, "custom", "plugins")) || pathExists(filepath.Join(dir, "custom", "apps"))
+}
+
+// findLocalExtensionsOfProject returns the extensions of a project, skipping third-party extensions installed into vendor.
+func findLocalExtensionsOfProject(ctx context.Context, project string) ([]string, error) {
+	vendorDir, err := filepath.Abs(filepath.Join(project, "vendor"))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+
+	for _, ext := range extension.FindExtensionsFromProject(ctx, project) {
+		extPath, err := filepath.EvalSymlinks(ext.GetPath())
+		if err != nil {
+			return nil, err
+		}
+
+		if extPath, err = filepath.Abs(extPath); err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(extPath, vendorDir+string(os.PathSeparator)) {
+			continue
+		}
+
+		paths = append(paths, extPath)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no extensions found in project %s", project)
+	}
+
+	return paths, nil
+}
+
+// useSharedPackageCaches points npm and composer of all parallel runs to the same cache folders.
+func useSharedPackageCaches() {
+	cacheDir := system.GetShopwareCliCacheDir()
+
+	if os.Getenv("npm_config_cache") == "" {
+		_ = os.Setenv("npm_config_cache", filepath.Join(cacheDir, "npm"))
+	}
+
+	if os.Getenv("COMPOSER_CACHE_DIR") == "" {
+		_ = os.Setenv("COMPOSER_CACHE_DIR", filepath.Join(cacheDir, "composer"))
+	}
+}
+
+// runForExtensions runs fn for all paths with at most parallel concurrent workers.
+// A failing extension does not stop the others, the errors are part of the returned results.
+func runForExtensions(ctx context.Context, paths []string, parallel int, fn func(ctx context.Context, extPath string) (string, error)) []extensionRunResult {
+	results := make([]extensionRunResult, len(paths))
+
+	var gr errgroup.Group
+	gr.SetLimit(max(parallel, 1))
+
+	for i, extPath := range paths {
+		gr.Go(func() error {
+			start := time.Now()
+
+			output, err := fn(ctx, extPath)
+
+			results[i] = extensionRunResult{
+				Path:     extPath,
+				Output:   output,
+				Duration: time.Since(start).Round(time.Millisecond),
+			}
+
+			if err != nil {
+				results[i].Error = err.Error()
+				logging.FromContext(ctx).Errorf("%s: %s", extPath, err.Error())
+			}
+
+			return nil
+		})
+	}
+
+	_ = gr.Wait()
+
+	return results
+}
+
+// writeExtensionRunReport prints a summary of all runs and optionally writes them as JSON to reportFile.
+func writeExtensionRunReport(out io.Writer, results []extensionRunResult, reportFile string) error {
+	t := table.NewWriter(out)
+	t.Header([]string{"Extension", "Status", "Duration", "Result"})
+
+	failed := 0
+
+	for _, result := range results {
+		status := "ok"
+		message := result.Output
+
+		if result.Error != "" {
+			status = "failed"
+			message = result.Error
+			failed++
+		}
+
+		_ = t.Append([]string{filepath.Base(result.Path), status, result.Duration.String(), message})
+	}
+
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	if reportFile != "" {
+		content, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal report: %w", err)
+		}
+
+		if err := os.WriteFile(reportFile, content, 0o644); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d extensions failed", failed, len(results))
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package extension
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestPlugin(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	pluginDir := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(pluginDir, os.ModePerm))
+
+	composerJSON := fmt.Sprintf(`{
+	"name": "test/%s",
+	"type": "shopware-platform-plugin",
+	"version": "1.0.0",
+	"require": {"shopware/core": "~6.6.0"},
+	"autoload": {"psr-4": {"%s\\": "src/"}},
+	"extra": {"shopware-plugin-class": "%s\\%s", "label": {"en-GB": "%s"}}
+}`, name, name, name, name, name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "composer.json"), []byte(composerJSON), os.ModePerm))
+
+	return pluginDir
+}
+
+func TestResolveExtensionPaths(t *testing.T) {
+	project := t.TempDir()
+	pluginsDir := filepath.Join(project, "custom", "plugins")
+
+	first := createTestPlugin(t, pluginsDir, "FirstPlugin")
+	second := createTestPlugin(t, pluginsDir, "SecondPlugin")
+
+	paths, err := resolveExtensionPaths(t.Context(), []string{first})
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, paths)
+
+	paths, err = resolveExtensionPaths(t.Context(), []string{filepath.Join(pluginsDir, "*"), first})
+	require.NoError(t, err)
+	assert.Equal(t, []string{first, second}, paths)
+
+	paths, err = resolveExtensionPaths(t.Context(), []string{project})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first, second}, paths)
+
+	_, err = resolveExtensionPaths(t.Context(), []string{filepath.Join(pluginsDir, "Missing*")})
+	assert.Error(t, err)
+}
+
+func TestRunForExtensions(t *testing.T) {
+	paths := []string{"/a", "/b", "/c"}
+
+	results := runForExtensions(t.Context(), paths, 2, func(_ context.Context, extPath string) (string, error) {
+		if extPath == "/b" {
+			return "", fmt.Errorf("broken")
+		}
+
+		return extPath + ".zip", nil
+	})
+
+	require.Len(t, results, 3)
+	assert.Equal(t, "/a.zip", results[0].Output)
+	assert.Equal(t, "broken", results[1].Error)
+	assert.Equal(t, "/c.zip", results[2].Output)
+
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+
+	err := writeExtensionRunReport(io.Discard, results, reportFile)
+	assert.EqualError(t, err, "1 of 3 extensions failed")
+	assert.FileExists(t, reportFile)
+}
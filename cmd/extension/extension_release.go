@@ -0,0 +1,140 @@
+package extension
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/internal/changelog"
+	"github.com/shopware/shopware-cli/internal/git"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+// releaseChangelogTemplate is used when the extension has no changelog configured, as the default template requires a public VCS url.
+const releaseChangelogTemplate = "{{range .Commits}}- {{ .Message }}\n{{end}}"
+
+var extensionReleaseCmd = &cobra.Command{
+	Use:       "release [path] [patch|minor|major|auto]",
+	Short:     "Increase the version of the extension, generate the changelog and tag the release",
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: []string{changelog.BumpPatch, changelog.BumpMinor, changelog.BumpMajor, "auto"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		extPath, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("cannot find path: %w", err)
+		}
+
+		bump := "auto"
+		if len(args) == 2 {
+			bump = args[1]
+		}
+
+		noGit, _ := cmd.Flags().GetBool("no-git")
+		tagPrefix, _ := cmd.Flags().GetString("tag-prefix")
+
+		ext, err := extension.GetExtensionByFolder(extPath)
+		if err != nil {
+			return fmt.Errorf("detect extension type: %w", err)
+		}
+
+		currentVersion, err := ext.GetVersion()
+		if err != nil {
+			return fmt.Errorf("get version: %w", err)
+		}
+
+		if !noGit {
+			dirty, err := git.HasUncommittedChanges(cmd.Context(), extPath)
+			if err != nil {
+				return err
+			}
+
+			if dirty {
+				return fmt.Errorf("the repository has uncommitted changes, commit or stash them before releasing")
+			}
+		}
+
+		if bump == "auto" {
+			// Commits are collected from the tag before the next possible version
+			candidate, err := extension.BumpVersion(currentVersion, changelog.BumpPatch)
+			if err != nil {
+				return err
+			}
+
+			commits, err := git.GetCommits(cmd.Context(), candidate.String(), extPath)
+			if err != nil {
+				return fmt.Errorf("get commits: %w", err)
+			}
+
+			bump = changelog.DetectVersionBump(commits)
+
+			logging.FromContext(cmd.Context()).Infof("Detected %s release from %d commits", bump, len(commits))
+		}
+
+		newVersion, err := extension.BumpVersion(currentVersion, bump)
+		if err != nil {
+			return err
+		}
+
+		changelogCfg := ext.GetExtensionConfig().Changelog
+		if !changelogCfg.Enabled {
+			changelogCfg = changelog.Config{Template: releaseChangelogTemplate}
+		}
+
+		content, err := changelog.GenerateChangelog(cmd.Context(), newVersion.String(), extPath, changelogCfg)
+		if err != nil {
+			return fmt.Errorf("generate changelog: %w", err)
+		}
+
+		if err := extension.UpdateExtensionVersion(ext, newVersion); err != nil {
+			return fmt.Errorf("update version: %w", err)
+		}
+
+		changedFiles := []string{"composer.json"}
+		if ext.GetType() == extension.TypePlatformApp {
+			changedFiles = []string{"manifest.xml"}
+		}
+
+		changelogs, err := changelog.TranslateChangelogs(cmd.Context(), content, changelogCfg.Translation)
+		if err != nil {
+			return err
+		}
+
+		for _, language := range changelog.Languages {
+			changelogFile, err := extension.PrependChangelog(ext, language, newVersion.String(), changelogs[language])
+			if err != nil {
+				return err
+			}
+
+			changedFiles = append(changedFiles, filepath.Base(changelogFile))
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Updated version from %s to %s", currentVersion.String(), newVersion.String())
+
+		if noGit {
+			return nil
+		}
+
+		tag := tagPrefix + newVersion.String()
+		message := fmt.Sprintf("Release %s", newVersion.String())
+
+		if err := git.CommitFiles(cmd.Context(), extPath, message, changedFiles...); err != nil {
+			return fmt.Errorf("commit release: %w", err)
+		}
+
+		if err := git.CreateTag(cmd.Context(), extPath, tag, message); err != nil {
+			return fmt.Errorf("create tag: %w", err)
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Created commit and tag %s, push them with: git push --follow-tags", tag)
+
+		return nil
+	},
+}
+
+func init() {
+	extensionRootCmd.AddCommand(extensionReleaseCmd)
+	extensionReleaseCmd.Flags().Bool("no-git", false, "Only update the files, do not create a commit and tag")
+	extensionReleaseCmd.Flags().String("tag-prefix", "", "Prefix for the created tag, e.g. v")
+}
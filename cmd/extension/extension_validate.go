@@ -1,9 +1,12 @@
 package extension
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/sync/errgroup"
@@ -16,116 +19,170 @@ import (
 )
 
 var extensionValidateCmd = &cobra.Command{
-	Use:   "validate [path]",
+	Use:   "validate [path...]",
 	Short: "Validate a Extension",
+	Long:  "Validate one or more extensions. Paths can be extension folders, zip files, glob patterns like custom/plugins/* or a Shopware project, in which case all its extensions are validated.",
 	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		isFull, _ := cmd.Flags().GetBool("full")
-		storeCompliance, _ := cmd.Flags().GetBool("store-compliance")
 		reportingFormat, _ := cmd.Flags().GetString("reporter")
-		checkAgainst, _ := cmd.Flags().GetString("check-against")
-		tmpDir, err := os.MkdirTemp(os.TempDir(), "analyse-extension-*")
-		only, _ := cmd.Flags().GetString("only")
-		exclude, _ := cmd.Flags().GetString("exclude")
-
-		// If the user does not want to run full validation, only run shopware-cli
-		if !isFull {
-			only = "sw-cli"
-		}
 
 		if reportingFormat == "" {
 			reportingFormat = validation.DetectDefaultReporter()
 		}
 
+		paths, err := resolveExtensionPaths(cmd.Context(), args)
 		if err != nil {
-			return fmt.Errorf("cannot create temporary directory: %w", err)
+			return err
 		}
 
-		path, err := filepath.Abs(args[0])
-		if err != nil {
-			return fmt.Errorf("cannot find path: %w", err)
-		}
+		if len(paths) == 1 {
+			result, err := validateExtension(cmd.Context(), cmd, paths[0])
+			if err != nil {
+				return err
+			}
 
-		stat, err := os.Stat(path)
-		if err != nil {
-			return fmt.Errorf("cannot find path: %w", err)
+			return validation.DoCheckReport(result, reportingFormat)
 		}
-		var toolCfg *verifier.ToolConfig
 
-		if stat.IsDir() {
-			if isFull {
-				if err := system.CopyFiles(args[0], tmpDir); err != nil {
-					return err
-				}
+		useSharedPackageCaches()
 
-				defer func() {
-					if err := os.RemoveAll(tmpDir); err != nil {
-						logging.FromContext(cmd.Context()).Error("Failed to remove temporary directory:", err)
-					}
-				}()
-			} else {
-				tmpDir = args[0]
-			}
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		results := make([]validation.Check, len(paths))
 
-			ext, err := extension.GetExtensionByFolder(tmpDir)
-			if err != nil {
-				return err
-			}
+		runForExtensions(cmd.Context(), paths, parallel, func(ctx context.Context, extPath string) (string, error) {
+			index := slices.Index(paths, extPath)
 
-			toolCfg, err = verifier.ConvertExtensionToToolConfig(ext)
+			result, err := validateExtension(ctx, cmd, extPath)
 			if err != nil {
-				return err
+				check := verifier.NewCheck()
+				check.AddResult(validation.CheckResult{
+					Path:       ".",
+					Identifier: "extension.validate",
+					Message:    err.Error(),
+					Severity:   validation.SeverityError,
+				})
+				result = check
 			}
 
-			toolCfg.InputWasDirectory = true
-		} else {
-			ext, err := extension.GetExtensionByZip(args[0])
-			if err != nil {
-				return err
-			}
+			results[index] = result
 
-			toolCfg, err = verifier.ConvertExtensionToToolConfig(ext)
-			if err != nil {
-				return err
+			return "", err
+		})
+
+		// Prefix all results with the extension folder to get one consolidated report
+		merged := verifier.NewCheck()
+
+		for i, result := range results {
+			for _, r := range result.GetResults() {
+				r.Path = filepath.Join(filepath.Base(paths[i]), r.Path)
+				merged.AddResult(r)
 			}
 		}
 
-		if storeCompliance || os.Getenv("SHOPWARE_CLI_STORE_COMPLIANCE") == "1" {
-			toolCfg.Extension.GetExtensionConfig().Validation.StoreCompliance = true
-			// The user is not allowed to provide a custom ignore list when store compliance is enabled
-			toolCfg.Extension.GetExtensionConfig().Validation.Ignore = extension.ConfigValidationList{}
-		}
+		return validation.DoCheckReport(merged, reportingFormat)
+	},
+}
 
-		toolCfg.CheckAgainst = checkAgainst
-		result := verifier.NewCheck()
+func validateExtension(ctx context.Context, cmd *cobra.Command, path string) (validation.Check, error) {
+	isFull, _ := cmd.Flags().GetBool("full")
+	storeCompliance, _ := cmd.Flags().GetBool("store-compliance")
+	checkAgainst, _ := cmd.Flags().GetString("check-against")
+	only, _ := cmd.Flags().GetString("only")
+	exclude, _ := cmd.Flags().GetString("exclude")
 
-		var gr errgroup.Group
+	// If the user does not want to run full validation, only run shopware-cli
+	if !isFull {
+		only = "sw-cli"
+	}
 
-		tools := verifier.GetTools()
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find path: %w", err)
+	}
+	var toolCfg *verifier.ToolConfig
 
-		tools, err = tools.Only(only)
+	if stat.IsDir() {
+		extDir := path
+
+		if isFull {
+			tmpDir, err := os.MkdirTemp(os.TempDir(), "analyse-extension-*")
+			if err != nil {
+				return nil, fmt.Errorf("cannot create temporary directory: %w", err)
+			}
+
+			if err := system.CopyFiles(path, tmpDir); err != nil {
+				return nil, err
+			}
+
+			defer func() {
+				if err := os.RemoveAll(tmpDir); err != nil {
+					logging.FromContext(ctx).Error("Failed to remove temporary directory:", err)
+				}
+			}()
+
+			extDir = tmpDir
+		}
+
+		ext, err := extension.GetExtensionByFolder(extDir)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		tools, err = tools.Exclude(exclude)
+		toolCfg, err = verifier.ConvertExtensionToToolConfig(ext)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		for _, tool := range tools {
-			tool := tool
-			gr.Go(func() error {
-				return tool.Check(cmd.Context(), result, *toolCfg)
-			})
+		toolCfg.InputWasDirectory = true
+	} else {
+		ext, err := extension.GetExtensionByZip(path)
+		if err != nil {
+			return nil, err
 		}
 
-		if err := gr.Wait(); err != nil {
-			return err
+		toolCfg, err = verifier.ConvertExtensionToToolConfig(ext)
+		if err != nil {
+			return nil, err
 		}
 
-		return validation.DoCheckReport(result.RemoveByIdentifier(toolCfg.ValidationIgnores), reportingFormat)
-	},
+		toolCfg.ZipFile = path
+	}
+
+	if storeCompliance || os.Getenv("SHOPWARE_CLI_STORE_COMPLIANCE") == "1" {
+		toolCfg.Extension.GetExtensionConfig().Validation.StoreCompliance = true
+		// The user is not allowed to provide a custom ignore list when store compliance is enabled
+		toolCfg.Extension.GetExtensionConfig().Validation.Ignore = extension.ConfigValidationList{}
+	}
+
+	toolCfg.CheckAgainst = checkAgainst
+	result := verifier.NewCheck()
+
+	var gr errgroup.Group
+
+	tools := verifier.GetTools()
+
+	tools, err = tools.Only(only)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, err = tools.Exclude(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tool := range tools {
+		tool := tool
+		gr.Go(func() error {
+			return tool.Check(ctx, result, *toolCfg)
+		})
+	}
+
+	if err := gr.Wait(); err != nil {
+		return nil, err
+	}
+
+	return result.RemoveByIdentifier(toolCfg.ValidationIgnores), nil
 }
 
 func init() {
@@ -136,6 +193,7 @@ func init() {
 	extensionValidateCmd.PersistentFlags().String("check-against", "highest", "Check against Shopware Version (highest, lowest)")
 	extensionValidateCmd.PersistentFlags().String("only", "", "Run only specific tools by name (comma-separated, e.g. phpstan,eslint)")
 	extensionValidateCmd.PersistentFlags().String("exclude", "", "Exclude specific tools by name (comma-separated, e.g. phpstan,eslint)")
+	extensionValidateCmd.PersistentFlags().Int("parallel", runtime.NumCPU(), "Amount of extensions validated in parallel")
 	extensionValidateCmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		reporter, _ := cmd.Flags().GetString("reporter")
 		if reporter != "summary" && reporter != "json" && reporter != "github" && reporter != "junit" && reporter != "markdown" && reporter != "" {
@@ -0,0 +1,80 @@
+package extension
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var extensionVerifySignatureCmd = &cobra.Command{
+	Use:   "verify-signature [zip]",
+	Short: "Verify the detached signature of a extension zip",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		zipFile, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("cannot find path: %w", err)
+		}
+
+		keyFile, _ := cmd.Flags().GetString("key")
+		signatureFile, _ := cmd.Flags().GetString("signature")
+
+		if signatureFile == "" {
+			signatureFile = extension.SignatureFileName(zipFile)
+		}
+
+		key, err := extension.LoadVerifyKey(keyFile)
+		if err != nil {
+			return fmt.Errorf("load public key: %w", err)
+		}
+
+		signature, err := extension.ReadSignature(signatureFile)
+		if err != nil {
+			return err
+		}
+
+		if err := extension.VerifyZipSignature(zipFile, signature, key); err != nil {
+			return err
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Signature of %s is valid (key %s)", args[0], signature.KeyID)
+
+		return nil
+	},
+}
+
+var extensionGenerateSigningKeyCmd = &cobra.Command{
+	Use:   "generate-signing-key [name]",
+	Short: "Generate a ed25519 key pair for signing extension zips",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		privateKey, publicKey, err := extension.GenerateSigningKey()
+		if err != nil {
+			return fmt.Errorf("generate key: %w", err)
+		}
+
+		if err := os.WriteFile(args[0]+".key", privateKey, 0o600); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(args[0]+".pub", publicKey, 0o644); err != nil {
+			return err
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Created %s.key and %s.pub, keep the private key secret", args[0], args[0])
+
+		return nil
+	},
+}
+
+func init() {
+	extensionRootCmd.AddCommand(extensionVerifySignatureCmd)
+	extensionRootCmd.AddCommand(extensionGenerateSigningKeyCmd)
+	extensionVerifySignatureCmd.Flags().String("key", "", "Path to the public key, defaults to the SHOPWARE_CLI_SIGNING_PUBLIC_KEY environment variable")
+	extensionVerifySignatureCmd.Flags().String("signature", "", "Path to the signature file, defaults to the zip path with .sig suffix")
+}
@@ -7,12 +7,15 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"regexp"
+	"runtime"
 
 	cp "github.com/otiai10/copy"
 	"github.com/spf13/cobra"
 
 	"github.com/shopware/shopware-cli/extension"
 	"github.com/shopware/shopware-cli/internal/validation"
+	"github.com/shopware/shopware-cli/internal/verifier"
 	"github.com/shopware/shopware-cli/logging"
 )
 
@@ -24,202 +27,275 @@ var (
 var extensionZipCmd = &cobra.Command{
 	Use:   "zip [path] [branch]",
 	Short: "Zip a Extension",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Zip one or more extensions. The path can be an extension folder, a glob pattern like custom/plugins/* or a Shopware project, in which case all its extensions are zipped.
+Further extensions can be added with --path. The optional branch is used as the version in the zip file name.`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		extPath, err := filepath.Abs(args[0])
-		if err != nil {
-			return err
-		}
-
 		var branch string
 		if len(args) == 2 {
 			branch = args[1]
 		}
 
-		ext, err := extension.GetExtensionByFolder(extPath)
-		if err != nil {
-			return fmt.Errorf("detect extension type: %w", err)
-		}
-
-		extCfg := ext.GetExtensionConfig()
+		additionalPaths, _ := cmd.Flags().GetStringSlice("path")
 
-		name, err := ext.GetName()
+		extPaths, err := resolveExtensionPaths(cmd.Context(), append([]string{args[0]}, additionalPaths...))
 		if err != nil {
-			return fmt.Errorf("get name: %w", err)
+			return err
 		}
 
-		// Clear previous zips
-		existingFiles, err := filepath.Glob(fmt.Sprintf("%s-*.zip", name))
-		if err != nil {
+		if len(extPaths) == 1 {
+			_, err := zipExtension(cmd.Context(), cmd, extPaths[0], branch)
 			return err
 		}
 
-		for _, file := range existingFiles {
-			err = os.Remove(file)
-			if err != nil {
-				return fmt.Errorf("remove existing file: %w", err)
-			}
+		if fileName, _ := cmd.Flags().GetString("filename"); fileName != "" {
+			return fmt.Errorf("--filename cannot be used when zipping multiple extensions")
 		}
 
-		// Create temp dir
-		tempDir, err := os.MkdirTemp("", "extension")
-		if err != nil {
-			return fmt.Errorf("create temp directory: %w", err)
-		}
+		useSharedPackageCaches()
 
-		extName, err := ext.GetName()
-		if err != nil {
-			return fmt.Errorf("get extension name: %w", err)
-		}
+		parallel, _ := cmd.Flags().GetInt("parallel")
+		reportFile, _ := cmd.Flags().GetString("report-file")
+
+		results := runForExtensions(cmd.Context(), extPaths, parallel, func(ctx context.Context, extPath string) (string, error) {
+			return zipExtension(ctx, cmd, extPath, branch)
+		})
+
+		return writeExtensionRunReport(os.Stdout, results, reportFile)
+	},
+}
+
+func zipExtension(ctx context.Context, cmd *cobra.Command, extPath, branch string) (string, error) {
+	ext, err := extension.GetExtensionByFolder(extPath)
+	if err != nil {
+		return "", fmt.Errorf("detect extension type: %w", err)
+	}
+
+	extCfg := ext.GetExtensionConfig()
+
+	name, err := ext.GetName()
+	if err != nil {
+		return "", fmt.Errorf("get name: %w", err)
+	}
 
-		extDir := fmt.Sprintf("%s/%s/", tempDir, extName)
+	// Clear previous zips and their signatures
+	existingFiles, err := previousZipFiles(".", name)
+	if err != nil {
+		return "", err
+	}
 
-		err = os.Mkdir(extDir, os.ModePerm)
+	for _, file := range existingFiles {
+		err = os.Remove(file)
 		if err != nil {
-			return fmt.Errorf("create temp directory: %w", err)
+			return "", fmt.Errorf("remove existing file: %w", err)
 		}
+	}
 
-		tempDir += "/"
+	// Create temp dir
+	tempDir, err := os.MkdirTemp("", "extension")
+	if err != nil {
+		return "", fmt.Errorf("create temp directory: %w", err)
+	}
 
-		defer func(path string) {
-			_ = os.RemoveAll(path)
-		}(tempDir)
+	extName, err := ext.GetName()
+	if err != nil {
+		return "", fmt.Errorf("get extension name: %w", err)
+	}
 
-		var tag string
+	extDir := fmt.Sprintf("%s/%s/", tempDir, extName)
 
-		// Extract files using strategy
-		if disableGit {
-			err = cp.Copy(extPath, extDir, copyOptions())
-			if err != nil {
-				return fmt.Errorf("copy files: %w", err)
-			}
-		} else {
-			gitCommit, _ := cmd.Flags().GetString("git-commit")
+	err = os.Mkdir(extDir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("create temp directory: %w", err)
+	}
 
-			tag, err = extension.GitCopyFolder(cmd.Context(), extPath, extDir, gitCommit)
-			if err != nil {
-				return fmt.Errorf("copy via git: %w", err)
-			}
+	tempDir += "/"
+
+	defer func(path string) {
+		_ = os.RemoveAll(path)
+	}(tempDir)
 
-			logging.FromContext(cmd.Context()).Infof("Checking out %s using Git", tag)
+	var tag string
+
+	// Extract files using strategy
+	if disableGit {
+		err = cp.Copy(extPath, extDir, copyOptions())
+		if err != nil {
+			return "", fmt.Errorf("copy files: %w", err)
 		}
+	} else {
+		gitCommit, _ := cmd.Flags().GetString("git-commit")
 
-		// User input wins
-		if len(branch) > 0 {
-			tag = branch
+		tag, err = extension.GitCopyFolder(ctx, extPath, extDir, gitCommit)
+		if err != nil {
+			return "", fmt.Errorf("copy via git: %w", err)
 		}
 
-		if extCfg.Build.Zip.Composer.Enabled {
-			if err := executeHooks(cmd.Context(), ext, extCfg.Build.Zip.Composer.BeforeHooks, extDir); err != nil {
-				return fmt.Errorf("before hooks composer: %w", err)
-			}
+		logging.FromContext(ctx).Infof("Checking out %s using Git", tag)
+	}
 
-			if err := extension.PrepareFolderForZipping(cmd.Context(), extDir, ext, extCfg); err != nil {
-				return fmt.Errorf("prepare package: %w", err)
-			}
+	// User input wins
+	if len(branch) > 0 {
+		tag = branch
+	}
 
-			if err := executeHooks(cmd.Context(), ext, extCfg.Build.Zip.Composer.AfterHooks, extDir); err != nil {
-				return fmt.Errorf("after hooks composer: %w", err)
-			}
-		}
-		var tempExt extension.Extension
-		if tempExt, err = extension.GetExtensionByFolder(extDir); err != nil {
-			return err
+	if extCfg.Build.Zip.Composer.Enabled {
+		if err := executeHooks(ctx, ext, extCfg.Build.Zip.Composer.BeforeHooks, extDir); err != nil {
+			return "", fmt.Errorf("before hooks composer: %w", err)
 		}
 
-		if extCfg.Build.Zip.Assets.Enabled {
-			if err := executeHooks(cmd.Context(), ext, extCfg.Build.Zip.Assets.BeforeHooks, extDir); err != nil {
-				return fmt.Errorf("before hooks assets: %w", err)
-			}
+		if err := extension.PrepareFolderForZipping(ctx, extDir, ext, extCfg); err != nil {
+			return "", fmt.Errorf("prepare package: %w", err)
+		}
 
-			shopwareConstraint, err := tempExt.GetShopwareVersionConstraint()
-			if err != nil {
-				return fmt.Errorf("get shopware version constraint: %w", err)
-			}
+		if err := executeHooks(ctx, ext, extCfg.Build.Zip.Composer.AfterHooks, extDir); err != nil {
+			return "", fmt.Errorf("after hooks composer: %w", err)
+		}
+	}
+	var tempExt extension.Extension
+	if tempExt, err = extension.GetExtensionByFolder(extDir); err != nil {
+		return "", err
+	}
 
-			assetBuildConfig := extension.AssetBuildConfig{
-				CleanupNodeModules: true,
-				ShopwareRoot:       os.Getenv("SHOPWARE_PROJECT_ROOT"),
-				ShopwareVersion:    shopwareConstraint,
-			}
+	if extCfg.Build.Zip.Assets.Enabled {
+		if err := executeHooks(ctx, ext, extCfg.Build.Zip.Assets.BeforeHooks, extDir); err != nil {
+			return "", fmt.Errorf("before hooks assets: %w", err)
+		}
 
-			if err := extension.BuildAssetsForExtensions(cmd.Context(), extension.ConvertExtensionsToSources(cmd.Context(), []extension.Extension{tempExt}), assetBuildConfig); err != nil {
-				return fmt.Errorf("building assets: %w", err)
-			}
+		shopwareConstraint, err := tempExt.GetShopwareVersionConstraint()
+		if err != nil {
+			return "", fmt.Errorf("get shopware version constraint: %w", err)
+		}
 
-			if err := executeHooks(cmd.Context(), ext, extCfg.Build.Zip.Assets.AfterHooks, extDir); err != nil {
-				return fmt.Errorf("after hooks assets: %w", err)
-			}
+		assetBuildConfig := extension.AssetBuildConfig{
+			CleanupNodeModules: true,
+			ShopwareRoot:       os.Getenv("SHOPWARE_PROJECT_ROOT"),
+			ShopwareVersion:    shopwareConstraint,
 		}
 
-		if cmd.Flags().Changed("overwrite-app-backend-secret") {
-			extCfg.Validation.Ignore = append(extCfg.Validation.Ignore, validation.ToolConfigIgnore{Identifier: "metadata.setup"})
-			if err := extCfg.Dump(extDir); err != nil {
-				return fmt.Errorf("dump extension config: %w", err)
-			}
+		if err := extension.BuildAssetsForExtensions(ctx, extension.ConvertExtensionsToSources(ctx, []extension.Extension{tempExt}), assetBuildConfig); err != nil {
+			return "", fmt.Errorf("building assets: %w", err)
 		}
 
-		// Cleanup not wanted files
-		if err := extension.CleanupExtensionFolder(extDir, extCfg.Build.Zip.Pack.Excludes.Paths); err != nil {
-			return fmt.Errorf("cleanup package: %w", err)
+		if err := executeHooks(ctx, ext, extCfg.Build.Zip.Assets.AfterHooks, extDir); err != nil {
+			return "", fmt.Errorf("after hooks assets: %w", err)
 		}
+	}
 
-		if extensionReleaseMode {
-			if err := extension.PrepareExtensionForRelease(cmd.Context(), extPath, extDir, ext); err != nil {
-				return fmt.Errorf("prepare for release: %w", err)
-			}
+	if cmd.Flags().Changed("overwrite-app-backend-secret") {
+		extCfg.Validation.Ignore = append(extCfg.Validation.Ignore, validation.ToolConfigIgnore{Identifier: "metadata.setup"})
+		if err := extCfg.Dump(extDir); err != nil {
+			return "", fmt.Errorf("dump extension config: %w", err)
 		}
+	}
+
+	// Cleanup not wanted files
+	if err := extension.CleanupExtensionFolder(extDir, extCfg.Build.Zip.Pack.Excludes.Paths); err != nil {
+		return "", fmt.Errorf("cleanup package: %w", err)
+	}
 
-		if err := extension.ResizeExtensionIcon(cmd.Context(), tempExt); err != nil {
-			return fmt.Errorf("resize extension icon: %w", err)
+	if extensionReleaseMode {
+		if err := extension.PrepareExtensionForRelease(ctx, extPath, extDir, ext); err != nil {
+			return "", fmt.Errorf("prepare for release: %w", err)
 		}
+	}
+
+	if err := extension.ResizeExtensionIcon(ctx, tempExt); err != nil {
+		return "", fmt.Errorf("resize extension icon: %w", err)
+	}
+
+	if err := extension.BuildModifier(ext, extDir, extension.BuildModifierConfig{
+		AppBackendUrl:    getStringOnStringError(cmd.Flags().GetString("overwrite-app-backend-url")),
+		AppBackendSecret: getStringOnStringError(cmd.Flags().GetString("overwrite-app-backend-secret")),
+		Version:          getStringOnStringError(cmd.Flags().GetString("overwrite-version")),
+	}); err != nil {
+		return "", fmt.Errorf("build modifier: %w", err)
+	}
 
-		if err := extension.BuildModifier(ext, extDir, extension.BuildModifierConfig{
-			AppBackendUrl:    getStringOnStringError(cmd.Flags().GetString("overwrite-app-backend-url")),
-			AppBackendSecret: getStringOnStringError(cmd.Flags().GetString("overwrite-app-backend-secret")),
-			Version:          getStringOnStringError(cmd.Flags().GetString("overwrite-version")),
-		}); err != nil {
-			return fmt.Errorf("build modifier: %w", err)
+	fileName, _ := cmd.Flags().GetString("filename")
+
+	if len(fileName) == 0 {
+		fileName = fmt.Sprintf("%s-%s.zip", name, tag)
+		if len(tag) == 0 {
+			fileName = fmt.Sprintf("%s.zip", name)
 		}
+	}
 
-		fileName, _ := cmd.Flags().GetString("filename")
+	outputDir, _ := cmd.Flags().GetString("output-directory")
 
-		if len(fileName) == 0 {
-			fileName = fmt.Sprintf("%s-%s.zip", name, tag)
-			if len(tag) == 0 {
-				fileName = fmt.Sprintf("%s.zip", name)
+	if len(outputDir) > 0 {
+		if _, err := os.Stat(outputDir); os.IsNotExist(err) {
+			if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+				return "", fmt.Errorf("create output directory: %w", err)
 			}
 		}
 
-		outputDir, _ := cmd.Flags().GetString("output-directory")
+		fileName = path.Join(outputDir, fileName)
+	}
+
+	if err := executeHooks(ctx, ext, extCfg.Build.Zip.Pack.BeforeHooks, extDir); err != nil {
+		return "", fmt.Errorf("before hooks pack: %w", err)
+	}
 
-		if len(outputDir) > 0 {
-			if _, err := os.Stat(outputDir); os.IsNotExist(err) {
-				if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
-					return fmt.Errorf("create output directory: %w", err)
-				}
-			}
+	// Generate checksums.json file before creating the zip
+	if err := extension.GenerateChecksumJSON(ctx, extDir, ext); err != nil {
+		return "", fmt.Errorf("generate checksum.json: %w", err)
+	}
+
+	if err := extension.CreateZip(tempDir, fileName); err != nil {
+		return "", fmt.Errorf("create zip file: %w", err)
+	}
 
-			fileName = path.Join(outputDir, fileName)
+	logging.FromContext(ctx).Infof("Created file %s", fileName)
+
+	if sign, _ := cmd.Flags().GetBool("sign"); sign {
+		signingKeyFile, _ := cmd.Flags().GetString("signing-key")
+
+		signingKey, err := extension.LoadSigningKey(signingKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("load signing key: %w", err)
 		}
 
-		if err := executeHooks(cmd.Context(), ext, extCfg.Build.Zip.Pack.BeforeHooks, extDir); err != nil {
-			return fmt.Errorf("before hooks pack: %w", err)
+		signature, err := extension.SignZip(fileName, signingKey)
+		if err != nil {
+			return "", fmt.Errorf("sign zip file: %w", err)
 		}
 
-		// Generate checksums.json file before creating the zip
-		if err := extension.GenerateChecksumJSON(cmd.Context(), extDir, ext); err != nil {
-			return fmt.Errorf("generate checksum.json: %w", err)
+		if err := extension.WriteSignature(extension.SignatureFileName(fileName), signature); err != nil {
+			return "", fmt.Errorf("write signature: %w", err)
 		}
 
-		if err := extension.CreateZip(tempDir, fileName); err != nil {
-			return fmt.Errorf("create zip file: %w", err)
+		logging.FromContext(ctx).Infof("Created signature %s with key %s", extension.SignatureFileName(fileName), signature.KeyID)
+	}
+
+	showReport, _ := cmd.Flags().GetBool("report")
+	sizeBudget := extCfg.Build.Zip.SizeBudget
+
+	if !showReport && sizeBudget.Max == "" && len(sizeBudget.Paths) == 0 {
+		return fileName, nil
+	}
+
+	report, err := extension.AnalyzeZip(fileName)
+	if err != nil {
+		return "", fmt.Errorf("analyze zip file: %w", err)
+	}
+
+	if showReport {
+		if err := report.Write(os.Stdout); err != nil {
+			return "", fmt.Errorf("write zip report: %w", err)
 		}
+	}
 
-		logging.FromContext(cmd.Context()).Infof("Created file %s", fileName)
+	check := verifier.NewCheck()
+	report.ValidateSizeBudget(sizeBudget, check)
 
-		return nil
-	},
+	if check.HasErrors() {
+		if err := validation.DoCheckReport(check, validation.DetectDefaultReporter()); err != nil {
+			return "", fmt.Errorf("zip size budget exceeded: %w", err)
+		}
+	}
+
+	return fileName, nil
 }
 
 func init() {
@@ -232,6 +308,32 @@ func init() {
 	extensionZipCmd.Flags().String("output-directory", "", "Output directory for the zip file")
 	extensionZipCmd.Flags().String("git-commit", "", "Commit Hash / Tag to use")
 	extensionZipCmd.Flags().String("filename", "", "Name of the zip file, if not set it will be generated from the extension name and tag")
+	extensionZipCmd.Flags().Bool("report", false, "Print a size breakdown of the created zip file")
+	extensionZipCmd.Flags().StringSlice("path", nil, "Additional extension folders, glob patterns or Shopware projects to zip")
+	extensionZipCmd.Flags().Int("parallel", runtime.NumCPU(), "Amount of extensions zipped in parallel")
+	extensionZipCmd.Flags().String("report-file", "", "Write a JSON report of all zipped extensions to this file")
+	extensionZipCmd.Flags().Bool("sign", false, "Create a detached ed25519 signature next to the zip file")
+	extensionZipCmd.Flags().String("signing-key", "", "Path to the private key used for signing, defaults to the SHOPWARE_CLI_SIGNING_KEY environment variable")
+}
+
+// previousZipFiles returns the zips <name>-<version>.zip of the extension in the directory and their signatures.
+// Zips of other extensions with the same prefix like <name>-Addon-1.0.0.zip are kept, the version has to follow the name directly.
+func previousZipFiles(dir, name string) ([]string, error) {
+	candidates, err := filepath.Glob(filepath.Join(dir, name+"-*.zip*"))
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `-v?\d[0-9A-Za-z.+-]*\.zip(\.sig)?$`)
+	files := make([]string, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		if pattern.MatchString(filepath.Base(candidate)) {
+			files = append(files, candidate)
+		}
+	}
+
+	return files, nil
 }
 
 func getStringOnStringError(val string, _ error) string {
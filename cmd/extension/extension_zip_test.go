@@ -0,0 +1,26 @@
+package extension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousZipFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"Foo-1.0.0.zip", "Foo-1.0.0.zip.sig", "Foo-v2.0.0-beta.1.zip", "Foo-Bar-1.0.0.zip", "Foo-Bar-1.0.0.zip.sig", "Foo-1.0.0.zip.bak", "FooBar-1.0.0.zip"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte{}, os.ModePerm))
+	}
+
+	files, err := previousZipFiles(dir, "Foo")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "Foo-1.0.0.zip"),
+		filepath.Join(dir, "Foo-1.0.0.zip.sig"),
+		filepath.Join(dir, "Foo-v2.0.0-beta.1.zip"),
+	}, files)
+}
@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"dario.cat/mergo"
@@ -39,195 +40,206 @@ var projectCI = &cobra.Command{
 	Short: "Build Shopware in the CI",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var err error
-		args[0], err = filepath.Abs(args[0])
+		root, err := filepath.Abs(args[0])
 		if err != nil {
 			return err
 		}
 
-		if os.Getenv("APP_ENV") == "" {
-			if err := os.Setenv("APP_ENV", "prod"); err != nil {
-				return err
-			}
-		}
+		withDev, _ := cmd.Flags().GetBool("with-dev-dependencies")
 
-		// speed up composer install, when no version is set
-		if os.Getenv("COMPOSER_ROOT_VERSION") == "" {
-			if err := os.Setenv("COMPOSER_ROOT_VERSION", "1.0.0"); err != nil {
-				return err
-			}
-		}
+		return runProjectCI(cmd.Context(), root, ciOptions{WithDevDependencies: withDev})
+	},
+}
 
-		// Remove annoying cache invalidation errors while asset install
-		_ = os.Setenv("SHOPWARE_SKIP_ASSET_INSTALL_CACHE_INVALIDATION", "1")
+// ciOptions configures the build of runProjectCI.
+type ciOptions struct {
+	WithDevDependencies bool
+}
 
-		shopCfg, err := shop.ReadConfig(projectConfigPath, true)
-		if err != nil {
+// runProjectCI builds the Shopware project in root for production, it is used by project ci and project deploy --build.
+func runProjectCI(ctx context.Context, root string, opts ciOptions) error {
+	if os.Getenv("APP_ENV") == "" {
+		if err := os.Setenv("APP_ENV", "prod"); err != nil {
 			return err
 		}
+	}
 
-		cleanupPaths = append(cleanupPaths, shopCfg.Build.CleanupPaths...)
+	// speed up composer install, when no version is set
+	if os.Getenv("COMPOSER_ROOT_VERSION") == "" {
+		if err := os.Setenv("COMPOSER_ROOT_VERSION", "1.0.0"); err != nil {
+			return err
+		}
+	}
 
-		composerFlags := []string{"install", "--no-interaction", "--no-progress", "--optimize-autoloader", "--classmap-authoritative"}
+	// Remove annoying cache invalidation errors while asset install
+	_ = os.Setenv("SHOPWARE_SKIP_ASSET_INSTALL_CACHE_INVALIDATION", "1")
 
-		if withDev, _ := cmd.Flags().GetBool("with-dev-dependencies"); !withDev {
-			composerFlags = append(composerFlags, "--no-dev")
-		}
+	shopCfg, err := shop.ReadConfig(projectConfigPath, true)
+	if err != nil {
+		return err
+	}
 
-		token, err := prepareComposerAuth(cmd.Context(), args[0])
-		if err != nil {
-			return err
-		}
+	removePaths := append(slices.Clone(cleanupPaths), shopCfg.Build.CleanupPaths...)
 
-		composerInstallSection := ci.Default.Section(cmd.Context(), "Composer Installation")
+	composerFlags := []string{"install", "--no-interaction", "--no-progress", "--optimize-autoloader", "--classmap-authoritative"}
 
-		composer := phpexec.ComposerCommand(cmd.Context(), composerFlags...)
-		composer.Dir = args[0]
-		composer.Stdin = os.Stdin
-		composer.Stdout = os.Stdout
-		composer.Stderr = os.Stderr
-		composer.Env = append(os.Environ(),
-			"COMPOSER_AUTH="+token,
-		)
+	if !opts.WithDevDependencies {
+		composerFlags = append(composerFlags, "--no-dev")
+	}
 
-		if err := composer.Run(); err != nil {
-			return err
-		}
+	token, err := prepareComposerAuth(ctx, root)
+	if err != nil {
+		return err
+	}
 
-		composerInstallSection.End(cmd.Context())
+	composerInstallSection := ci.Default.Section(ctx, "Composer Installation")
 
-		lookingForExtensionsSection := ci.Default.Section(cmd.Context(), "Looking for extensions")
+	composer := phpexec.ComposerCommand(ctx, composerFlags...)
+	composer.Dir = root
+	composer.Stdin = os.Stdin
+	composer.Stdout = os.Stdout
+	composer.Stderr = os.Stderr
+	composer.Env = append(os.Environ(),
+		"COMPOSER_AUTH="+token,
+	)
 
-		sources := extension.FindAssetSourcesOfProject(cmd.Context(), args[0], shopCfg)
+	if err := composer.Run(); err != nil {
+		return err
+	}
 
-		shopwareConstraint, err := extension.GetShopwareProjectConstraint(args[0])
-		if err != nil {
-			return err
-		}
+	composerInstallSection.End(ctx)
 
-		lookingForExtensionsSection.End(cmd.Context())
-
-		assetCfg := extension.AssetBuildConfig{
-			CleanupNodeModules:           true,
-			ShopwareRoot:                 args[0],
-			ShopwareVersion:              shopwareConstraint,
-			Browserslist:                 shopCfg.Build.Browserslist,
-			SkipExtensionsWithBuildFiles: true,
-			DisableStorefrontBuild:       shopCfg.Build.DisableStorefrontBuild,
-			ForceExtensionBuild:          convertForceExtensionBuild(shopCfg.Build.ForceExtensionBuild),
-			ForceAdminBuild:              shopCfg.Build.ForceAdminBuild,
-			KeepNodeModules:              shopCfg.Build.KeepNodeModules,
-		}
+	lookingForExtensionsSection := ci.Default.Section(ctx, "Looking for extensions")
 
-		if err := extension.BuildAssetsForExtensions(cmd.Context(), sources, assetCfg); err != nil {
-			return err
-		}
+	sources := extension.FindAssetSourcesOfProject(ctx, root, shopCfg)
 
-		optimizeSection := ci.Default.Section(cmd.Context(), "Optimizing Administration Assets")
-		if err := cleanupAdministrationFiles(cmd.Context(), path.Join(args[0], "vendor", "shopware", "administration")); err != nil {
-			return err
-		}
+	shopwareConstraint, err := extension.GetShopwareProjectConstraint(root)
+	if err != nil {
+		return err
+	}
 
-		if err := createEmptySnippetFolder(path.Join(args[0], "vendor", "shopware", "administration")); err != nil {
-			return err
-		}
+	lookingForExtensionsSection.End(ctx)
+
+	assetCfg := extension.AssetBuildConfig{
+		CleanupNodeModules:           true,
+		ShopwareRoot:                 root,
+		ShopwareVersion:              shopwareConstraint,
+		Browserslist:                 shopCfg.Build.Browserslist,
+		SkipExtensionsWithBuildFiles: true,
+		DisableStorefrontBuild:       shopCfg.Build.DisableStorefrontBuild,
+		ForceExtensionBuild:          convertForceExtensionBuild(shopCfg.Build.ForceExtensionBuild),
+		ForceAdminBuild:              shopCfg.Build.ForceAdminBuild,
+		KeepNodeModules:              shopCfg.Build.KeepNodeModules,
+	}
 
-		if !shopCfg.Build.KeepExtensionSource {
-			for _, source := range sources {
-				if err := cleanupAdministrationFiles(cmd.Context(), source.Path); err != nil {
-					return err
-				}
-			}
-		}
+	if err := extension.BuildAssetsForExtensions(ctx, sources, assetCfg); err != nil {
+		return err
+	}
 
-		if !shopCfg.Build.KeepSourceMaps {
-			if err := cleanupJavaScriptSourceMaps(path.Join(args[0], "vendor", "shopware", "administration", "Resources", "public")); err != nil {
-				return err
-			}
+	optimizeSection := ci.Default.Section(ctx, "Optimizing Administration Assets")
+	if err := cleanupAdministrationFiles(ctx, path.Join(root, "vendor", "shopware", "administration")); err != nil {
+		return err
+	}
 
-			for _, source := range sources {
-				if err := cleanupJavaScriptSourceMaps(path.Join(source.Path, "Resources", "public")); err != nil {
-					return err
-				}
+	if err := createEmptySnippetFolder(path.Join(root, "vendor", "shopware", "administration")); err != nil {
+		return err
+	}
+
+	if !shopCfg.Build.KeepExtensionSource {
+		for _, source := range sources {
+			if err := cleanupAdministrationFiles(ctx, source.Path); err != nil {
+				return err
 			}
 		}
+	}
 
-		for _, removePath := range cleanupPaths {
-			logging.FromContext(cmd.Context()).Infof("Removing %s", removePath)
+	if !shopCfg.Build.KeepSourceMaps {
+		if err := cleanupJavaScriptSourceMaps(path.Join(root, "vendor", "shopware", "administration", "Resources", "public")); err != nil {
+			return err
+		}
 
-			if err := os.RemoveAll(path.Join(args[0], removePath)); err != nil {
+		for _, source := range sources {
+			if err := cleanupJavaScriptSourceMaps(path.Join(source.Path, "Resources", "public")); err != nil {
 				return err
 			}
 		}
+	}
+
+	for _, removePath := range removePaths {
+		logging.FromContext(ctx).Infof("Removing %s", removePath)
 
-		if err := cleanupTcpdf(args[0], cmd.Context()); err != nil {
+		if err := os.RemoveAll(path.Join(root, removePath)); err != nil {
 			return err
 		}
+	}
 
-		optimizeSection.End(cmd.Context())
+	if err := cleanupTcpdf(root, ctx); err != nil {
+		return err
+	}
 
-		warumupSection := ci.Default.Section(cmd.Context(), "Warming up container cache")
+	optimizeSection.End(ctx)
 
-		if err := runTransparentCommand(phpexec.PHPCommand(cmd.Context(), path.Join(args[0], "bin", "ci"), "--version")); err != nil { //nolint: gosec
-			return fmt.Errorf("failed to warmup container cache (php bin/ci --version): %w", err)
-		}
+	warumupSection := ci.Default.Section(ctx, "Warming up container cache")
 
-		if !shopCfg.Build.DisableAssetCopy {
-			logging.FromContext(cmd.Context()).Infof("Copying extension assets to final public/bundles folder")
+	if err := runTransparentCommand(phpexec.PHPCommand(ctx, path.Join(root, "bin", "ci"), "--version")); err != nil { //nolint: gosec
+		return fmt.Errorf("failed to warmup container cache (php bin/ci --version): %w", err)
+	}
 
-			// Delete asset manifest to force a new build
-			manifestPath := path.Join(args[0], "public", "asset-manifest.json")
-			if _, err := os.Stat(manifestPath); err == nil {
-				if err := os.Remove(manifestPath); err != nil {
-					return err
-				}
-			}
+	if !shopCfg.Build.DisableAssetCopy {
+		logging.FromContext(ctx).Infof("Copying extension assets to final public/bundles folder")
 
-			if err := runTransparentCommand(phpexec.PHPCommand(cmd.Context(), path.Join(args[0], "bin", "ci"), "asset:install")); err != nil { //nolint: gosec
-				return fmt.Errorf("failed to install assets (php bin/ci asset:install): %w", err)
+		// Delete asset manifest to force a new build
+		manifestPath := path.Join(root, "public", "asset-manifest.json")
+		if _, err := os.Stat(manifestPath); err == nil {
+			if err := os.Remove(manifestPath); err != nil {
+				return err
 			}
 		}
 
-		warumupSection.End(cmd.Context())
+		if err := runTransparentCommand(phpexec.PHPCommand(ctx, path.Join(root, "bin", "ci"), "asset:install")); err != nil { //nolint: gosec
+			return fmt.Errorf("failed to install assets (php bin/ci asset:install): %w", err)
+		}
+	}
 
-		if shopCfg.Build.RemoveExtensionAssets {
-			deleteAssetsSection := ci.Default.Section(cmd.Context(), "Deleting assets of extensions")
+	warumupSection.End(ctx)
 
-			for _, source := range sources {
-				if _, err := os.Stat(path.Join(source.Path, "Resources", "public", "administration", "css")); err == nil {
-					if err := os.WriteFile(path.Join(source.Path, "Resources", ".administration-css"), []byte{}, os.ModePerm); err != nil {
-						return err
-					}
-				}
+	if shopCfg.Build.RemoveExtensionAssets {
+		deleteAssetsSection := ci.Default.Section(ctx, "Deleting assets of extensions")
 
-				if _, err := os.Stat(path.Join(source.Path, "Resources", "public", "administration", "js")); err == nil {
-					if err := os.WriteFile(path.Join(source.Path, "Resources", ".administration-js"), []byte{}, os.ModePerm); err != nil {
-						return err
-					}
+		for _, source := range sources {
+			if _, err := os.Stat(path.Join(source.Path, "Resources", "public", "administration", "css")); err == nil {
+				if err := os.WriteFile(path.Join(source.Path, "Resources", ".administration-css"), []byte{}, os.ModePerm); err != nil {
+					return err
 				}
+			}
 
-				if err := os.RemoveAll(path.Join(source.Path, "Resources", "public")); err != nil {
+			if _, err := os.Stat(path.Join(source.Path, "Resources", "public", "administration", "js")); err == nil {
+				if err := os.WriteFile(path.Join(source.Path, "Resources", ".administration-js"), []byte{}, os.ModePerm); err != nil {
 					return err
 				}
 			}
 
-			if err := os.RemoveAll(path.Join(args[0], "vendor", "shopware", "administration", "Resources", "public")); err != nil {
+			if err := os.RemoveAll(path.Join(source.Path, "Resources", "public")); err != nil {
 				return err
 			}
+		}
 
-			if err := os.WriteFile(path.Join(args[0], "vendor", "shopware", "administration", "Resources", ".administration-js"), []byte{}, os.ModePerm); err != nil {
-				return err
-			}
+		if err := os.RemoveAll(path.Join(root, "vendor", "shopware", "administration", "Resources", "public")); err != nil {
+			return err
+		}
 
-			if err := os.WriteFile(path.Join(args[0], "vendor", "shopware", "administration", "Resources", ".administration-css"), []byte{}, os.ModePerm); err != nil {
-				return err
-			}
+		if err := os.WriteFile(path.Join(root, "vendor", "shopware", "administration", "Resources", ".administration-js"), []byte{}, os.ModePerm); err != nil {
+			return err
+		}
 
-			deleteAssetsSection.End(cmd.Context())
+		if err := os.WriteFile(path.Join(root, "vendor", "shopware", "administration", "Resources", ".administration-css"), []byte{}, os.ModePerm); err != nil {
+			return err
 		}
 
-		return nil
-	},
+		deleteAssetsSection.End(ctx)
+	}
+
+	return nil
 }
 
 func createEmptySnippetFolder(root string) error {
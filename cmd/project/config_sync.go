@@ -58,6 +58,12 @@ func NewSyncApplyers(cfg *shop.Config) []ConfigSyncApplyer {
 			syncApplyers = append(syncApplyers, &MailTemplateSync{})
 		case shop.SyncOptionEntity:
 			syncApplyers = append(syncApplyers, &EntitySync{})
+		case shop.SyncOptionCms:
+			syncApplyers = append(syncApplyers, &CmsSync{})
+		case shop.SyncOptionRule:
+			syncApplyers = append(syncApplyers, &RuleSync{})
+		case shop.SyncOptionFlow:
+			syncApplyers = append(syncApplyers, &FlowSync{})
 		}
 	}
 
@@ -68,6 +74,35 @@ type ConfigSyncOperation struct {
 	Operations     Operation
 	SystemSettings SystemConfig
 	ThemeSettings  ThemeSettings
+	// Changes are the field level differences between the config and the shop
+	Changes []ConfigSyncChange
+	// Managed are the entities of the entity sync, they are not pruned while they are part of the config
+	Managed []ConfigSyncManagedEntity
+	// Created are the entities inserted by the entity sync, only they are tracked in the state to prune them later
+	Created []ConfigSyncManagedEntity
+}
+
+const (
+	ConfigSyncActionAdded   = "added"
+	ConfigSyncActionChanged = "changed"
+	ConfigSyncActionRemoved = "removed"
+)
+
+type ConfigSyncChange struct {
+	Resource string      `json:"resource"`
+	Field    string      `json:"field,omitempty"`
+	Action   string      `json:"action"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+}
+
+type ConfigSyncManagedEntity struct {
+	Entity string `json:"entity"`
+	Id     string `json:"id"`
+}
+
+func (o *ConfigSyncOperation) addChange(resource, field, action string, oldValue, newValue interface{}) {
+	o.Changes = append(o.Changes, ConfigSyncChange{Resource: resource, Field: field, Action: action, Old: oldValue, New: newValue})
 }
 
 type ThemeSyncOperation struct {
@@ -0,0 +1,237 @@
+package project
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strconv"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var (
+	cmsPageFields    = []string{"cssClass", "config", "entity"}
+	cmsSectionFields = []string{"name", "sizingMode", "mobileBehavior", "backgroundColor", "backgroundMediaId", "backgroundMediaMode", "cssClass", "visibility"}
+	cmsBlockFields   = []string{"name", "sectionPosition", "marginTop", "marginBottom", "marginLeft", "marginRight", "backgroundColor", "backgroundMediaId", "backgroundMediaMode", "cssClass", "visibility"}
+)
+
+// CmsSync synchronizes the CMS layouts, the locked default layouts of Shopware are left out.
+type CmsSync struct{}
+
+func (CmsSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config, operation *ConfigSyncOperation) error {
+	if len(config.Sync.Cms) == 0 {
+		return nil
+	}
+
+	remote, err := searchSyncEntities(ctx, client, "cms_page", cmsPageCriteria())
+	if err != nil {
+		return err
+	}
+
+	refs := make([]syncEntityRef, 0, len(config.Sync.Cms))
+	for _, layout := range config.Sync.Cms {
+		refs = append(refs, syncEntityRef{Key: layout.Key, Name: layout.Name})
+	}
+
+	resolved := resolveSyncEntities(remote, "cms_page", refs)
+
+	for i, layout := range config.Sync.Cms {
+		id, existing := resolved[i].Id, resolved[i].Existing
+		resource := "cms_page " + layout.Key
+
+		if existing == nil {
+			operation.addChange(resource, "", ConfigSyncActionAdded, nil, layout.Name)
+		} else if !diffSyncConfig(operation, resource, layout, cmsLayoutFromEntity(existing, layout.Key)) {
+			continue
+		}
+
+		payload, deletions := cmsLayoutPayload(layout, id, existing)
+
+		operation.Operations[syncOperationCmsPrefix+layout.Key] = adminSdk.SyncOperation{
+			Action:  "upsert",
+			Entity:  "cms_page",
+			Payload: []map[string]interface{}{payload},
+		}
+
+		addSyncDeletions(operation, syncOperationDeletePrefix+"cms-"+layout.Key, deletions)
+	}
+
+	return nil
+}
+
+func (CmsSync) Pull(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config) error {
+	remote, err := searchSyncEntities(ctx, client, "cms_page", cmsPageCriteria())
+	if err != nil {
+		return err
+	}
+
+	keys := assignSyncKeys(remote)
+
+	config.Sync.Cms = make([]shop.CmsLayout, 0, len(remote))
+
+	for _, page := range remote {
+		config.Sync.Cms = append(config.Sync.Cms, cmsLayoutFromEntity(page, keys[syncString(page, "id")]))
+	}
+
+	slices.SortFunc(config.Sync.Cms, func(a, b shop.CmsLayout) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return nil
+}
+
+func cmsPageCriteria() map[string]interface{} {
+	return map[string]interface{}{
+		"filter": []map[string]interface{}{{"type": adminSdk.SearchFilterTypeEquals, "field": "locked", "value": false}},
+		"associations": map[string]interface{}{
+			"sections": map[string]interface{}{
+				"associations": map[string]interface{}{
+					"blocks": map[string]interface{}{
+						"associations": map[string]interface{}{"slots": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+	}
+}
+
+// cmsLayoutFromEntity converts the page of the API into the config, sections and blocks are ordered by their position.
+func cmsLayoutFromEntity(page map[string]interface{}, key string) shop.CmsLayout {
+	layout := shop.CmsLayout{
+		Key:    key,
+		Name:   syncString(page, "name"),
+		Type:   syncString(page, "type"),
+		Fields: pickSyncFields(page, cmsPageFields),
+	}
+
+	sections := syncList(page, "sections")
+	sortByPosition(sections)
+
+	for _, section := range sections {
+		cmsSection := shop.CmsSection{Type: syncString(section, "type"), Fields: pickSyncFields(section, cmsSectionFields)}
+
+		blocks := syncList(section, "blocks")
+		sortByPosition(blocks)
+
+		for _, block := range blocks {
+			cmsBlock := shop.CmsBlock{Type: syncString(block, "type"), Fields: pickSyncFields(block, cmsBlockFields)}
+
+			slots := syncList(block, "slots")
+			slices.SortFunc(slots, func(a, b map[string]interface{}) int {
+				return cmp.Compare(syncString(a, "slot"), syncString(b, "slot"))
+			})
+
+			for _, slot := range slots {
+				cmsBlock.Slots = append(cmsBlock.Slots, shop.CmsSlot{Slot: syncString(slot, "slot"), Type: syncString(slot, "type"), Config: syncObject(slot, "config")})
+			}
+
+			cmsSection.Blocks = append(cmsSection.Blocks, cmsBlock)
+		}
+
+		layout.Sections = append(layout.Sections, cmsSection)
+	}
+
+	return layout
+}
+
+// cmsLayoutPayload returns the upsert payload of the layout and the children to delete.
+// Children keep the id of the existing child at the same position, as slot ids are referenced by the slot overrides of categories and products.
+func cmsLayoutPayload(layout shop.CmsLayout, id string, existing map[string]interface{}) (map[string]interface{}, map[string][]string) {
+	deletions := map[string][]string{}
+
+	remoteSections := syncList(existing, "sections")
+	sortByPosition(remoteSections)
+
+	sections := make([]map[string]interface{}, 0, len(layout.Sections))
+
+	for i, section := range layout.Sections {
+		var remoteSection map[string]interface{}
+		if i < len(remoteSections) {
+			remoteSection = remoteSections[i]
+		}
+
+		sectionId := cmp.Or(syncString(remoteSection, "id"), syncEntityId(id, "section", strconv.Itoa(i)))
+
+		remoteBlocks := syncList(remoteSection, "blocks")
+		sortByPosition(remoteBlocks)
+
+		blocks := make([]map[string]interface{}, 0, len(section.Blocks))
+
+		for j, block := range section.Blocks {
+			var remoteBlock map[string]interface{}
+			if j < len(remoteBlocks) {
+				remoteBlock = remoteBlocks[j]
+			}
+
+			blockId := cmp.Or(syncString(remoteBlock, "id"), syncEntityId(sectionId, "block", strconv.Itoa(j)))
+
+			remoteSlots := map[string]string{}
+			for _, slot := range syncList(remoteBlock, "slots") {
+				remoteSlots[syncString(slot, "slot")] = syncString(slot, "id")
+			}
+
+			slots := make([]map[string]interface{}, 0, len(block.Slots))
+
+			for _, slot := range block.Slots {
+				slotPayload := map[string]interface{}{
+					"id":   cmp.Or(remoteSlots[slot.Slot], syncEntityId(blockId, "slot", slot.Slot)),
+					"slot": slot.Slot,
+					"type": slot.Type,
+				}
+
+				if slot.Config != nil {
+					slotPayload["config"] = slot.Config
+				}
+
+				slots = append(slots, slotPayload)
+				delete(remoteSlots, slot.Slot)
+			}
+
+			for _, slotId := range slices.Sorted(maps.Values(remoteSlots)) {
+				deletions["cms_slot"] = append(deletions["cms_slot"], slotId)
+			}
+
+			blocks = append(blocks, withSyncFields(block.Fields, map[string]interface{}{"id": blockId, "position": j, "type": block.Type, "slots": slots}))
+		}
+
+		for _, remoteBlock := range remoteBlocks[min(len(section.Blocks), len(remoteBlocks)):] {
+			deletions["cms_block"] = append(deletions["cms_block"], syncString(remoteBlock, "id"))
+		}
+
+		sections = append(sections, withSyncFields(section.Fields, map[string]interface{}{"id": sectionId, "position": i, "type": section.Type, "blocks": blocks}))
+	}
+
+	for _, remoteSection := range remoteSections[min(len(layout.Sections), len(remoteSections)):] {
+		deletions["cms_section"] = append(deletions["cms_section"], syncString(remoteSection, "id"))
+	}
+
+	payload := withSyncFields(layout.Fields, map[string]interface{}{"id": id, "name": layout.Name, "type": layout.Type, "sections": sections})
+
+	return payload, deletions
+}
+
+// withSyncFields merges the additional fields of the config into the payload, the fields managed by the sync take precedence.
+func withSyncFields(fields, payload map[string]interface{}) map[string]interface{} {
+	merged := maps.Clone(fields)
+	if merged == nil {
+		merged = map[string]interface{}{}
+	}
+
+	maps.Copy(merged, payload)
+
+	return merged
+}
+
+func addSyncDeletions(operation *ConfigSyncOperation, prefix string, deletions map[string][]string) {
+	for entity, ids := range deletions {
+		payload := make([]map[string]interface{}, 0, len(ids))
+
+		for _, id := range ids {
+			payload = append(payload, map[string]interface{}{"id": id})
+		}
+
+		operation.Operations[prefix+"-"+entity] = adminSdk.SyncOperation{Action: "delete", Entity: entity, Payload: payload}
+	}
+}
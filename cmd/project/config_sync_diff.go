@@ -0,0 +1,148 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/shopware/shopware-cli/internal/table"
+)
+
+const maxSyncValueLength = 60
+
+// normalizeSyncValue converts the value to its JSON representation, so values of the config and the API are comparable.
+func normalizeSyncValue(value interface{}) interface{} {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(content, &normalized); err != nil {
+		return value
+	}
+
+	return normalized
+}
+
+// containsSyncValue reports whether the remote value contains the local one, fields only known by the shop are ignored.
+func containsSyncValue(local, remote interface{}) bool {
+	switch l := local.(type) {
+	case map[string]interface{}:
+		r, ok := remote.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for key, value := range l {
+			remoteValue, ok := r[key]
+			if !ok || !containsSyncValue(value, remoteValue) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		r, ok := remote.([]interface{})
+		if !ok || len(r) != len(l) {
+			return false
+		}
+
+		for i := range l {
+			if !containsSyncValue(l[i], r[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return reflect.DeepEqual(local, remote)
+}
+
+// diffSyncEntity records the changed fields and returns the payload containing only them.
+func diffSyncEntity(operation *ConfigSyncOperation, resource string, payload, remote map[string]interface{}) map[string]interface{} {
+	delta := map[string]interface{}{}
+	local, _ := normalizeSyncValue(payload).(map[string]interface{})
+
+	for _, key := range slices.Sorted(maps.Keys(local)) {
+		if key == "id" {
+			continue
+		}
+
+		remoteValue, exists := remote[key]
+		if exists && containsSyncValue(local[key], remoteValue) {
+			continue
+		}
+
+		delta[key] = payload[key]
+		addSyncFieldChanges(operation, resource, key, local[key], remoteValue)
+	}
+
+	return delta
+}
+
+// addSyncFieldChanges descends into objects, so only the changed nested fields are reported.
+func addSyncFieldChanges(operation *ConfigSyncOperation, resource, field string, local, remote interface{}) {
+	if remote == nil {
+		operation.addChange(resource, field, ConfigSyncActionAdded, nil, local)
+
+		return
+	}
+
+	l, localIsObject := local.(map[string]interface{})
+	r, remoteIsObject := remote.(map[string]interface{})
+
+	if !localIsObject || !remoteIsObject {
+		operation.addChange(resource, field, ConfigSyncActionChanged, remote, local)
+
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		if remoteValue, ok := r[key]; !ok || !containsSyncValue(l[key], remoteValue) {
+			addSyncFieldChanges(operation, resource, field+"."+key, l[key], remoteValue)
+		}
+	}
+}
+
+func writeConfigSyncPlan(w io.Writer, changes []ConfigSyncChange) error {
+	t := table.NewWriter(w)
+	t.Header([]string{"Resource", "Field", "Action", "Old", "New"})
+
+	for _, change := range changes {
+		_ = t.Append([]string{change.Resource, change.Field, change.Action, formatSyncValue(change.Old), formatSyncValue(change.New)})
+	}
+
+	return t.Render()
+}
+
+func formatSyncValue(value interface{}) string {
+	var text string
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		text = v
+	default:
+		content, err := json.Marshal(v)
+		if err != nil {
+			text = fmt.Sprint(v)
+		} else {
+			text = string(content)
+		}
+	}
+
+	text = strings.ReplaceAll(text, "\n", `\n`)
+
+	if runes := []rune(text); len(runes) > maxSyncValueLength {
+		return string(runes[:maxSyncValueLength-3]) + "..."
+	}
+
+	return text
+}
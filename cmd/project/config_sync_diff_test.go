@@ -0,0 +1,101 @@
+package project
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSyncOperation() *ConfigSyncOperation {
+	return &ConfigSyncOperation{Operations: map[string]adminSdk.SyncOperation{}}
+}
+
+func TestDiffSyncEntity(t *testing.T) {
+	operation := newTestSyncOperation()
+
+	payload := map[string]interface{}{
+		"id":           "a",
+		"name":         "Shirt",
+		"stock":        10,
+		"active":       true,
+		"customFields": map[string]interface{}{"color": "red", "size": "L"},
+		"tags":         []interface{}{map[string]interface{}{"name": "summer"}},
+		"description":  "new",
+	}
+
+	remote := map[string]interface{}{
+		"id":           "a",
+		"name":         "Shirt",
+		"stock":        float64(10),
+		"active":       true,
+		"customFields": map[string]interface{}{"color": "blue", "size": "L", "fit": "slim"},
+		"tags":         []interface{}{map[string]interface{}{"id": "t", "name": "summer"}},
+		"description":  nil,
+		"updatedAt":    "2024-01-01",
+	}
+
+	delta := diffSyncEntity(operation, "product a", payload, remote)
+
+	assert.Equal(t, map[string]interface{}{
+		"customFields": payload["customFields"],
+		"description":  "new",
+	}, delta)
+
+	assert.Equal(t, []ConfigSyncChange{
+		{Resource: "product a", Field: "customFields.color", Action: ConfigSyncActionChanged, Old: "blue", New: "red"},
+		{Resource: "product a", Field: "description", Action: ConfigSyncActionAdded, New: "new"},
+	}, operation.Changes)
+
+	assert.Empty(t, diffSyncEntity(newTestSyncOperation(), "product a", map[string]interface{}{"tags": []interface{}{}}, map[string]interface{}{"tags": []interface{}{}}))
+	assert.NotEmpty(t, diffSyncEntity(newTestSyncOperation(), "product a", map[string]interface{}{"tags": []interface{}{}}, map[string]interface{}{"tags": []interface{}{"x"}}))
+}
+
+func TestFormatSyncValue(t *testing.T) {
+	assert.Equal(t, "", formatSyncValue(nil))
+	assert.Equal(t, `{"a":1}`, formatSyncValue(map[string]interface{}{"a": 1}))
+	assert.Equal(t, `line\nbreak`, formatSyncValue("line\nbreak"))
+	assert.Len(t, []rune(formatSyncValue(string(bytes.Repeat([]byte("ä"), 100)))), maxSyncValueLength)
+}
+
+func TestConfigSyncStatePrune(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), ".shopware-cli", "state.json")
+
+	state, err := readConfigSyncState(statePath)
+	require.NoError(t, err)
+	assert.Empty(t, state.Entities)
+
+	state.Entities = []ConfigSyncManagedEntity{{Entity: "tag", Id: "a"}, {Entity: "tag", Id: "b"}, {Entity: "rule", Id: "c"}}
+	require.NoError(t, writeConfigSyncState(statePath, state))
+
+	state, err = readConfigSyncState(statePath)
+	require.NoError(t, err)
+
+	operation := newTestSyncOperation()
+	operation.Managed = []ConfigSyncManagedEntity{{Entity: "tag", Id: "a"}, {Entity: "tag", Id: "d"}, {Entity: "tag", Id: "existing"}}
+	operation.Created = []ConfigSyncManagedEntity{{Entity: "tag", Id: "d"}}
+
+	// Without pruning the removed entities stay in the state
+	assert.Equal(t, []ConfigSyncManagedEntity{{Entity: "tag", Id: "a"}, {Entity: "tag", Id: "d"}, {Entity: "tag", Id: "b"}, {Entity: "rule", Id: "c"}}, nextConfigSyncState(state, operation, false).Entities)
+
+	addPruneOperations(operation, state)
+
+	assert.Equal(t, adminSdk.SyncOperation{Action: "delete", Entity: "tag", Payload: []map[string]interface{}{{"id": "b"}}}, operation.Operations["prune-tag"])
+	assert.Equal(t, adminSdk.SyncOperation{Action: "delete", Entity: "rule", Payload: []map[string]interface{}{{"id": "c"}}}, operation.Operations["prune-rule"])
+	assert.Equal(t, []ConfigSyncChange{
+		{Resource: "tag b", Action: ConfigSyncActionRemoved},
+		{Resource: "rule c", Action: ConfigSyncActionRemoved},
+	}, operation.Changes)
+
+	// Existing entities of the shop matched by the config are never tracked
+	assert.Equal(t, []ConfigSyncManagedEntity{{Entity: "tag", Id: "a"}, {Entity: "tag", Id: "d"}}, nextConfigSyncState(state, operation, true).Entities)
+}
+
+func TestDefaultConfigSyncStatePath(t *testing.T) {
+	assert.Equal(t, filepath.Join("project", ".shopware-cli", "config-sync-state.staging.example.com.json"), defaultConfigSyncStatePath(filepath.Join("project", ".shopware-project.yml"), "https://staging.example.com"))
+	assert.Equal(t, filepath.Join("project", ".shopware-cli", "config-sync-state.localhost_8000.json"), defaultConfigSyncStatePath(filepath.Join("project", ".shopware-project.yml"), "http://localhost:8000/"))
+	assert.Equal(t, filepath.Join("project", ".shopware-cli", "config-sync-state.json"), defaultConfigSyncStatePath(filepath.Join("project", ".shopware-project.yml"), ""))
+}
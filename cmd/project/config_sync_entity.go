@@ -1,58 +1,93 @@
 package project
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
+	"maps"
+	"net/http"
+	"slices"
 
 	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
 
-	"github.com/shopware/shopware-cli/logging"
 	"github.com/shopware/shopware-cli/shop"
 )
 
 type EntitySync struct{}
 
 func (EntitySync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config, operation *ConfigSyncOperation) error {
+	if len(config.Sync.Entity) == 0 {
+		return nil
+	}
+
+	// The schema tells which payload fields are associations, they have to be loaded to compare them
+	schema, err := loadEntitySchema(ctx, client)
+	if err != nil {
+		return err
+	}
+
 	for _, entity := range config.Sync.Entity {
-		if entity.Exists != nil && len(*entity.Exists) > 0 {
-			criteria := make(map[string]interface{})
-			criteria["filter"] = entity.Exists
+		payload := maps.Clone(entity.Payload)
+		if payload == nil {
+			payload = map[string]interface{}{}
+		}
+
+		hasFilter := entity.Exists != nil && len(*entity.Exists) > 0
+		id, _ := payload["id"].(string)
 
-			searchPayload, err := json.Marshal(criteria)
-			if err != nil {
+		if id == "" && hasFilter {
+			if id, err = searchSyncEntityId(ctx, client, entity); err != nil {
 				return err
 			}
+		}
+
+		var remote map[string]interface{}
+
+		exists := false
 
-			r, err := client.NewRequest(ctx, "POST", fmt.Sprintf("/api/search-ids/%s", entity.Entity), bytes.NewReader(searchPayload))
-			if err != nil {
+		if id != "" {
+			if remote, exists, err = fetchSyncEntity(ctx, client, schema, entity.Entity, id, payload); err != nil {
 				return err
 			}
+		}
 
-			r.Header.Set("Accept", "application/json")
-			r.Header.Set("Content-Type", "application/json")
+		if !exists {
+			// New entities found by a filter get an id, so they can be tracked in the state
+			if id == "" && hasFilter {
+				id = shop.NewUuid()
+			}
 
-			var res criteriaApiResponse
-			resp, err := client.Do(ctx.Context, r, &res)
-			if err != nil {
-				return err
+			if id != "" {
+				payload["id"] = id
 			}
 
-			defer func() {
-				if err := resp.Body.Close(); err != nil {
-					logging.FromContext(ctx.Context).Errorf("Push: %v", err)
+			resource := syncEntityResource(entity.Entity, id)
+
+			for _, key := range slices.Sorted(maps.Keys(payload)) {
+				if key != "id" {
+					operation.addChange(resource, key, ConfigSyncActionAdded, nil, payload[key])
 				}
-			}()
+			}
+
+			operation.Operations[shop.NewUuid()] = adminSdk.SyncOperation{
+				Action:  "upsert",
+				Entity:  entity.Entity,
+				Payload: []map[string]interface{}{payload},
+			}
+
+			if id != "" {
+				operation.Created = append(operation.Created, ConfigSyncManagedEntity{Entity: entity.Entity, Id: id})
+			}
+		} else if delta := diffSyncEntity(operation, syncEntityResource(entity.Entity, id), payload, remote); len(delta) > 0 {
+			delta["id"] = id
 
-			if res.Total > 0 {
-				continue
+			operation.Operations[shop.NewUuid()] = adminSdk.SyncOperation{
+				Action:  "upsert",
+				Entity:  entity.Entity,
+				Payload: []map[string]interface{}{delta},
 			}
 		}
 
-		operation.Operations[shop.NewUuid()] = adminSdk.SyncOperation{
-			Action:  "upsert",
-			Entity:  entity.Entity,
-			Payload: []map[string]interface{}{entity.Payload},
+		if id != "" {
+			operation.Managed = append(operation.Managed, ConfigSyncManagedEntity{Entity: entity.Entity, Id: id})
 		}
 	}
 
@@ -63,6 +98,69 @@ func (EntitySync) Pull(_ adminSdk.ApiContext, _ *adminSdk.Client, _ *shop.Config
 	return nil
 }
 
+func syncEntityResource(entity, id string) string {
+	if id == "" {
+		return entity + " (new)"
+	}
+
+	return fmt.Sprintf("%s %s", entity, id)
+}
+
+func searchSyncEntityId(ctx adminSdk.ApiContext, client *adminSdk.Client, entity shop.EntitySync) (string, error) {
+	criteria := map[string]interface{}{"filter": entity.Exists, "limit": 1}
+
+	r, err := client.NewRequest(ctx, http.MethodPost, fmt.Sprintf("/api/search-ids/%s", entity.Entity), criteria)
+	if err != nil {
+		return "", err
+	}
+
+	var res criteriaApiResponse
+	if _, err := client.Do(ctx.Context, r, &res); err != nil {
+		return "", err
+	}
+
+	if len(res.Data) == 0 {
+		return "", nil
+	}
+
+	return res.Data[0], nil
+}
+
+// fetchSyncEntity returns the entity with the associations of the payload and whether it exists.
+func fetchSyncEntity(ctx adminSdk.ApiContext, client *adminSdk.Client, schema entitySchema, entity, id string, payload map[string]interface{}) (map[string]interface{}, bool, error) {
+	criteria := map[string]interface{}{"ids": []string{id}}
+	associations := map[string]interface{}{}
+
+	for key := range payload {
+		if schema[entity].Properties[key].Type == "association" {
+			associations[key] = map[string]interface{}{}
+		}
+	}
+
+	if len(associations) > 0 {
+		criteria["associations"] = associations
+	}
+
+	r, err := client.NewRequest(ctx, http.MethodPost, fmt.Sprintf("/api/search/%s", entity), criteria)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var result struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+
+	if _, err := client.Do(ctx.Context, r, &result); err != nil {
+		return nil, false, fmt.Errorf("fetch %s %s: %w", entity, id, err)
+	}
+
+	if len(result.Data) == 0 {
+		return nil, false, nil
+	}
+
+	return result.Data[0], true, nil
+}
+
 type criteriaApiResponse struct {
 	Total int      `json:"total"`
 	Data  []string `json:"data"`
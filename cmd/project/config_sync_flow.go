@@ -0,0 +1,288 @@
+package project
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+// FlowSync synchronizes the Flow Builder flows, conditions reference rules by their key.
+type FlowSync struct{}
+
+func (FlowSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config, operation *ConfigSyncOperation) error {
+	if len(config.Sync.Flow) == 0 {
+		return nil
+	}
+
+	remote, err := searchSyncEntities(ctx, client, "flow", flowCriteria())
+	if err != nil {
+		return err
+	}
+
+	ruleKeys, err := loadSyncRuleKeys(ctx, client, config.Sync.Rule)
+	if err != nil {
+		return err
+	}
+
+	ruleIds := map[string]string{}
+	for id, key := range ruleKeys {
+		ruleIds[key] = id
+	}
+
+	refs := make([]syncEntityRef, 0, len(config.Sync.Flow))
+	for _, flow := range config.Sync.Flow {
+		refs = append(refs, syncEntityRef{Key: flow.Key, Name: flow.Name})
+	}
+
+	resolved := resolveSyncEntities(remote, "flow", refs)
+
+	for i, flow := range config.Sync.Flow {
+		id, existing := resolved[i].Id, resolved[i].Existing
+		resource := "flow " + flow.Key
+
+		payload, err := flowPayload(flow, id, ruleIds)
+		if err != nil {
+			return fmt.Errorf("flow %s: %w", flow.Key, err)
+		}
+
+		if existing == nil {
+			operation.addChange(resource, "", ConfigSyncActionAdded, nil, flow.Name)
+		} else if !diffSyncConfig(operation, resource, flow, flowFromEntity(existing, flow.Key, ruleKeys)) {
+			continue
+		}
+
+		operation.Operations[syncOperationFlowPrefix+flow.Key] = adminSdk.SyncOperation{
+			Action:  "upsert",
+			Entity:  "flow",
+			Payload: []map[string]interface{}{payload},
+		}
+
+		addSyncDeletions(operation, syncOperationDeletePrefix+"flow-"+flow.Key, staleSyncChildren(syncList(existing, "sequences"), flowSequenceIds(payload), "flow_sequence"))
+	}
+
+	return nil
+}
+
+func (FlowSync) Pull(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config) error {
+	remote, err := searchSyncEntities(ctx, client, "flow", flowCriteria())
+	if err != nil {
+		return err
+	}
+
+	ruleKeys, err := loadSyncRuleKeys(ctx, client, nil)
+	if err != nil {
+		return err
+	}
+
+	keys := assignSyncKeys(remote)
+
+	config.Sync.Flow = make([]shop.Flow, 0, len(remote))
+
+	for _, flow := range remote {
+		config.Sync.Flow = append(config.Sync.Flow, flowFromEntity(flow, keys[syncString(flow, "id")], ruleKeys))
+	}
+
+	slices.SortFunc(config.Sync.Flow, func(a, b shop.Flow) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return nil
+}
+
+func flowCriteria() map[string]interface{} {
+	return map[string]interface{}{
+		"associations": map[string]interface{}{"sequences": map[string]interface{}{}},
+	}
+}
+
+// loadSyncRuleKeys returns the keys of all rules by id, the rules of the config keep their configured key.
+func loadSyncRuleKeys(ctx adminSdk.ApiContext, client *adminSdk.Client, configured []shop.Rule) (map[string]string, error) {
+	rules, err := searchSyncEntities(ctx, client, "rule", map[string]interface{}{
+		"includes": map[string][]string{"rule": {"id", "name"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return syncRuleKeys(rules, configured), nil
+}
+
+func syncRuleKeys(rules []map[string]interface{}, configured []shop.Rule) map[string]string {
+	keys := assignSyncKeys(rules)
+
+	resolved := resolveSyncEntities(rules, "rule", ruleSyncRefs(configured))
+
+	for i, rule := range configured {
+		id := resolved[i].Id
+
+		// A configured key replaces the key derived from the name
+		for otherId, key := range keys {
+			if key == rule.Key && otherId != id {
+				delete(keys, otherId)
+			}
+		}
+
+		keys[id] = rule.Key
+	}
+
+	return keys
+}
+
+// flowFromEntity converts the flat sequence list of the API into the nested sequences of the config.
+func flowFromEntity(flow map[string]interface{}, key string, ruleKeys map[string]string) shop.Flow {
+	children := map[string][]map[string]interface{}{}
+
+	for _, sequence := range syncList(flow, "sequences") {
+		parentId := syncString(sequence, "parentId")
+		children[parentId] = append(children[parentId], sequence)
+	}
+
+	var build func(sequences []map[string]interface{}, root bool) []shop.FlowSequence
+
+	build = func(sequences []map[string]interface{}, root bool) []shop.FlowSequence {
+		slices.SortStableFunc(sequences, func(a, b map[string]interface{}) int {
+			return cmp.Or(cmp.Compare(syncNumber(a, "displayGroup"), syncNumber(b, "displayGroup")), cmp.Compare(syncNumber(a, "position"), syncNumber(b, "position")))
+		})
+
+		var result []shop.FlowSequence
+
+		for _, sequence := range sequences {
+			flowSequence := shop.FlowSequence{Action: syncString(sequence, "actionName"), Config: syncObject(sequence, "config")}
+
+			if displayGroup := int(syncNumber(sequence, "displayGroup")); root && displayGroup > 1 {
+				flowSequence.DisplayGroup = displayGroup
+			}
+
+			if ruleId := syncString(sequence, "ruleId"); ruleId != "" {
+				flowSequence.Rule = cmp.Or(ruleKeys[ruleId], ruleId)
+
+				var trueCase, falseCase []map[string]interface{}
+
+				for _, child := range children[syncString(sequence, "id")] {
+					if child["trueCase"] == true {
+						trueCase = append(trueCase, child)
+					} else {
+						falseCase = append(falseCase, child)
+					}
+				}
+
+				flowSequence.True = build(trueCase, false)
+				flowSequence.False = build(falseCase, false)
+			}
+
+			result = append(result, flowSequence)
+		}
+
+		return result
+	}
+
+	return shop.Flow{
+		Key:         key,
+		Name:        syncString(flow, "name"),
+		EventName:   syncString(flow, "eventName"),
+		Priority:    int(syncNumber(flow, "priority")),
+		Active:      flow["active"] == true,
+		Description: syncString(flow, "description"),
+		Sequences:   build(children[""], true),
+	}
+}
+
+// flowPayload returns the upsert payload of the flow, sequences get ids derived from their position in the tree.
+func flowPayload(flow shop.Flow, id string, ruleIds map[string]string) (map[string]interface{}, error) {
+	var build func(sequences []shop.FlowSequence, path string, displayGroup int, trueCase bool) ([]map[string]interface{}, error)
+
+	build = func(sequences []shop.FlowSequence, path string, displayGroup int, trueCase bool) ([]map[string]interface{}, error) {
+		payload := make([]map[string]interface{}, 0, len(sequences))
+
+		for i, sequence := range sequences {
+			sequencePath := path + "/" + strconv.Itoa(i)
+			group := displayGroup
+
+			// The whole tree below a root sequence belongs to its display group
+			if path == "" {
+				group = max(sequence.DisplayGroup, 1)
+			}
+
+			item := map[string]interface{}{
+				"id":           syncEntityId(id, "sequence", sequencePath),
+				"flowId":       id,
+				"position":     i + 1,
+				"displayGroup": group,
+				"trueCase":     trueCase,
+			}
+
+			if sequence.Rule != "" {
+				ruleId, ok := ruleIds[sequence.Rule]
+				if !ok {
+					return nil, fmt.Errorf("unknown rule %s", sequence.Rule)
+				}
+
+				trueChildren, err := build(sequence.True, sequencePath+"/true", group, true)
+				if err != nil {
+					return nil, err
+				}
+
+				falseChildren, err := build(sequence.False, sequencePath+"/false", group, false)
+				if err != nil {
+					return nil, err
+				}
+
+				item["ruleId"] = ruleId
+				item["children"] = append(trueChildren, falseChildren...)
+			} else {
+				config := sequence.Config
+				if config == nil {
+					config = map[string]interface{}{}
+				}
+
+				item["actionName"] = sequence.Action
+				item["config"] = config
+			}
+
+			payload = append(payload, item)
+		}
+
+		return payload, nil
+	}
+
+	sequences, err := build(flow.Sequences, "", 1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":          id,
+		"name":        flow.Name,
+		"eventName":   flow.EventName,
+		"priority":    flow.Priority,
+		"active":      flow.Active,
+		"description": flow.Description,
+		"sequences":   sequences,
+	}, nil
+}
+
+func flowSequenceIds(payload map[string]interface{}) map[string]bool {
+	ids := map[string]bool{}
+
+	var collect func(sequences []map[string]interface{})
+
+	collect = func(sequences []map[string]interface{}) {
+		for _, sequence := range sequences {
+			ids[sequence["id"].(string)] = true
+
+			if children, ok := sequence["children"].([]map[string]interface{}); ok {
+				collect(children)
+			}
+		}
+	}
+
+	sequences, _ := payload["sequences"].([]map[string]interface{})
+	collect(sequences)
+
+	return ids
+}
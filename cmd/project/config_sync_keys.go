@@ -0,0 +1,242 @@
+package project
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// Operations are applied in the order of their keys, rules have to exist before the flows using them.
+const (
+	syncOperationDeletePrefix = "sync-0-delete-"
+	syncOperationRulePrefix   = "sync-1-rule-"
+	syncOperationCmsPrefix    = "sync-1-cms-"
+	syncOperationFlowPrefix   = "sync-2-flow-"
+)
+
+// syncKeyFromName returns a readable technical key like summer-sale for the name Summer Sale.
+func syncKeyFromName(name string) string {
+	var key strings.Builder
+
+	dash := false
+
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			if dash && key.Len() > 0 {
+				key.WriteByte('-')
+			}
+
+			key.WriteRune(c)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	if key.Len() == 0 {
+		return "unnamed"
+	}
+
+	return key.String()
+}
+
+// assignSyncKeys returns the keys of the entities by id. Entities are ordered by name and id, so duplicate names get the same suffix on every pull.
+func assignSyncKeys(entities []map[string]interface{}) map[string]string {
+	sorted := slices.Clone(entities)
+	slices.SortFunc(sorted, func(a, b map[string]interface{}) int {
+		return cmp.Or(cmp.Compare(syncString(a, "name"), syncString(b, "name")), cmp.Compare(syncString(a, "id"), syncString(b, "id")))
+	})
+
+	keys := map[string]string{}
+	used := map[string]bool{}
+
+	for _, entity := range sorted {
+		base := syncKeyFromName(syncString(entity, "name"))
+		key := base
+
+		for i := 2; used[key]; i++ {
+			key = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		used[key] = true
+		keys[syncString(entity, "id")] = key
+	}
+
+	return keys
+}
+
+// syncEntityId returns a deterministic id, so the same key results in the same id in every environment.
+func syncEntityId(parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "/")))
+
+	return hex.EncodeToString(hash[:16])
+}
+
+// syncEntityRef is a configured entity identified by its technical key.
+type syncEntityRef struct {
+	Key  string
+	Name string
+}
+
+// resolvedSyncEntity is the id of a configured entity and the remote entity, which is nil when it has to be created.
+type resolvedSyncEntity struct {
+	Id       string
+	Existing map[string]interface{}
+}
+
+// resolveSyncEntities finds the remote entities of the keys. The deterministic id is preferred, an entity with the same name is reused,
+// so entities created in the Administration are updated instead of duplicated. The name is only used when it is unique in the shop
+// and the entity was not claimed by another key, otherwise the deterministic id is used for a new entity.
+func resolveSyncEntities(remote []map[string]interface{}, entity string, refs []syncEntityRef) []resolvedSyncEntity {
+	byId := map[string]map[string]interface{}{}
+	byName := map[string][]map[string]interface{}{}
+
+	for _, candidate := range remote {
+		byId[syncString(candidate, "id")] = candidate
+		byName[syncString(candidate, "name")] = append(byName[syncString(candidate, "name")], candidate)
+	}
+
+	claimed := map[string]bool{}
+
+	for _, ref := range refs {
+		if id := syncEntityId(entity, ref.Key); byId[id] != nil {
+			claimed[id] = true
+		}
+	}
+
+	resolved := make([]resolvedSyncEntity, 0, len(refs))
+
+	for _, ref := range refs {
+		id := syncEntityId(entity, ref.Key)
+
+		if existing := byId[id]; existing != nil {
+			resolved = append(resolved, resolvedSyncEntity{Id: id, Existing: existing})
+
+			continue
+		}
+
+		if candidates := byName[ref.Name]; ref.Name != "" && len(candidates) == 1 && !claimed[syncString(candidates[0], "id")] {
+			claimed[syncString(candidates[0], "id")] = true
+			resolved = append(resolved, resolvedSyncEntity{Id: syncString(candidates[0], "id"), Existing: candidates[0]})
+
+			continue
+		}
+
+		resolved = append(resolved, resolvedSyncEntity{Id: id})
+	}
+
+	return resolved
+}
+
+func searchSyncEntities(ctx adminSdk.ApiContext, client *adminSdk.Client, entity string, criteria map[string]interface{}) ([]map[string]interface{}, error) {
+	return searchAllEntities(ctx, client, entity, criteria, 100, func(int, int) {})
+}
+
+func syncString(entity map[string]interface{}, field string) string {
+	value, _ := entity[field].(string)
+
+	return value
+}
+
+func syncNumber(entity map[string]interface{}, field string) float64 {
+	value, _ := entity[field].(float64)
+
+	return value
+}
+
+func syncList(entity map[string]interface{}, field string) []map[string]interface{} {
+	values, _ := entity[field].([]interface{})
+	result := make([]map[string]interface{}, 0, len(values))
+
+	for _, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+func syncObject(entity map[string]interface{}, field string) map[string]interface{} {
+	value, _ := entity[field].(map[string]interface{})
+
+	if len(value) == 0 {
+		return nil
+	}
+
+	return value
+}
+
+func sortByPosition(entities []map[string]interface{}) {
+	slices.SortStableFunc(entities, func(a, b map[string]interface{}) int {
+		return cmp.Compare(syncNumber(a, "position"), syncNumber(b, "position"))
+	})
+}
+
+// pickSyncFields returns the fields of the entity which are set.
+func pickSyncFields(entity map[string]interface{}, fields []string) map[string]interface{} {
+	picked := map[string]interface{}{}
+
+	for _, field := range fields {
+		if value, ok := entity[field]; ok && value != nil {
+			picked[field] = value
+		}
+	}
+
+	if len(picked) == 0 {
+		return nil
+	}
+
+	return picked
+}
+
+// normalizeSyncConfig converts a config struct into plain values, empty fields are omitted like in the YAML file.
+func normalizeSyncConfig(value interface{}) map[string]interface{} {
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return nil
+	}
+
+	var normalized interface{}
+	if err := yaml.Unmarshal(content, &normalized); err != nil {
+		return nil
+	}
+
+	result, _ := normalizeSyncValue(normalized).(map[string]interface{})
+
+	return result
+}
+
+// diffSyncConfig records the changed top level fields between the config and the config converted from the shop.
+func diffSyncConfig(operation *ConfigSyncOperation, resource string, local, remote interface{}) bool {
+	l := normalizeSyncConfig(local)
+	r := normalizeSyncConfig(remote)
+	changed := false
+
+	for _, key := range slices.Sorted(maps.Keys(l)) {
+		if value, ok := r[key]; !ok || !equalSyncValue(l[key], value) {
+			addSyncFieldChanges(operation, resource, key, l[key], value)
+			changed = true
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(r)) {
+		if _, ok := l[key]; !ok {
+			operation.addChange(resource, key, ConfigSyncActionRemoved, r[key], nil)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func equalSyncValue(a, b interface{}) bool {
+	return containsSyncValue(a, b) && containsSyncValue(b, a)
+}
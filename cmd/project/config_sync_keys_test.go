@@ -0,0 +1,198 @@
+package project
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+// apiEntity decodes the JSON like the API client does, so numbers are float64.
+func apiEntity(t *testing.T, content string) map[string]interface{} {
+	t.Helper()
+
+	var entity map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(content), &entity))
+
+	return entity
+}
+
+func TestSyncKeys(t *testing.T) {
+	assert.Equal(t, "summer-sale-2024", syncKeyFromName("  Summer Sale: 2024!"))
+	assert.Equal(t, "unnamed", syncKeyFromName("!!!"))
+
+	keys := assignSyncKeys([]map[string]interface{}{
+		{"id": "b", "name": "Default Layout"},
+		{"id": "a", "name": "Default layout"},
+		{"id": "c", "name": "Other"},
+	})
+
+	assert.Equal(t, map[string]string{"a": "default-layout-2", "b": "default-layout", "c": "other"}, keys)
+
+	id := syncEntityId("rule", "vip")
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, syncEntityId("rule", "vip"))
+	assert.NotEqual(t, id, syncEntityId("rule", "vip-2"))
+
+	remote := []map[string]interface{}{{"id": "existing", "name": "VIP"}, {"id": syncEntityId("rule", "pushed"), "name": "Renamed"}}
+
+	resolved := resolveSyncEntities(remote, "rule", []syncEntityRef{{Key: "vip", Name: "VIP"}, {Key: "pushed", Name: "Other name"}, {Key: "new", Name: "New"}})
+	assert.Equal(t, "existing", resolved[0].Id)
+	assert.NotNil(t, resolved[0].Existing)
+	assert.Equal(t, syncEntityId("rule", "pushed"), resolved[1].Id)
+	assert.NotNil(t, resolved[1].Existing)
+	assert.Equal(t, syncEntityId("rule", "new"), resolved[2].Id)
+	assert.Nil(t, resolved[2].Existing)
+
+	// An ambiguous name is never reused
+	remote = []map[string]interface{}{{"id": "first", "name": "VIP"}, {"id": "second", "name": "VIP"}}
+	resolved = resolveSyncEntities(remote, "rule", []syncEntityRef{{Key: "vip", Name: "VIP"}})
+	assert.Equal(t, []resolvedSyncEntity{{Id: syncEntityId("rule", "vip")}}, resolved)
+
+	// A name is reused only once, another key with the same name creates a new entity
+	remote = []map[string]interface{}{{"id": "existing", "name": "VIP"}}
+	resolved = resolveSyncEntities(remote, "rule", []syncEntityRef{{Key: "vip", Name: "VIP"}, {Key: "vip-copy", Name: "VIP"}})
+	assert.Equal(t, "existing", resolved[0].Id)
+	assert.Equal(t, resolvedSyncEntity{Id: syncEntityId("rule", "vip-copy")}, resolved[1])
+
+	// An entity found by the deterministic id of another key is not taken by name
+	pushed := map[string]interface{}{"id": syncEntityId("rule", "pushed"), "name": "VIP"}
+	resolved = resolveSyncEntities([]map[string]interface{}{pushed}, "rule", []syncEntityRef{{Key: "vip", Name: "VIP"}, {Key: "pushed", Name: "VIP"}})
+	assert.Equal(t, []resolvedSyncEntity{{Id: syncEntityId("rule", "vip")}, {Id: syncEntityId("rule", "pushed"), Existing: pushed}}, resolved)
+}
+
+func TestCmsLayoutSync(t *testing.T) {
+	page := apiEntity(t, `{
+		"id": "page", "name": "Landing", "type": "landingpage", "cssClass": null, "locked": false,
+		"sections": [
+			{"id": "s2", "position": 1, "type": "default", "sizingMode": "boxed", "blocks": []},
+			{"id": "s1", "position": 0, "type": "sidebar", "sizingMode": "full_width", "blocks": [
+				{"id": "b1", "position": 0, "type": "text", "sectionPosition": "main", "marginTop": "20px", "slots": [
+					{"id": "slot-content", "slot": "content", "type": "text", "config": {"content": {"source": "static", "value": "Hello"}}},
+					{"id": "slot-old", "slot": "old", "type": "text", "config": null}
+				]}
+			]}
+		]
+	}`)
+
+	layout := cmsLayoutFromEntity(page, "landing")
+
+	assert.Equal(t, "landing", layout.Key)
+	assert.Nil(t, layout.Fields)
+	require.Len(t, layout.Sections, 2)
+	assert.Equal(t, "sidebar", layout.Sections[0].Type)
+	assert.Equal(t, map[string]interface{}{"sizingMode": "full_width"}, layout.Sections[0].Fields)
+	assert.Equal(t, []shop.CmsSlot{
+		{Slot: "content", Type: "text", Config: map[string]interface{}{"content": map[string]interface{}{"source": "static", "value": "Hello"}}},
+		{Slot: "old", Type: "text"},
+	}, layout.Sections[0].Blocks[0].Slots)
+
+	// The pulled layout has no changes
+	operation := newTestSyncOperation()
+	assert.False(t, diffSyncConfig(operation, "cms_page landing", layout, cmsLayoutFromEntity(page, "landing")))
+
+	// Drop the second section and the old slot, add a new block
+	layout.Sections = layout.Sections[:1]
+	layout.Sections[0].Blocks[0].Slots = layout.Sections[0].Blocks[0].Slots[:1]
+	layout.Sections[0].Blocks = append(layout.Sections[0].Blocks, shop.CmsBlock{Type: "image", Slots: []shop.CmsSlot{{Slot: "image", Type: "image"}}})
+
+	assert.True(t, diffSyncConfig(operation, "cms_page landing", layout, cmsLayoutFromEntity(page, "landing")))
+	assert.Equal(t, "sections", operation.Changes[0].Field)
+
+	payload, deletions := cmsLayoutPayload(layout, "page", page)
+
+	assert.Equal(t, map[string][]string{"cms_section": {"s2"}, "cms_slot": {"slot-old"}}, deletions)
+
+	sections := payload["sections"].([]map[string]interface{})
+	require.Len(t, sections, 1)
+	assert.Equal(t, "s1", sections[0]["id"])
+	assert.Equal(t, "full_width", sections[0]["sizingMode"])
+
+	blocks := sections[0]["blocks"].([]map[string]interface{})
+	require.Len(t, blocks, 2)
+	assert.Equal(t, "b1", blocks[0]["id"])
+	assert.Equal(t, syncEntityId("s1", "block", "1"), blocks[1]["id"])
+	assert.Equal(t, syncEntityId(blocks[1]["id"].(string), "slot", "image"), blocks[1]["slots"].([]map[string]interface{})[0]["id"])
+}
+
+func TestRuleSync(t *testing.T) {
+	rule := apiEntity(t, `{
+		"id": "rule", "name": "VIP customers", "priority": 100, "description": null,
+		"conditions": [
+			{"id": "and", "parentId": "or", "type": "andContainer", "position": 0, "value": null},
+			{"id": "group", "parentId": "and", "type": "customerCustomerGroup", "position": 0, "value": {"operator": "=", "customerGroupIds": ["g"]}},
+			{"id": "or", "parentId": null, "type": "orContainer", "position": 0, "value": null}
+		]
+	}`)
+
+	config := ruleFromEntity(rule, "vip-customers")
+
+	assert.Equal(t, shop.Rule{
+		Key:      "vip-customers",
+		Name:     "VIP customers",
+		Priority: 100,
+		Conditions: []shop.RuleCondition{{Type: "orContainer", Children: []shop.RuleCondition{{Type: "andContainer", Children: []shop.RuleCondition{
+			{Type: "customerCustomerGroup", Value: map[string]interface{}{"operator": "=", "customerGroupIds": []interface{}{"g"}}},
+		}}}}},
+	}, config)
+
+	payload, deletions := rulePayload(config, "rule", rule)
+
+	// All existing conditions have random ids, deleting the root removes its children
+	assert.Equal(t, map[string][]string{"rule_condition": {"or"}}, deletions)
+
+	root := payload["conditions"].([]map[string]interface{})[0]
+	assert.Equal(t, syncEntityId("rule", "condition", "/0"), root["id"])
+	assert.Equal(t, "rule", root["children"].([]map[string]interface{})[0]["ruleId"])
+
+	// Pushing the same rule again keeps all conditions
+	payload, _ = rulePayload(config, "rule", nil)
+	_, deletions = rulePayload(config, "rule", map[string]interface{}{"conditions": []interface{}{
+		map[string]interface{}{"id": payload["conditions"].([]map[string]interface{})[0]["id"]},
+	}})
+	assert.Empty(t, deletions)
+}
+
+func TestFlowSync(t *testing.T) {
+	flow := apiEntity(t, `{
+		"id": "flow", "name": "Order placed", "eventName": "checkout.order.placed", "priority": 1, "active": true,
+		"sequences": [
+			{"id": "if", "parentId": null, "ruleId": "rule-id", "actionName": null, "position": 1, "displayGroup": 1, "trueCase": false},
+			{"id": "mail", "parentId": "if", "ruleId": null, "actionName": "action.mail.send", "config": {"mailTemplateId": "m"}, "position": 1, "displayGroup": 1, "trueCase": true},
+			{"id": "tag", "parentId": "if", "ruleId": null, "actionName": "action.add.order.tag", "config": {}, "position": 1, "displayGroup": 1, "trueCase": false},
+			{"id": "second", "parentId": null, "ruleId": null, "actionName": "action.stop.flow", "config": [], "position": 1, "displayGroup": 2, "trueCase": false}
+		]
+	}`)
+
+	ruleKeys := syncRuleKeys([]map[string]interface{}{{"id": "rule-id", "name": "Always valid"}}, []shop.Rule{{Key: "always", Name: "Always valid"}})
+	assert.Equal(t, map[string]string{"rule-id": "always"}, ruleKeys)
+
+	config := flowFromEntity(flow, "order-placed", ruleKeys)
+
+	assert.Equal(t, []shop.FlowSequence{
+		{
+			Rule:  "always",
+			True:  []shop.FlowSequence{{Action: "action.mail.send", Config: map[string]interface{}{"mailTemplateId": "m"}}},
+			False: []shop.FlowSequence{{Action: "action.add.order.tag"}},
+		},
+		{Action: "action.stop.flow", DisplayGroup: 2},
+	}, config.Sequences)
+
+	payload, err := flowPayload(config, "flow", map[string]string{"always": "rule-id"})
+	require.NoError(t, err)
+
+	sequences := payload["sequences"].([]map[string]interface{})
+	assert.Equal(t, "rule-id", sequences[0]["ruleId"])
+	assert.Equal(t, 2, sequences[1]["displayGroup"])
+
+	children := sequences[0]["children"].([]map[string]interface{})
+	assert.Equal(t, true, children[0]["trueCase"])
+	assert.Equal(t, false, children[1]["trueCase"])
+	assert.Len(t, flowSequenceIds(payload), 4)
+
+	_, err = flowPayload(config, "flow", map[string]string{})
+	assert.ErrorContains(t, err, "unknown rule always")
+}
@@ -38,19 +38,23 @@ func (MailTemplateSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 					for _, configTranslation := range configEntry.Translations {
 						if translation.Language.Name == configTranslation.Language {
 							translationUpdate := make(map[string]interface{})
+							resource := fmt.Sprintf("mail_template %s (%s)", configEntry.Id, configTranslation.Language)
 
 							if translation.SenderName != configTranslation.SenderName {
 								translationUpdate["senderName"] = configTranslation.SenderName
+								operation.addChange(resource, "senderName", ConfigSyncActionChanged, translation.SenderName, configTranslation.SenderName)
 							}
 
 							if translation.Subject != configTranslation.Subject {
 								translationUpdate["subject"] = configTranslation.Subject
+								operation.addChange(resource, "subject", ConfigSyncActionChanged, translation.Subject, configTranslation.Subject)
 							}
 
 							if configTranslation.HTML != "" {
 								if content, err := os.ReadFile(configTranslation.HTML); err == nil {
 									if translation.ContentHtml != string(content) {
 										translationUpdate["contentHtml"] = string(content)
+										operation.addChange(resource, "contentHtml", ConfigSyncActionChanged, translation.ContentHtml, string(content))
 									}
 								} else {
 									logging.FromContext(ctx.Context).Errorf("Cannot read file %s, with error: %s", configTranslation.HTML, err)
@@ -61,6 +65,7 @@ func (MailTemplateSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 								if content, err := os.ReadFile(configTranslation.Plain); err == nil {
 									if translation.ContentPlain != string(content) {
 										translationUpdate["contentPlain"] = string(content)
+										operation.addChange(resource, "contentPlain", ConfigSyncActionChanged, translation.ContentPlain, string(content))
 									}
 								} else {
 									logging.FromContext(ctx.Context).Errorf("Cannot read file %s, with error: %s", configTranslation.Plain, err)
@@ -72,6 +77,7 @@ func (MailTemplateSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 
 							if !bytes.Equal(localCustomFields, remoteCustomFields) {
 								translationUpdate["customFields"] = configTranslation.CustomFields
+								operation.addChange(resource, "customFields", ConfigSyncActionChanged, translation.CustomFields, configTranslation.CustomFields)
 							}
 
 							if len(translationUpdate) > 0 {
@@ -0,0 +1,186 @@
+package project
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+// RuleSync synchronizes the Rule Builder rules with their nested conditions.
+type RuleSync struct{}
+
+func (RuleSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config, operation *ConfigSyncOperation) error {
+	if len(config.Sync.Rule) == 0 {
+		return nil
+	}
+
+	remote, err := searchSyncEntities(ctx, client, "rule", ruleCriteria())
+	if err != nil {
+		return err
+	}
+
+	resolved := resolveSyncEntities(remote, "rule", ruleSyncRefs(config.Sync.Rule))
+
+	for i, rule := range config.Sync.Rule {
+		id, existing := resolved[i].Id, resolved[i].Existing
+		resource := "rule " + rule.Key
+
+		if existing == nil {
+			operation.addChange(resource, "", ConfigSyncActionAdded, nil, rule.Name)
+		} else if !diffSyncConfig(operation, resource, rule, ruleFromEntity(existing, rule.Key)) {
+			continue
+		}
+
+		payload, deletions := rulePayload(rule, id, existing)
+
+		operation.Operations[syncOperationRulePrefix+rule.Key] = adminSdk.SyncOperation{
+			Action:  "upsert",
+			Entity:  "rule",
+			Payload: []map[string]interface{}{payload},
+		}
+
+		addSyncDeletions(operation, syncOperationDeletePrefix+"rule-"+rule.Key, deletions)
+	}
+
+	return nil
+}
+
+func (RuleSync) Pull(ctx adminSdk.ApiContext, client *adminSdk.Client, config *shop.Config) error {
+	remote, err := searchSyncEntities(ctx, client, "rule", ruleCriteria())
+	if err != nil {
+		return err
+	}
+
+	keys := assignSyncKeys(remote)
+
+	config.Sync.Rule = make([]shop.Rule, 0, len(remote))
+
+	for _, rule := range remote {
+		config.Sync.Rule = append(config.Sync.Rule, ruleFromEntity(rule, keys[syncString(rule, "id")]))
+	}
+
+	slices.SortFunc(config.Sync.Rule, func(a, b shop.Rule) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return nil
+}
+
+func ruleSyncRefs(rules []shop.Rule) []syncEntityRef {
+	refs := make([]syncEntityRef, 0, len(rules))
+
+	for _, rule := range rules {
+		refs = append(refs, syncEntityRef{Key: rule.Key, Name: rule.Name})
+	}
+
+	return refs
+}
+
+func ruleCriteria() map[string]interface{} {
+	return map[string]interface{}{
+		"associations": map[string]interface{}{"conditions": map[string]interface{}{}},
+	}
+}
+
+// ruleFromEntity converts the flat condition list of the API into the nested conditions of the config.
+func ruleFromEntity(rule map[string]interface{}, key string) shop.Rule {
+	children := map[string][]map[string]interface{}{}
+
+	for _, condition := range syncList(rule, "conditions") {
+		parentId := syncString(condition, "parentId")
+		children[parentId] = append(children[parentId], condition)
+	}
+
+	var build func(parentId string) []shop.RuleCondition
+
+	build = func(parentId string) []shop.RuleCondition {
+		conditions := children[parentId]
+		sortByPosition(conditions)
+
+		var result []shop.RuleCondition
+
+		for _, condition := range conditions {
+			result = append(result, shop.RuleCondition{
+				Type:     syncString(condition, "type"),
+				Value:    syncObject(condition, "value"),
+				Children: build(syncString(condition, "id")),
+			})
+		}
+
+		return result
+	}
+
+	return shop.Rule{
+		Key:         key,
+		Name:        syncString(rule, "name"),
+		Priority:    int(syncNumber(rule, "priority")),
+		Description: syncString(rule, "description"),
+		Conditions:  build(""),
+	}
+}
+
+// rulePayload returns the upsert payload of the rule and the conditions to delete.
+// Conditions get ids derived from their position in the tree, so pushing the same rule again does not change them.
+func rulePayload(rule shop.Rule, id string, existing map[string]interface{}) (map[string]interface{}, map[string][]string) {
+	ids := map[string]bool{}
+
+	var build func(conditions []shop.RuleCondition, path string) []map[string]interface{}
+
+	build = func(conditions []shop.RuleCondition, path string) []map[string]interface{} {
+		payload := make([]map[string]interface{}, 0, len(conditions))
+
+		for i, condition := range conditions {
+			conditionPath := path + "/" + strconv.Itoa(i)
+			conditionId := syncEntityId(id, "condition", conditionPath)
+			ids[conditionId] = true
+
+			// Nested children are written with their parent, the rule has to be set on every level
+			payload = append(payload, map[string]interface{}{
+				"id":       conditionId,
+				"ruleId":   id,
+				"type":     condition.Type,
+				"value":    condition.Value,
+				"position": i,
+				"children": build(condition.Children, conditionPath),
+			})
+		}
+
+		return payload
+	}
+
+	payload := map[string]interface{}{
+		"id":          id,
+		"name":        rule.Name,
+		"priority":    rule.Priority,
+		"description": rule.Description,
+		"conditions":  build(rule.Conditions, ""),
+	}
+
+	return payload, staleSyncChildren(syncList(existing, "conditions"), ids, "rule_condition")
+}
+
+// staleSyncChildren returns the children which are not part of the payload anymore.
+// Children of a deleted parent are removed by the database, so only the topmost stale children are deleted.
+func staleSyncChildren(existing []map[string]interface{}, ids map[string]bool, entity string) map[string][]string {
+	stale := map[string]bool{}
+
+	for _, child := range existing {
+		if !ids[syncString(child, "id")] {
+			stale[syncString(child, "id")] = true
+		}
+	}
+
+	deletions := map[string][]string{}
+
+	for _, child := range existing {
+		if stale[syncString(child, "id")] && !stale[syncString(child, "parentId")] {
+			deletions[entity] = append(deletions[entity], syncString(child, "id"))
+		}
+	}
+
+	return deletions
+}
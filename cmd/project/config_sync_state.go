@@ -0,0 +1,111 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+)
+
+// configSyncState remembers the entities managed by the config, so they can be pruned after they were removed from it.
+type configSyncState struct {
+	Entities []ConfigSyncManagedEntity `json:"entities"`
+}
+
+// defaultConfigSyncStatePath returns a state file per shop, so applying the config to several shops does not mix their entities.
+func defaultConfigSyncStatePath(configPath, shopURL string) string {
+	name := "config-sync-state.json"
+
+	if u, err := url.Parse(shopURL); err == nil && u.Host != "" {
+		name = fmt.Sprintf("config-sync-state.%s.json", strings.NewReplacer(":", "_", "/", "_").Replace(u.Host))
+	}
+
+	return filepath.Join(filepath.Dir(configPath), ".shopware-cli", name)
+}
+
+func readConfigSyncState(path string) (*configSyncState, error) {
+	state := &configSyncState{}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func writeConfigSyncState(path string, state *configSyncState) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0o644)
+}
+
+// unmanagedEntities returns the entities of the state which are no longer part of the config.
+func (s *configSyncState) unmanagedEntities(managed []ConfigSyncManagedEntity) []ConfigSyncManagedEntity {
+	unmanaged := make([]ConfigSyncManagedEntity, 0)
+
+	for _, entity := range s.Entities {
+		if !slices.Contains(managed, entity) {
+			unmanaged = append(unmanaged, entity)
+		}
+	}
+
+	return unmanaged
+}
+
+// addPruneOperations deletes the entities which were removed from the config.
+func addPruneOperations(operation *ConfigSyncOperation, state *configSyncState) {
+	deletions := map[string][]map[string]interface{}{}
+
+	for _, entity := range state.unmanagedEntities(operation.Managed) {
+		deletions[entity.Entity] = append(deletions[entity.Entity], map[string]interface{}{"id": entity.Id})
+		operation.addChange(syncEntityResource(entity.Entity, entity.Id), "", ConfigSyncActionRemoved, nil, nil)
+	}
+
+	for entity, payload := range deletions {
+		operation.Operations["prune-"+entity] = adminSdk.SyncOperation{Action: "delete", Entity: entity, Payload: payload}
+	}
+}
+
+// nextConfigSyncState keeps the removed entities in the state until they are pruned. Only entities created by the sync are tracked,
+// existing entities of the shop which are matched by the config are updated but never pruned.
+func nextConfigSyncState(previous *configSyncState, operation *ConfigSyncOperation, pruned bool) *configSyncState {
+	next := &configSyncState{}
+
+	for _, entity := range operation.Managed {
+		if slices.Contains(previous.Entities, entity) || slices.Contains(operation.Created, entity) {
+			next.Entities = append(next.Entities, entity)
+		}
+	}
+
+	if !pruned {
+		next.Entities = append(next.Entities, previous.unmanagedEntities(operation.Managed)...)
+	}
+
+	if next.Entities == nil {
+		next.Entities = []ConfigSyncManagedEntity{}
+	}
+
+	return next
+}
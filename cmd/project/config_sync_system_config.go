@@ -3,6 +3,7 @@ package project
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 
 	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
 
@@ -31,6 +32,11 @@ func (SystemConfigSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 	}()
 
 	for _, config := range config.Sync.Config {
+		resource := "system_config"
+		if config.SalesChannel != nil {
+			resource = fmt.Sprintf("system_config (%s)", *config.SalesChannel)
+		}
+
 		if config.SalesChannel != nil && len(*config.SalesChannel) != 32 {
 			foundId := false
 
@@ -72,6 +78,7 @@ func (SystemConfigSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 
 					if !bytes.Equal(encodedSource, encodedTarget) {
 						operation.SystemSettings[config.SalesChannel][newK] = newV
+						operation.addChange(resource, newK, ConfigSyncActionChanged, existingConfig.ConfigurationValue, newV)
 					}
 
 					break
@@ -80,6 +87,7 @@ func (SystemConfigSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, c
 
 			if !foundKey {
 				operation.SystemSettings[config.SalesChannel][newK] = newV
+				operation.addChange(resource, newK, ConfigSyncActionAdded, nil, newV)
 			}
 		}
 	}
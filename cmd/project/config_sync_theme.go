@@ -58,6 +58,7 @@ func (ThemeSync) Push(ctx adminSdk.ApiContext, client *adminSdk.Client, config *
 
 							if !bytes.Equal(localJson, remoteJson) {
 								op.Settings[remoteFieldName] = localFieldValue
+								operation.addChange("theme "+t.Name, remoteFieldName, ConfigSyncActionChanged, remoteFieldValue.Value, localFieldValue.Value)
 							}
 						}
 					}
@@ -6,14 +6,30 @@ import (
 	"github.com/shopware/shopware-cli/shop"
 )
 
-var projectConfigPath string
+var (
+	projectConfigPath  string
+	projectEnvironment string
+)
 
 var projectRootCmd = &cobra.Command{
 	Use:   "project",
 	Short: "Manage your Shopware Project",
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		// The config is read in many places, the flags are passed to the shop package instead of through the environment of child processes
+		if cmd.Flags().Changed("env") {
+			shop.SetEnvironment(projectEnvironment)
+		}
+
+		strict, _ := cmd.Flags().GetBool("strict-secrets")
+		shop.SetStrictSecrets(strict)
+
+		return nil
+	},
 }
 
 func Register(rootCmd *cobra.Command) {
 	rootCmd.AddCommand(projectRootCmd)
 	projectRootCmd.PersistentFlags().StringVar(&projectConfigPath, "project-config", shop.DefaultConfigFileName(), "Path to config")
+	projectRootCmd.PersistentFlags().StringVar(&projectEnvironment, "env", "", "Environment overlay of the config to use, defaults to the SHOPWARE_ENV environment variable")
+	projectRootCmd.PersistentFlags().Bool("strict-secrets", false, "Rejects plaintext secrets in the config, use secret:// references or environment variables instead")
 }
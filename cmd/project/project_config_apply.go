@@ -0,0 +1,89 @@
+package project
+
+import (
+	"os"
+
+	"github.com/charmbracelet/huh"
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var projectConfigApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Applies the differences of project config plan to the shop",
+	Long: `Shows the plan and sends only the changed fields to the shop.
+With --prune, entities which were managed by the config and have been removed from it are deleted.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		autoApprove, _ := cmd.Flags().GetBool("auto-approve")
+
+		plan, err := buildConfigSyncPlan(cmd)
+		if err != nil {
+			return err
+		}
+
+		operation := plan.operation
+
+		if operation.HasChanges() {
+			if err := writeConfigSyncPlan(os.Stdout, operation.Changes); err != nil {
+				return err
+			}
+
+			if !autoApprove {
+				var confirm bool
+
+				confirmForm := huh.NewForm(
+					huh.NewGroup(
+						huh.NewConfirm().
+							Title("You want to apply these changes to your Shop?").
+							Value(&confirm),
+					),
+				)
+
+				if err := confirmForm.Run(); err != nil {
+					return err
+				}
+
+				if !confirm {
+					return nil
+				}
+			}
+
+			if operation.Operations.HasChanges() {
+				if _, err := plan.client.Bulk.Sync(plan.apiCtx, operation.Operations); err != nil {
+					return err
+				}
+			}
+
+			if operation.SystemSettings.HasChanges() {
+				if _, err := plan.client.SystemConfigManager.UpdateConfig(plan.apiCtx, operation.SystemSettings.ToJson()); err != nil {
+					return err
+				}
+			}
+
+			for _, themeOp := range operation.ThemeSettings {
+				if len(themeOp.Settings) == 0 {
+					continue
+				}
+
+				if _, err := plan.client.ThemeManager.UpdateConfiguration(plan.apiCtx, themeOp.Id, adminSdk.ThemeUpdateRequest{Config: themeOp.Settings}); err != nil {
+					return err
+				}
+			}
+
+			logging.FromContext(cmd.Context()).Infof("Configuration has been applied to remote")
+		} else {
+			logging.FromContext(cmd.Context()).Infof("Configuration is up to date")
+		}
+
+		// The state is also written without changes, so entities removed from the config stay tracked until they are pruned
+		return writeConfigSyncState(plan.statePath, nextConfigSyncState(plan.state, operation, plan.prune))
+	},
+}
+
+func init() {
+	projectConfigCmd.AddCommand(projectConfigApplyCmd)
+	addConfigSyncPlanFlags(projectConfigApplyCmd)
+	projectConfigApplyCmd.Flags().Bool("auto-approve", false, "Skips the confirmation")
+}
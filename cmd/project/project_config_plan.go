@@ -0,0 +1,120 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectConfigPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Shows the differences between the sync section of the project config and the shop",
+	Long: `Fetches the current values of the system config, theme settings, mail templates and synced entities from the shop
+and shows the fields which would be added, changed or removed by project config apply.
+
+The entities created by the config are tracked in a state file per shop URL. With --prune, entities removed from the config are deleted.
+Existing entities of the shop which are matched by the config are updated, but not tracked and never deleted.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		outputAsJson, _ := cmd.Flags().GetBool("json")
+
+		plan, err := buildConfigSyncPlan(cmd)
+		if err != nil {
+			return err
+		}
+
+		if outputAsJson {
+			content, err := json.Marshal(plan.operation.Changes)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(content))
+
+			return nil
+		}
+
+		if len(plan.operation.Changes) == 0 {
+			logging.FromContext(cmd.Context()).Infof("Configuration is up to date")
+
+			return nil
+		}
+
+		return writeConfigSyncPlan(os.Stdout, plan.operation.Changes)
+	},
+}
+
+type configSyncPlan struct {
+	client    *adminSdk.Client
+	apiCtx    adminSdk.ApiContext
+	operation *ConfigSyncOperation
+	state     *configSyncState
+	statePath string
+	prune     bool
+}
+
+// buildConfigSyncPlan compares the sync section of the project config with the shop.
+func buildConfigSyncPlan(cmd *cobra.Command) (*configSyncPlan, error) {
+	prune, _ := cmd.Flags().GetBool("prune")
+	statePath, _ := cmd.Flags().GetString("state")
+
+	cfg, err := shop.ReadConfig(projectConfigPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := shop.NewShopClient(cmd.Context(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if statePath == "" {
+		statePath = defaultConfigSyncStatePath(projectConfigPath, shop.ShopURL(cfg))
+	}
+
+	state, err := readConfigSyncState(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("read state %s: %w", statePath, err)
+	}
+
+	plan := &configSyncPlan{
+		client: client,
+		apiCtx: adminSdk.NewApiContext(cmd.Context()),
+		operation: &ConfigSyncOperation{
+			Operations:     map[string]adminSdk.SyncOperation{},
+			SystemSettings: map[*string]map[string]interface{}{},
+			ThemeSettings:  []ThemeSyncOperation{},
+		},
+		state:     state,
+		statePath: statePath,
+		prune:     prune,
+	}
+
+	for _, applyer := range NewSyncApplyers(cfg) {
+		if err := applyer.Push(plan.apiCtx, client, cfg, plan.operation); err != nil {
+			return nil, err
+		}
+	}
+
+	if prune {
+		addPruneOperations(plan.operation, state)
+	}
+
+	return plan, nil
+}
+
+func addConfigSyncPlanFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("prune", false, "Delete entities which were managed by the config and have been removed from it")
+	cmd.Flags().String("state", "", "Path to the state file of the managed entities, defaults to .shopware-cli/config-sync-state.<host>.json next to the project config")
+}
+
+func init() {
+	projectConfigCmd.AddCommand(projectConfigPlanCmd)
+	addConfigSyncPlanFlags(projectConfigPlanCmd)
+	projectConfigPlanCmd.Flags().Bool("json", false, "Output the changes as json")
+}
@@ -0,0 +1,76 @@
+package project
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectConfigShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows the effective project config",
+	Long: `Prints the project config with all includes merged and the overlay of the environment selected with --env or SHOPWARE_ENV applied.
+Each value is annotated with the file it is coming from.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, sources, err := shop.ReadConfigWithSources(projectConfigPath, shop.SelectedEnvironment())
+		if err != nil {
+			return err
+		}
+
+		// The overlays are already applied
+		cfg.Environments = nil
+		cfg.RedactSecrets()
+
+		var document yaml.Node
+		if err := document.Encode(cfg); err != nil {
+			return err
+		}
+
+		annotateConfigSources(&document, "", sources)
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(&document); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	},
+}
+
+// annotateConfigSources adds the source file of each scalar value as a line comment.
+func annotateConfigSources(node *yaml.Node, path string, sources shop.ConfigSources) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			annotateConfigSources(node.Content[i+1], joinSourcePath(path, node.Content[i].Value), sources)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			annotateConfigSources(item, joinSourcePath(path, strconv.Itoa(i)), sources)
+		}
+	case yaml.ScalarNode:
+		node.LineComment = sources[path]
+	case yaml.DocumentNode, yaml.AliasNode:
+		for _, child := range node.Content {
+			annotateConfigSources(child, path, sources)
+		}
+	}
+}
+
+func joinSourcePath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func init() {
+	projectConfigCmd.AddCommand(projectConfigShowCmd)
+}
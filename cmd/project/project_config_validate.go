@@ -0,0 +1,56 @@
+package project
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/validation"
+	"github.com/shopware/shopware-cli/internal/verifier"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectConfigValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the project config against the JSON schema",
+	Long: `Validates the project config and its includes against the bundled JSON schema.
+Unknown keys like typos, wrong types and deprecated keys are reported with their line and column.
+With --fix, deprecated keys are migrated to their replacement.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		reportingFormat, _ := cmd.Flags().GetString("reporter")
+		fix, _ := cmd.Flags().GetBool("fix")
+
+		if reportingFormat == "" {
+			reportingFormat = validation.DetectDefaultReporter()
+		}
+
+		if fix {
+			fixed, err := shop.FixConfigFile(projectConfigPath)
+			if err != nil {
+				return err
+			}
+
+			for _, file := range fixed {
+				logging.FromContext(cmd.Context()).Infof("Migrated the deprecated keys of %s", file)
+			}
+		}
+
+		results, err := shop.ValidateConfigFile(projectConfigPath)
+		if err != nil {
+			return err
+		}
+
+		check := verifier.NewCheck()
+
+		for _, result := range results {
+			check.AddResult(result)
+		}
+
+		return validation.DoCheckReport(check, reportingFormat)
+	},
+}
+
+func init() {
+	projectConfigCmd.AddCommand(projectConfigValidateCmd)
+	projectConfigValidateCmd.Flags().String("reporter", "", "Reporting format (summary, json, github, junit, markdown)")
+	projectConfigValidateCmd.Flags().Bool("fix", false, "Migrate deprecated keys to their replacement")
+}
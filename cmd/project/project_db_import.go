@@ -0,0 +1,160 @@
+package project
+
+import (
+	"database/sql"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/dbdump"
+	"github.com/shopware/shopware-cli/internal/phpexec"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var projectDatabaseImportCmd = &cobra.Command{
+	Use:   "db-import [file]",
+	Short: "Imports a plain, gzip or zstd compressed SQL dump or a directory dump into the Shopware database",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mysqlConfig, err := assembleConnectionURI(cmd)
+		if err != nil {
+			return err
+		}
+
+		drop, _ := cmd.Flags().GetBool("drop")
+		clearCache, _ := cmd.Flags().GetBool("clear-cache")
+		refreshIndex, _ := cmd.Flags().GetBool("refresh-index")
+		parallel, _ := cmd.Flags().GetInt("parallel")
+
+		if drop {
+			serverConfig := mysqlConfig.Clone()
+			serverConfig.DBName = ""
+
+			server, err := sql.Open("mysql", serverConfig.FormatDSN())
+			if err != nil {
+				return err
+			}
+
+			defer server.Close()
+
+			logging.FromContext(cmd.Context()).Infof("Recreating database %s", mysqlConfig.DBName)
+
+			if err := dbdump.RecreateDatabase(cmd.Context(), server, mysqlConfig.DBName); err != nil {
+				return err
+			}
+		}
+
+		db, err := sql.Open("mysql", mysqlConfig.FormatDSN())
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		logging.FromContext(cmd.Context()).Infof("Importing %s into database %s", args[0], mysqlConfig.DBName)
+
+		start := time.Now()
+
+		if dbdump.IsDirectoryDump(args[0]) {
+			if err := importDirectoryDump(cmd, db, args[0], parallel); err != nil {
+				return err
+			}
+		} else if err := importFileDump(cmd, db, args[0]); err != nil {
+			return err
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Import finished in %s", time.Since(start).Round(time.Second))
+
+		if !clearCache && !refreshIndex {
+			return nil
+		}
+
+		projectRoot, err := findClosestShopwareProject()
+		if err != nil {
+			return fmt.Errorf("running the console commands requires a Shopware project: %w", err)
+		}
+
+		if clearCache {
+			if err := runTransparentCommand(commandWithRoot(phpexec.ConsoleCommand(cmd.Context(), "cache:clear"), projectRoot)); err != nil {
+				return fmt.Errorf("cache:clear: %w", err)
+			}
+		}
+
+		if refreshIndex {
+			if err := runTransparentCommand(commandWithRoot(phpexec.ConsoleCommand(cmd.Context(), "dal:refresh:index"), projectRoot)); err != nil {
+				return fmt.Errorf("dal:refresh:index: %w", err)
+			}
+		}
+
+		return nil
+	},
+}
+
+func importFileDump(cmd *cobra.Command, db *sql.DB, file string) error {
+	dump, err := dbdump.OpenDump(file)
+	if err != nil {
+		return err
+	}
+
+	defer dump.Close()
+
+	progress, err := dbdump.Import(cmd.Context(), db, dump, dbdump.ImportOptions{
+		ProgressInterval: 5 * time.Second,
+		OnProgress: func(progress dbdump.ImportProgress) {
+			logImportProgress(cmd, progress)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	logging.FromContext(cmd.Context()).Infof("Imported %d statements (%s)", progress.Statements, humanize.Bytes(uint64(progress.ReadBytes)))
+
+	return nil
+}
+
+func importDirectoryDump(cmd *cobra.Command, db *sql.DB, dir string, parallel int) error {
+	var imported atomic.Int64
+
+	manifest, err := dbdump.ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	_, err = dbdump.ImportDirectory(cmd.Context(), db, dir, dbdump.DirectoryImportOptions{
+		Parallel: parallel,
+		OnTable: func(table dbdump.ManifestTable) {
+			logging.FromContext(cmd.Context()).Infof("Imported %s with %d rows (%d/%d)", table.Name, table.Rows, imported.Add(1), len(manifest.Tables))
+		},
+	})
+
+	return err
+}
+
+func logImportProgress(cmd *cobra.Command, progress dbdump.ImportProgress) {
+	if percent := progress.Percent(); percent >= 0 {
+		logging.FromContext(cmd.Context()).Infof("Imported %.1f%% (%s of %s, %d statements)", percent, humanize.Bytes(uint64(progress.ReadBytes)), humanize.Bytes(uint64(progress.TotalBytes)), progress.Statements)
+
+		return
+	}
+
+	logging.FromContext(cmd.Context()).Infof("Imported %s (%d statements)", humanize.Bytes(uint64(progress.ReadBytes)), progress.Statements)
+}
+
+func init() {
+	projectRootCmd.AddCommand(projectDatabaseImportCmd)
+	projectDatabaseImportCmd.Flags().String("host", "", "hostname")
+	projectDatabaseImportCmd.Flags().String("database", "", "database name")
+	projectDatabaseImportCmd.Flags().StringP("username", "u", "", "mysql user")
+	projectDatabaseImportCmd.Flags().StringP("password", "p", "", "mysql password")
+	projectDatabaseImportCmd.Flags().String("port", "", "mysql port")
+
+	projectDatabaseImportCmd.Flags().Bool("drop", false, "Drop and recreate the database before importing")
+	projectDatabaseImportCmd.Flags().Bool("clear-cache", false, "Run cache:clear after the import")
+	projectDatabaseImportCmd.Flags().Bool("refresh-index", false, "Run dal:refresh:index after the import")
+	projectDatabaseImportCmd.Flags().Int("parallel", runtime.NumCPU(), "Number of tables imported in parallel from a directory dump")
+}
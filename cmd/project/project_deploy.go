@@ -0,0 +1,430 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/shyim/go-version"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/internal/dbdump"
+	"github.com/shopware/shopware-cli/internal/phpexec"
+	"github.com/shopware/shopware-cli/internal/release"
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const (
+	deploymentTable    = "shopware_cli_deployment"
+	oneTimeTasksTable  = "shopware_cli_one_time_task"
+	deployedVersionKey = "version"
+
+	defaultKeptReleases = 5
+)
+
+var projectDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Installs or updates the Shopware project using the deployment configuration",
+	Long: `Installs Shopware when the database is empty, otherwise updates it.
+Runs the hooks, migrations, the extension management and the one-time tasks of the deployment section of the project config and clears the cache.
+
+A fresh installation uses the environment variables INSTALL_LOCALE, INSTALL_CURRENCY and INSTALL_ADMIN_USERNAME.
+INSTALL_ADMIN_PASSWORD is required for a fresh installation, it is passed to Shopware through stdin and not as a command line argument.
+
+With --release the project is copied into a new directory releases/<timestamp> of the release path, the shared paths are linked into it
+and the current symlink is switched to it after the deployment succeeded. Use --build to run project ci on the new release.`,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		outputAsJson, _ := cmd.Flags().GetBool("json")
+		releaseMode, _ := cmd.Flags().GetBool("release")
+
+		projectRoot, err := findClosestShopwareProject()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := shop.ReadConfig(projectConfigPath, true)
+		if err != nil {
+			return err
+		}
+
+		deployment := cfg.ConfigDeployment
+		if deployment == nil {
+			deployment = &shop.ConfigDeployment{}
+		}
+
+		deployRoot := projectRoot
+
+		var layout release.Layout
+
+		releaseName := release.NewName(time.Now())
+
+		if releaseMode {
+			if layout, err = resolveReleaseLayout(cmd, projectRoot, deployment); err != nil {
+				return err
+			}
+
+			if !dryRun {
+				if deployRoot, err = createRelease(cmd, layout, releaseName, projectRoot, deployment); err != nil {
+					return err
+				}
+			}
+		}
+
+		connection := defaultConnectionConfig()
+		if err := loadDatabaseURLIntoConnection(cmd.Context(), deployRoot, connection); err != nil {
+			return err
+		}
+
+		state, err := loadDeploymentState(cmd.Context(), deployRoot, connection)
+		if err != nil {
+			return err
+		}
+
+		steps, err := buildDeploymentPlan(deployment, *state, deployInstallOptionsFromEnv())
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			if outputAsJson {
+				content, err := json.Marshal(steps)
+				if err != nil {
+					return err
+				}
+
+				fmt.Println(string(content))
+
+				return nil
+			}
+
+			if releaseMode {
+				fmt.Printf("Release %s will be created in %s and activated after the deployment\n", releaseName, layout.ReleasePath(releaseName))
+			}
+
+			return writeDeploymentPlan(steps, state)
+		}
+
+		for i, step := range steps {
+			logging.FromContext(cmd.Context()).Infof("Step %d/%d: %s", i+1, len(steps), step.Description)
+
+			if err := runDeployStep(cmd.Context(), deployRoot, connection, state, step); err != nil {
+				return fmt.Errorf("deployment step %s failed: %w", step.Description, err)
+			}
+		}
+
+		if releaseMode {
+			if err := activateRelease(cmd.Context(), layout, releaseName, deployment); err != nil {
+				return err
+			}
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Deployment of Shopware %s finished", state.CurrentVersion)
+
+		return nil
+	},
+}
+
+// createRelease copies the source into a new release and optionally builds it with project ci.
+func createRelease(cmd *cobra.Command, layout release.Layout, name, projectRoot string, deployment *shop.ConfigDeployment) (string, error) {
+	source, _ := cmd.Flags().GetString("source")
+	build, _ := cmd.Flags().GetBool("build")
+	withDev, _ := cmd.Flags().GetBool("with-dev-dependencies")
+
+	if source == "" {
+		source = projectRoot
+	}
+
+	shared := deployment.Releases.Shared
+	if len(shared) == 0 {
+		shared = release.DefaultSharedPaths
+	}
+
+	logging.FromContext(cmd.Context()).Infof("Creating release %s from %s", name, source)
+
+	releaseDir, err := layout.Create(source, name, shared)
+	if err != nil {
+		return "", err
+	}
+
+	if build {
+		logging.FromContext(cmd.Context()).Infof("Building release %s", name)
+
+		buildDir, err := filepath.Abs(releaseDir)
+		if err != nil {
+			return "", err
+		}
+
+		if err := runProjectCI(cmd.Context(), buildDir, ciOptions{WithDevDependencies: withDev}); err != nil {
+			return "", fmt.Errorf("build release %s: %w", name, err)
+		}
+	}
+
+	return releaseDir, nil
+}
+
+// activateRelease switches the current symlink to the deployed release and removes the oldest releases.
+func activateRelease(ctx context.Context, layout release.Layout, name string, deployment *shop.ConfigDeployment) error {
+	if err := layout.Activate(name); err != nil {
+		return fmt.Errorf("activate release %s: %w", name, err)
+	}
+
+	logging.FromContext(ctx).Infof("Activated release %s", name)
+
+	keep := deployment.Releases.Keep
+	if keep <= 0 {
+		keep = defaultKeptReleases
+	}
+
+	removed, err := layout.Prune(keep)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range removed {
+		logging.FromContext(ctx).Infof("Removed old release %s", name)
+	}
+
+	return nil
+}
+
+func deployInstallOptionsFromEnv() deployInstallOptions {
+	getEnv := func(name, fallback string) string {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+
+		return fallback
+	}
+
+	return deployInstallOptions{
+		Locale:        getEnv("INSTALL_LOCALE", "en-GB"),
+		Currency:      getEnv("INSTALL_CURRENCY", "EUR"),
+		AdminUsername: getEnv("INSTALL_ADMIN_USERNAME", "admin"),
+		AdminPassword: os.Getenv("INSTALL_ADMIN_PASSWORD"),
+	}
+}
+
+func writeDeploymentPlan(steps []deployStep, state *deploymentState) error {
+	if state.Installed && state.PreviousVersion == "" {
+		fmt.Printf("Shopware is installed without a tracked deployment, assuming version %s\n\n", state.CurrentVersion)
+	} else if state.Installed {
+		fmt.Printf("Shopware is installed, updating from %s to %s\n\n", state.PreviousVersion, state.CurrentVersion)
+	} else {
+		fmt.Printf("Shopware is not installed, installing %s\n\n", state.CurrentVersion)
+	}
+
+	t := table.NewWriter(os.Stdout)
+	t.Header([]string{"Step", "Type", "Description"})
+
+	for i, step := range steps {
+		_ = t.Append([]string{fmt.Sprint(i + 1), step.Kind, step.Description})
+	}
+
+	return t.Render()
+}
+
+// loadDeploymentState reads the installation state, the extensions and the executed one-time tasks from the database.
+func loadDeploymentState(ctx context.Context, projectRoot string, connection *mysql.Config) (*deploymentState, error) {
+	state := &deploymentState{
+		CurrentVersion: readProjectShopwareVersion(projectRoot),
+		ExecutedTasks:  map[string]bool{},
+		Dependencies:   loadProjectExtensionDependencies(ctx, projectRoot, nil),
+	}
+
+	// Connect without the database, as it does not exist before the installation
+	serverConnection := connection.Clone()
+	serverConnection.DBName = ""
+
+	server, err := sql.Open("mysql", serverConnection.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	defer server.Close()
+
+	existingTables := map[string]bool{}
+
+	if err := dbdump.QueryRows(ctx, server, "SELECT table_name FROM information_schema.tables WHERE table_schema = ?", func(values []sql.NullString) {
+		existingTables[values[0].String] = true
+	}, connection.DBName); err != nil {
+		return nil, fmt.Errorf("check installation: %w", err)
+	}
+
+	state.Installed = existingTables["system_config"]
+
+	databaseExtensions := map[string]deployExtension{}
+
+	if state.Installed {
+		db, err := sql.Open("mysql", connection.FormatDSN())
+		if err != nil {
+			return nil, err
+		}
+
+		defer db.Close()
+
+		// The tracking tables are created by the first deployment step which needs them, so a dry run does not change the database
+		if existingTables[deploymentTable] {
+			if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT value FROM %s WHERE name = ?", deploymentTable), deployedVersionKey).Scan(&state.PreviousVersion); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("read deployed version: %w", err)
+			}
+		}
+
+		if existingTables[oneTimeTasksTable] {
+			if err := dbdump.QueryRows(ctx, db, fmt.Sprintf("SELECT id FROM %s", oneTimeTasksTable), func(values []sql.NullString) {
+				state.ExecutedTasks[values[0].String] = true
+			}); err != nil {
+				return nil, fmt.Errorf("read one-time tasks: %w", err)
+			}
+		}
+
+		if err := dbdump.QueryRows(ctx, db, "SELECT name, version, upgrade_version, active, installed_at IS NOT NULL FROM plugin", func(values []sql.NullString) {
+			databaseExtensions[values[0].String] = deployExtension{Name: values[0].String, Type: "plugin", Version: values[1].String, UpgradeVersion: values[2].String, Active: values[3].String == "1", Installed: values[4].String == "1"}
+		}); err != nil {
+			return nil, fmt.Errorf("read plugins: %w", err)
+		}
+
+		if err := dbdump.QueryRows(ctx, db, "SELECT name, version, active FROM app", func(values []sql.NullString) {
+			databaseExtensions[values[0].String] = deployExtension{Name: values[0].String, Type: "app", Version: values[1].String, Active: values[2].String == "1", Installed: true}
+		}); err != nil {
+			return nil, fmt.Errorf("read apps: %w", err)
+		}
+	}
+
+	for _, ext := range extension.FindExtensionsFromProject(ctx, projectRoot) {
+		name, err := ext.GetName()
+		if err != nil {
+			continue
+		}
+
+		deployed, ok := databaseExtensions[name]
+		if !ok {
+			deployed = deployExtension{Name: name, Type: "plugin"}
+
+			if ext.GetType() == extension.TypePlatformApp {
+				deployed.Type = "app"
+			}
+		}
+
+		// Compare normalized versions, so different notations of the same version do not trigger an update
+		if v, err := ext.GetVersion(); err == nil {
+			deployed.LocalVersion = v.String()
+
+			if installed, err := version.NewVersion(deployed.Version); err == nil && installed.Equal(v) {
+				deployed.LocalVersion = deployed.Version
+			}
+		}
+
+		state.Extensions = append(state.Extensions, deployed)
+	}
+
+	return state, nil
+}
+
+func ensureDeploymentTables(ctx context.Context, db *sql.DB) error {
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name VARCHAR(255) NOT NULL PRIMARY KEY, value TEXT NOT NULL, updated_at DATETIME NOT NULL)", deploymentTable),
+		fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) NOT NULL PRIMARY KEY, created_at DATETIME NOT NULL)", oneTimeTasksTable),
+	}
+
+	for _, statement := range statements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create deployment tables: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func runDeployStep(ctx context.Context, projectRoot string, connection *mysql.Config, state *deploymentState, step deployStep) error {
+	switch step.Kind {
+	case deployStepConsole:
+		cmd := commandWithRoot(phpexec.ConsoleCommand(ctx, step.Args...), projectRoot)
+
+		if len(step.Env) > 0 {
+			cmd.Env = append(os.Environ(), step.Env...)
+		}
+
+		if step.Stdin != "" {
+			return runDeployCommandWithInput(cmd, step.Stdin)
+		}
+
+		return runDeployCommand(cmd)
+	case deployStepHook:
+		return runDeployCommand(commandWithRoot(exec.CommandContext(ctx, "sh", "-c", step.Script), projectRoot))
+	case deployStepTask:
+		if err := runDeployCommand(commandWithRoot(exec.CommandContext(ctx, "sh", "-c", step.Script), projectRoot)); err != nil {
+			return err
+		}
+
+		return withDeploymentDatabase(ctx, connection, func(db *sql.DB) error {
+			_, err := db.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id, created_at) VALUES (?, NOW())", oneTimeTasksTable), step.TaskID)
+
+			return err
+		})
+	case deployStepRecord:
+		return withDeploymentDatabase(ctx, connection, func(db *sql.DB) error {
+			_, err := db.ExecContext(ctx, fmt.Sprintf("REPLACE INTO %s (name, value, updated_at) VALUES (?, ?, NOW())", deploymentTable), deployedVersionKey, state.CurrentVersion)
+
+			return err
+		})
+	}
+
+	return fmt.Errorf("unknown deployment step %s", step.Kind)
+}
+
+// withDeploymentDatabase opens the project database and makes sure the tracking tables exist, they are missing after a fresh installation.
+func withDeploymentDatabase(ctx context.Context, connection *mysql.Config, fn func(db *sql.DB) error) error {
+	db, err := sql.Open("mysql", connection.FormatDSN())
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	if err := ensureDeploymentTables(ctx, db); err != nil {
+		return err
+	}
+
+	return fn(db)
+}
+
+// runDeployCommand runs the command with the environment of the project, unlike runTransparentCommand which is meant for builds.
+func runDeployCommand(cmd *exec.Cmd) error {
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// runDeployCommandWithInput runs the command like runDeployCommand, but passes the input instead of the terminal to stdin.
+func runDeployCommandWithInput(cmd *exec.Cmd, input string) error {
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func init() {
+	projectRootCmd.AddCommand(projectDeployCmd)
+	projectDeployCmd.Flags().Bool("dry-run", false, "Only print the planned steps without executing them")
+	projectDeployCmd.Flags().Bool("json", false, "Output the planned steps of --dry-run as json")
+	projectDeployCmd.Flags().Bool("release", false, "Deploy into a new release directory and switch the current symlink afterwards")
+	projectDeployCmd.Flags().String("release-path", "", "Directory of the releases, overrides deployment.releases.path of the project config")
+	projectDeployCmd.Flags().String("source", "", "Directory copied into the new release, defaults to the project root")
+	projectDeployCmd.Flags().Bool("build", false, "Run project ci on the new release before deploying it")
+	projectDeployCmd.Flags().Bool("with-dev-dependencies", false, "Install dev dependencies when building the release with --build")
+}
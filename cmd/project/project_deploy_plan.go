@@ -0,0 +1,240 @@
+package project
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const (
+	deployStepConsole = "console"
+	deployStepHook    = "hook"
+	deployStepTask    = "one-time-task"
+	deployStepRecord  = "record-version"
+
+	extensionOverrideIgnore   = "ignore"
+	extensionOverrideInactive = "inactive"
+	extensionOverrideRemove   = "remove"
+)
+
+// deployStep is a single step of a deployment, the steps are executed in order.
+type deployStep struct {
+	Kind        string   `json:"kind"`
+	Description string   `json:"description"`
+	Args        []string `json:"args,omitempty"`
+	Script      string   `json:"-"`
+	TaskID      string   `json:"task_id,omitempty"`
+	// Env and Stdin are passed to the command, they keep secrets like the admin password out of the process arguments
+	Env   []string `json:"-"`
+	Stdin string   `json:"-"`
+}
+
+// deployExtension is a plugin or app of the project with its state in the database.
+type deployExtension struct {
+	Name           string
+	Type           string
+	Installed      bool
+	Active         bool
+	Version        string
+	UpgradeVersion string
+	// LocalVersion is the version of the extension code in the project
+	LocalVersion string
+}
+
+// deploymentState is the state of the shop before the deployment.
+type deploymentState struct {
+	Installed       bool
+	PreviousVersion string
+	CurrentVersion  string
+	Extensions      []deployExtension
+	ExecutedTasks   map[string]bool
+	Dependencies    map[string][]string
+}
+
+// deployInstallOptions are used for a fresh installation.
+type deployInstallOptions struct {
+	Locale        string
+	Currency      string
+	AdminUsername string
+	AdminPassword string
+}
+
+func consoleStep(args ...string) deployStep {
+	return deployStep{Kind: deployStepConsole, Description: "bin/console " + strings.Join(args, " "), Args: args}
+}
+
+func appendHookStep(steps []deployStep, name, script string) []deployStep {
+	if strings.TrimSpace(script) == "" {
+		return steps
+	}
+
+	return append(steps, deployStep{Kind: deployStepHook, Description: fmt.Sprintf("%s hook", name), Script: script})
+}
+
+// buildDeploymentPlan returns the steps to deploy the project based on the deployment configuration and the current state of the shop.
+func buildDeploymentPlan(cfg *shop.ConfigDeployment, state deploymentState, install deployInstallOptions) ([]deployStep, error) {
+	steps := appendHookStep(nil, "pre", cfg.Hooks.Pre)
+	previousVersion := state.PreviousVersion
+
+	if state.Installed {
+		steps = appendHookStep(steps, "pre-update", cfg.Hooks.PreUpdate)
+
+		// Shops installed before their deployments were tracked are assumed to be on the current version
+		if previousVersion == "" {
+			previousVersion = state.CurrentVersion
+			steps = append(steps, deployStep{Kind: deployStepRecord, Description: fmt.Sprintf("record deployed version %s of the untracked installation", state.CurrentVersion)})
+		}
+
+		if previousVersion != state.CurrentVersion {
+			steps = append(steps, consoleStep("system:update:prepare"), consoleStep("system:update:finish"))
+		} else {
+			steps = append(steps, consoleStep("database:migrate", "--all"))
+		}
+	} else {
+		if install.AdminPassword == "" {
+			return nil, fmt.Errorf("the environment variable INSTALL_ADMIN_PASSWORD must be set for a fresh installation")
+		}
+
+		steps = appendHookStep(steps, "pre-install", cfg.Hooks.PreInstall)
+		steps = append(steps,
+			consoleStep("system:install", "--create-database", "--shop-locale="+install.Locale, "--shop-currency="+install.Currency, "--force"),
+			deployStep{
+				Kind:        deployStepConsole,
+				Description: fmt.Sprintf("bin/console user:create %s --admin", install.AdminUsername),
+				Args:        []string{"user:create", install.AdminUsername, "--admin"},
+				// Without --password the command asks for it, SHELL_INTERACTIVE lets Symfony read the answer from the piped stdin
+				Env:   []string{"SHELL_INTERACTIVE=1"},
+				Stdin: install.AdminPassword + "\n",
+			},
+		)
+	}
+
+	if cfg.Store.LicenseDomain != "" {
+		steps = append(steps, consoleStep("system:config:set", "core.store.licenseHost", cfg.Store.LicenseDomain))
+	}
+
+	stepsBeforeChanges := len(steps)
+
+	if cfg.ExtensionManagement.Enabled {
+		extensionSteps, err := buildExtensionDeploySteps(cfg, state)
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, extensionSteps...)
+	}
+
+	for _, task := range cfg.OneTimeTasks {
+		if state.ExecutedTasks[task.Id] {
+			continue
+		}
+
+		steps = append(steps, deployStep{Kind: deployStepTask, Description: fmt.Sprintf("one-time task %s", task.Id), Script: task.Script, TaskID: task.Id})
+	}
+
+	hasChanges := len(steps) > stepsBeforeChanges || !state.Installed || previousVersion != state.CurrentVersion
+
+	if state.Installed {
+		steps = appendHookStep(steps, "post-update", cfg.Hooks.PostUpdate)
+	} else {
+		steps = appendHookStep(steps, "post-install", cfg.Hooks.PostInstall)
+	}
+
+	if cfg.Cache.AlwaysClear || hasChanges {
+		steps = append(steps, consoleStep("cache:clear"))
+	}
+
+	steps = append(steps, deployStep{Kind: deployStepRecord, Description: fmt.Sprintf("record deployed version %s", state.CurrentVersion)})
+
+	return appendHookStep(steps, "post", cfg.Hooks.Post), nil
+}
+
+// buildExtensionDeploySteps installs, activates and updates all extensions of the project, unless they are excluded or overridden.
+// Deactivations and removals run first with dependents before their dependencies, everything else follows with the dependencies first.
+func buildExtensionDeploySteps(cfg *shop.ConfigDeployment, state deploymentState) ([]deployStep, error) {
+	management := cfg.ExtensionManagement
+	byName := map[string]deployExtension{}
+	names := make([]string, 0, len(state.Extensions))
+
+	for _, ext := range state.Extensions {
+		if slices.Contains(management.Exclude, ext.Name) || management.Overrides[ext.Name].State == extensionOverrideIgnore {
+			continue
+		}
+
+		byName[ext.Name] = ext
+		names = append(names, ext.Name)
+	}
+
+	slices.Sort(names)
+
+	order, err := sortByDependencies(names, state.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	forceUpdate := append(slices.Clone(management.ForceUpdate), management.ForceUpdatesDeprecated...)
+	hasPlugins := false
+	disabling := make([][]deployStep, 0)
+	enabling := make([]deployStep, 0)
+
+	for _, name := range order {
+		ext := byName[name]
+		prefix := ext.Type + ":"
+
+		if ext.Type == "plugin" {
+			hasPlugins = true
+		}
+
+		switch management.Overrides[name].State {
+		case extensionOverrideRemove:
+			if ext.Installed {
+				disabling = append(disabling, []deployStep{consoleStep(prefix+"uninstall", name)})
+			}
+		case extensionOverrideInactive:
+			if !ext.Installed {
+				enabling = append(enabling, consoleStep(prefix+"install", name))
+			} else if ext.Active {
+				disabling = append(disabling, []deployStep{consoleStep(prefix+"deactivate", name)})
+			}
+		case "":
+			if !ext.Installed {
+				enabling = append(enabling, consoleStep(prefix+"install", "--activate", name))
+
+				continue
+			}
+
+			// Updates are only handled for plugins
+			needsUpdate := ext.UpgradeVersion != "" && ext.UpgradeVersion != ext.Version
+			needsUpdate = needsUpdate || (ext.LocalVersion != "" && ext.LocalVersion != ext.Version)
+
+			if ext.Type == "plugin" && (needsUpdate || slices.Contains(forceUpdate, name)) {
+				enabling = append(enabling, consoleStep("plugin:update", name))
+			}
+
+			if !ext.Active {
+				enabling = append(enabling, consoleStep(prefix+"activate", name))
+			}
+		default:
+			return nil, fmt.Errorf("unknown state %s of extension %s, expected %s, %s or %s", management.Overrides[name].State, name, extensionOverrideIgnore, extensionOverrideInactive, extensionOverrideRemove)
+		}
+	}
+
+	if len(disabling) == 0 && len(enabling) == 0 {
+		return nil, nil
+	}
+
+	steps := make([]deployStep, 0)
+
+	// Newly added plugins have to be known to Shopware before they can be installed
+	if hasPlugins {
+		steps = append(steps, consoleStep("plugin:refresh"))
+	}
+
+	for i := len(disabling) - 1; i >= 0; i-- {
+		steps = append(steps, disabling[i]...)
+	}
+
+	return append(steps, enabling...), nil
+}
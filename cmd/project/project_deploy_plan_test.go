@@ -0,0 +1,158 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+func stepDescriptions(steps []deployStep) []string {
+	result := make([]string, 0, len(steps))
+
+	for _, step := range steps {
+		result = append(result, step.Description)
+	}
+
+	return result
+}
+
+var testInstallOptions = deployInstallOptions{Locale: "de-DE", Currency: "EUR", AdminUsername: "admin", AdminPassword: "secret"}
+
+func TestBuildDeploymentPlanInstall(t *testing.T) {
+	cfg := &shop.ConfigDeployment{}
+	cfg.Hooks.Pre = "echo pre"
+	cfg.Hooks.PostInstall = "echo post-install"
+	cfg.Hooks.PostUpdate = "echo post-update"
+	cfg.ExtensionManagement.Enabled = true
+	cfg.OneTimeTasks = append(cfg.OneTimeTasks, struct {
+		Id     string `yaml:"id" jsonschema:"required"`
+		Script string `yaml:"script" jsonschema:"required"`
+	}{Id: "import-products", Script: "bin/console import"})
+
+	steps, err := buildDeploymentPlan(cfg, deploymentState{
+		CurrentVersion: "6.6.10.0",
+		Extensions:     []deployExtension{{Name: "SwagPayPal", Type: "plugin"}, {Name: "MyApp", Type: "app"}},
+	}, testInstallOptions)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"pre hook",
+		"bin/console system:install --create-database --shop-locale=de-DE --shop-currency=EUR --force",
+		"bin/console user:create admin --admin",
+		"bin/console plugin:refresh",
+		"bin/console app:install --activate MyApp",
+		"bin/console plugin:install --activate SwagPayPal",
+		"one-time task import-products",
+		"post-install hook",
+		"bin/console cache:clear",
+		"record deployed version 6.6.10.0",
+	}, stepDescriptions(steps))
+
+	// The password is passed through stdin and never part of the arguments
+	assert.NotContains(t, steps[2].Args, "--password=secret")
+	assert.Equal(t, "secret\n", steps[2].Stdin)
+	assert.Contains(t, steps[2].Env, "SHELL_INTERACTIVE=1")
+}
+
+func TestBuildDeploymentPlanInstallRequiresPassword(t *testing.T) {
+	install := testInstallOptions
+	install.AdminPassword = ""
+
+	_, err := buildDeploymentPlan(&shop.ConfigDeployment{}, deploymentState{CurrentVersion: "6.6.10.0"}, install)
+	assert.ErrorContains(t, err, "INSTALL_ADMIN_PASSWORD")
+
+	// Updates do not need the password
+	_, err = buildDeploymentPlan(&shop.ConfigDeployment{}, deploymentState{Installed: true, CurrentVersion: "6.6.10.0"}, install)
+	assert.NoError(t, err)
+}
+
+func TestBuildDeploymentPlanUpdate(t *testing.T) {
+	cfg := &shop.ConfigDeployment{}
+	cfg.Store.LicenseDomain = "shop.example.com"
+	cfg.ExtensionManagement.Enabled = true
+	cfg.ExtensionManagement.Exclude = []string{"Excluded"}
+	cfg.ExtensionManagement.ForceUpdate = []string{"Forced"}
+	cfg.ExtensionManagement.Overrides = shop.ConfigDeploymentOverrides{
+		"Legacy":   {State: "remove"},
+		"Disabled": {State: "inactive"},
+		"Ignored":  {State: "ignore"},
+	}
+	cfg.OneTimeTasks = append(cfg.OneTimeTasks, struct {
+		Id     string `yaml:"id" jsonschema:"required"`
+		Script string `yaml:"script" jsonschema:"required"`
+	}{Id: "already-done", Script: "true"})
+
+	state := deploymentState{
+		Installed:       true,
+		PreviousVersion: "6.6.9.0",
+		CurrentVersion:  "6.6.10.0",
+		ExecutedTasks:   map[string]bool{"already-done": true},
+		Dependencies:    map[string][]string{"Addon": {"Base"}},
+		Extensions: []deployExtension{
+			{Name: "Addon", Type: "plugin"},
+			{Name: "Base", Type: "plugin", Installed: true, Active: true, Version: "1.0.0", LocalVersion: "1.1.0"},
+			{Name: "Forced", Type: "plugin", Installed: true, Active: true, Version: "1.0.0", LocalVersion: "1.0.0"},
+			{Name: "Legacy", Type: "plugin", Installed: true, Active: true},
+			{Name: "Disabled", Type: "plugin", Installed: true, Active: true},
+			{Name: "Ignored", Type: "plugin"},
+			{Name: "Excluded", Type: "plugin"},
+		},
+	}
+
+	steps, err := buildDeploymentPlan(cfg, state, testInstallOptions)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"bin/console system:update:prepare",
+		"bin/console system:update:finish",
+		"bin/console system:config:set core.store.licenseHost shop.example.com",
+		"bin/console plugin:refresh",
+		"bin/console plugin:uninstall Legacy",
+		"bin/console plugin:deactivate Disabled",
+		"bin/console plugin:update Base",
+		"bin/console plugin:install --activate Addon",
+		"bin/console plugin:update Forced",
+		"bin/console cache:clear",
+		"record deployed version 6.6.10.0",
+	}, stepDescriptions(steps))
+}
+
+func TestBuildDeploymentPlanWithoutChanges(t *testing.T) {
+	cfg := &shop.ConfigDeployment{}
+	cfg.ExtensionManagement.Enabled = true
+
+	state := deploymentState{
+		Installed:       true,
+		PreviousVersion: "6.6.10.0",
+		CurrentVersion:  "6.6.10.0",
+		Extensions:      []deployExtension{{Name: "Base", Type: "plugin", Installed: true, Active: true, Version: "1.0.0", LocalVersion: "1.0.0"}},
+	}
+
+	steps, err := buildDeploymentPlan(cfg, state, testInstallOptions)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bin/console database:migrate --all", "record deployed version 6.6.10.0"}, stepDescriptions(steps))
+
+	cfg.Cache.AlwaysClear = true
+	cfg.ExtensionManagement.Overrides = shop.ConfigDeploymentOverrides{"Base": {State: "unknown"}}
+
+	_, err = buildDeploymentPlan(cfg, state, testInstallOptions)
+	assert.ErrorContains(t, err, "unknown state")
+}
+
+func TestBuildDeploymentPlanUntrackedInstallation(t *testing.T) {
+	cfg := &shop.ConfigDeployment{}
+
+	// The shop was installed before its deployments were tracked, so there is no previous version
+	state := deploymentState{Installed: true, CurrentVersion: "6.6.10.0"}
+
+	steps, err := buildDeploymentPlan(cfg, state, testInstallOptions)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"record deployed version 6.6.10.0 of the untracked installation",
+		"bin/console database:migrate --all",
+		"record deployed version 6.6.10.0",
+	}, stepDescriptions(steps))
+}
@@ -0,0 +1,153 @@
+package project
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/dbdump"
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const anonymizationKeyEnv = "SHOPWARE_CLI_ANONYMIZATION_KEY"
+
+var projectDatabaseDumpCheckAnonymizationCmd = &cobra.Command{
+	Use:   "check-anonymization",
+	Short: "Lists columns which look like personal data but are not covered by the anonymization preset",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		mysqlConfig, err := assembleConnectionURI(cmd)
+		if err != nil {
+			return err
+		}
+
+		preset, _ := cmd.Flags().GetString("preset")
+
+		var projectCfg *shop.Config
+		if projectCfg, err = shop.ReadConfig(projectConfigPath, true); err != nil {
+			return err
+		}
+
+		rewrites, err := resolveAnonymizationRewrites(cmd.Context(), projectCfg, preset)
+		if err != nil {
+			return err
+		}
+
+		skipTables := make([]string, 0)
+
+		if projectCfg != nil && projectCfg.ConfigDump != nil {
+			skipTables = append(skipTables, projectCfg.ConfigDump.NoData...)
+			skipTables = append(skipTables, projectCfg.ConfigDump.Ignore...)
+
+			for tableName, columns := range projectCfg.ConfigDump.Rewrite {
+				if rewrites[tableName] == nil {
+					rewrites[tableName] = map[string]string{}
+				}
+
+				for column, rewrite := range columns {
+					rewrites[tableName][column] = rewrite
+				}
+			}
+		}
+
+		db, err := sql.Open("mysql", mysqlConfig.FormatDSN())
+		if err != nil {
+			return err
+		}
+
+		defer db.Close()
+
+		columns, err := dbdump.LoadColumns(cmd.Context(), db, mysqlConfig.DBName)
+		if err != nil {
+			return err
+		}
+
+		uncovered := dbdump.FindUncoveredPIIColumns(columns, rewrites, skipTables)
+
+		if len(uncovered) == 0 {
+			logging.FromContext(cmd.Context()).Infof("All columns looking like personal data are anonymized")
+
+			return nil
+		}
+
+		t := table.NewWriter(os.Stdout)
+		t.Header([]string{"Table", "Column"})
+
+		for _, column := range uncovered {
+			_ = t.Append([]string{column.Table, column.Name})
+		}
+
+		if err := t.Render(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("found %d columns looking like personal data without anonymization rule", len(uncovered))
+	},
+}
+
+// resolveAnonymizationRewrites returns the select rewrites of the preset, falling back to the configured or built-in default preset.
+func resolveAnonymizationRewrites(ctx context.Context, projectCfg *shop.Config, preset string) (map[string]map[string]string, error) {
+	var anonymizationCfg shop.ConfigDumpAnonymization
+
+	if projectCfg != nil && projectCfg.ConfigDump != nil && projectCfg.ConfigDump.Anonymization != nil {
+		anonymizationCfg = *projectCfg.ConfigDump.Anonymization
+	}
+
+	if preset == "" {
+		preset = anonymizationCfg.Preset
+	}
+
+	if preset == "" {
+		preset = dbdump.DefaultAnonymizationPreset
+	}
+
+	customPresets := make(map[string]dbdump.AnonymizationPreset, len(anonymizationCfg.Presets))
+
+	for name, customPreset := range anonymizationCfg.Presets {
+		customPresets[name] = dbdump.AnonymizationPreset{
+			Extends: customPreset.Extends,
+			Rules:   customPreset.Rules,
+		}
+	}
+
+	rules, err := dbdump.ResolveAnonymizationPreset(preset, customPresets)
+	if err != nil {
+		return nil, err
+	}
+
+	key := anonymizationCfg.Key
+	if key == "" {
+		key = os.Getenv(anonymizationKeyEnv)
+	}
+
+	if key == "" {
+		randomKey := make([]byte, 32)
+		if _, err := rand.Read(randomKey); err != nil {
+			return nil, err
+		}
+
+		key = hex.EncodeToString(randomKey)
+
+		logging.FromContext(ctx).Infof("No anonymization key configured, pseudonyms are only stable within this dump. Set dump.anonymization.key or %s to keep them stable across dumps", anonymizationKeyEnv)
+	}
+
+	logging.FromContext(ctx).Infof("Anonymizing with preset %s", preset)
+
+	return dbdump.BuildAnonymizationRewrites(rules, key)
+}
+
+func init() {
+	projectDatabaseDumpCmd.AddCommand(projectDatabaseDumpCheckAnonymizationCmd)
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().String("host", "", "hostname")
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().String("database", "", "database name")
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().StringP("username", "u", "", "mysql user")
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().StringP("password", "p", "", "mysql password")
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().String("port", "", "mysql port")
+	projectDatabaseDumpCheckAnonymizationCmd.Flags().String("preset", "", "Anonymization preset to check, defaults to the configured preset")
+}
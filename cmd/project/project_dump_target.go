@@ -0,0 +1,215 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/dbdump"
+	"github.com/shopware/shopware-cli/internal/packagist"
+	"github.com/shopware/shopware-cli/internal/s3"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const (
+	dumpTargetLocal = "local"
+	dumpTargetS3    = "s3"
+)
+
+// resolveDumpTarget returns the configured target, without configuration the dumps stay in the local output directory.
+func resolveDumpTarget(ctx context.Context, cfg *shop.ConfigDumpTarget, localDir string) (dbdump.Target, error) {
+	if cfg == nil || cfg.Type == "" || cfg.Type == dumpTargetLocal {
+		return dbdump.LocalTarget{Dir: localDir}, nil
+	}
+
+	if cfg.Type != dumpTargetS3 {
+		return nil, fmt.Errorf("unsupported dump target %s, supported are %s and %s", cfg.Type, dumpTargetLocal, dumpTargetS3)
+	}
+
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("dump.target.bucket is required for the s3 target")
+	}
+
+	client := s3.NewClientFromEnv()
+
+	if cfg.Endpoint != "" {
+		client.Endpoint = cfg.Endpoint
+	}
+
+	if cfg.Region != "" {
+		client.Region = cfg.Region
+	}
+
+	if cfg.AccessKeyID != "" {
+		client.AccessKeyID = cfg.AccessKeyID
+	}
+
+	if cfg.SecretAccessKey != "" {
+		secretAccessKey, err := shop.ResolveSecret(ctx, cfg.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("dump.target.secret_access_key: %w", err)
+		}
+
+		client.SecretAccessKey = secretAccessKey
+	}
+
+	if client.AccessKeyID == "" || client.SecretAccessKey == "" {
+		return nil, fmt.Errorf("the s3 dump target requires credentials, set dump.target.access_key_id and dump.target.secret_access_key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+
+	return dbdump.S3Target{Client: client, Bucket: cfg.Bucket, Prefix: cfg.Prefix}, nil
+}
+
+// dumpFiles returns the files of the dump relative to the directory containing it.
+func dumpFiles(output string) ([]string, error) {
+	stat, err := os.Stat(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if !stat.IsDir() {
+		return []string{filepath.Base(output)}, nil
+	}
+
+	files := make([]string, 0)
+	parent := filepath.Dir(output)
+
+	err = filepath.WalkDir(output, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(parent, p)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(rel))
+
+		return nil
+	})
+
+	return files, err
+}
+
+// readShopwareVersion returns the installed shopware/core version of the project, or an empty string when it is unknown.
+func readShopwareVersion() string {
+	projectRoot, err := findClosestShopwareProject()
+	if err != nil {
+		return ""
+	}
+
+	return readProjectShopwareVersion(projectRoot)
+}
+
+// readProjectShopwareVersion returns the version of shopware/core in the composer.lock of the project.
+func readProjectShopwareVersion(projectRoot string) string {
+	lock, err := packagist.ReadComposerLock(filepath.Join(projectRoot, "composer.lock"))
+	if err != nil {
+		return ""
+	}
+
+	if pkg := lock.GetPackage("shopware/core"); pkg != nil {
+		return pkg.Version
+	}
+
+	return ""
+}
+
+type storeDumpOptions struct {
+	Output      string
+	Profile     string
+	Database    string
+	Format      string
+	Compression string
+	CreatedAt   time.Time
+	Target      *shop.ConfigDumpTarget
+	Rotation    dbdump.RotationPolicy
+}
+
+// storeDump writes the sidecar metadata of the dump, moves it into the target and removes expired dumps.
+func storeDump(ctx context.Context, db *sql.DB, options storeDumpOptions) error {
+	localDir := filepath.Dir(options.Output)
+
+	target, err := resolveDumpTarget(ctx, options.Target, localDir)
+	if err != nil {
+		return err
+	}
+
+	files, err := dumpFiles(options.Output)
+	if err != nil {
+		return err
+	}
+
+	extensions, err := dbdump.LoadInstalledExtensions(ctx, db)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Could not add the installed extensions to the dump metadata: %s", err.Error())
+	}
+
+	metadata := dbdump.DumpMetadata{
+		CreatedAt:       options.CreatedAt.UTC(),
+		Profile:         options.Profile,
+		Database:        options.Database,
+		ShopwareVersion: readShopwareVersion(),
+		Extensions:      extensions,
+		Format:          options.Format,
+		Compression:     options.Compression,
+		Files:           files,
+	}
+
+	metadataFile, err := dbdump.WriteMetadata(options.Output, metadata)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := target.Store(ctx, filepath.Join(localDir, filepath.FromSlash(file)), file); err != nil {
+			return err
+		}
+	}
+
+	// The metadata is stored last, so rotation only sees complete dumps
+	if err := target.Store(ctx, metadataFile, filepath.Base(metadataFile)); err != nil {
+		return err
+	}
+
+	if _, isLocal := target.(dbdump.LocalTarget); !isLocal {
+		// Only empty directories are left after uploading a directory dump
+		_ = os.RemoveAll(options.Output)
+
+		logging.FromContext(ctx).Infof("Uploaded the dump to %s", target.Location(filepath.Base(options.Output)))
+	}
+
+	if _, err := dbdump.RotateDumps(ctx, target, metadata, options.Rotation); err != nil {
+		return fmt.Errorf("rotate dumps: %w", err)
+	}
+
+	return nil
+}
+
+// dumpRotationPolicy returns the rotation of the profile, overridden by the --keep-daily and --keep-weekly flags.
+func dumpRotationPolicy(cmd *cobra.Command, cfg *shop.ConfigDumpRotation) dbdump.RotationPolicy {
+	policy := dbdump.RotationPolicy{}
+
+	if cfg != nil {
+		policy.Daily = cfg.Daily
+		policy.Weekly = cfg.Weekly
+	}
+
+	if cmd.Flags().Changed("keep-daily") {
+		policy.Daily, _ = cmd.Flags().GetInt("keep-daily")
+	}
+
+	if cmd.Flags().Changed("keep-weekly") {
+		policy.Weekly, _ = cmd.Flags().GetInt("keep-weekly")
+	}
+
+	return policy
+}
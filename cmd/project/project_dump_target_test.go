@@ -0,0 +1,68 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/internal/dbdump"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+func TestResolveDumpTarget(t *testing.T) {
+	target, err := resolveDumpTarget(t.Context(), nil, "dumps")
+	require.NoError(t, err)
+	assert.Equal(t, dbdump.LocalTarget{Dir: "dumps"}, target)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	t.Setenv("AWS_ENDPOINT_URL", "")
+
+	target, err = resolveDumpTarget(t.Context(), &shop.ConfigDumpTarget{Type: "s3", Bucket: "backups", Prefix: "shop", Endpoint: "http://localhost:9000", SecretAccessKey: "secret"}, "dumps")
+	require.NoError(t, err)
+
+	s3Target, ok := target.(dbdump.S3Target)
+	require.True(t, ok)
+	assert.Equal(t, "env-key", s3Target.Client.AccessKeyID)
+	assert.Equal(t, "secret", s3Target.Client.SecretAccessKey)
+	assert.Equal(t, "http://localhost:9000", s3Target.Client.Endpoint)
+	assert.Equal(t, "s3://backups/shop/dump.sql", target.Location("dump.sql"))
+
+	t.Setenv("SHOPWARE_TEST_S3_SECRET", "from-secret")
+
+	target, err = resolveDumpTarget(t.Context(), &shop.ConfigDumpTarget{Type: "s3", Bucket: "backups", SecretAccessKey: "secret://env/SHOPWARE_TEST_S3_SECRET"}, "dumps")
+	require.NoError(t, err)
+
+	s3Target, ok = target.(dbdump.S3Target)
+	require.True(t, ok)
+	assert.Equal(t, "from-secret", s3Target.Client.SecretAccessKey)
+
+	_, err = resolveDumpTarget(t.Context(), &shop.ConfigDumpTarget{Type: "s3"}, "dumps")
+	assert.ErrorContains(t, err, "bucket")
+
+	_, err = resolveDumpTarget(t.Context(), &shop.ConfigDumpTarget{Type: "ftp"}, "dumps")
+	assert.ErrorContains(t, err, "unsupported")
+}
+
+func TestDumpFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "dump.sql.zst")
+	require.NoError(t, os.WriteFile(file, []byte("dump"), 0o644))
+
+	files, err := dumpFiles(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dump.sql.zst"}, files)
+
+	directory := filepath.Join(dir, "nightly")
+	require.NoError(t, os.MkdirAll(directory, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "manifest.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "product.sql.zst"), []byte("dump"), 0o644))
+
+	files, err = dumpFiles(directory)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly/manifest.json", "nightly/product.sql.zst"}, files)
+}
@@ -0,0 +1,27 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderDumpOutputName(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 5, 7, 0, time.UTC)
+
+	name, err := renderDumpOutputName("dumps/{{.Profile}}-{{.Database}}-{{.Date}}-{{.Time}}.sql", "qa", "shopware", now)
+	require.NoError(t, err)
+	assert.Equal(t, "dumps/qa-shopware-2024-03-09-140507.sql", name)
+
+	_, err = renderDumpOutputName("{{.Unknown}}.sql", "qa", "shopware", now)
+	assert.Error(t, err)
+}
+
+func TestValidateDumpCompression(t *testing.T) {
+	assert.NoError(t, validateDumpCompression(""))
+	assert.NoError(t, validateDumpCompression(CompressionGzip))
+	assert.NoError(t, validateDumpCompression(CompressionZstd))
+	assert.EqualError(t, validateDumpCompression("xz"), "unsupported compression xz, supported are gzip and zstd")
+}
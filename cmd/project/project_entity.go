@@ -0,0 +1,48 @@
+package project
+
+import (
+	"fmt"
+	"net/http"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectEntityCmd = &cobra.Command{
+	Use:   "entity",
+	Short: "Export and import entities using the Admin API",
+}
+
+func newEntityClient(cmd *cobra.Command) (*adminSdk.Client, error) {
+	cfg, err := shop.ReadConfig(projectConfigPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AdminApi == nil {
+		return nil, fmt.Errorf("admin api is not activated in the config")
+	}
+
+	return shop.NewShopClient(cmd.Context(), cfg)
+}
+
+func loadEntitySchema(ctx adminSdk.ApiContext, client *adminSdk.Client) (entitySchema, error) {
+	r, err := client.NewRequest(ctx, http.MethodGet, "/api/_info/entity-schema.json", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema entitySchema
+
+	if _, err := client.Do(ctx.Context, r, &schema); err != nil {
+		return nil, fmt.Errorf("load entity schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+func init() {
+	projectRootCmd.AddCommand(projectEntityCmd)
+}
@@ -0,0 +1,186 @@
+package project
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var projectEntityExportCmd = &cobra.Command{
+	Use:   "export [entity]",
+	Short: "Exports all entities matching the criteria as csv, jsonl or yaml",
+	Long: `Pages through the search API of the entity and writes all matching entities.
+
+The criteria file is a JSON or YAML file with the criteria of the Admin API, like filter, sort or associations.
+With --association-depth all associations of the entity are loaded up to the given depth.
+CSV flattens nested objects into columns joined by a dot and writes lists as JSON.
+Keys of the API responses which are not fields of the entity, like apiAlias or translated, are removed, so the file can be imported again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		criteriaFile, _ := cmd.Flags().GetString("criteria")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		depth, _ := cmd.Flags().GetInt("association-depth")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		entity := args[0]
+
+		if format == "" {
+			format = entityFormatFromFile(output)
+		}
+
+		criteria, err := readEntityCriteria(criteriaFile)
+		if err != nil {
+			return err
+		}
+
+		client, err := newEntityClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		apiCtx := adminSdk.NewApiContext(cmd.Context())
+
+		schema, err := loadEntitySchema(apiCtx, client)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := schema[entity]; !ok {
+			return fmt.Errorf("unknown entity %s", entity)
+		}
+
+		if depth > 0 {
+			addEntityAssociations(criteria, entityAssociations(schema, entity, "", depth))
+		}
+
+		rows, err := searchAllEntities(apiCtx, client, entity, criteria, limit, func(page int, total int) {
+			logging.FromContext(cmd.Context()).Infof("Exported page %d, %d %s entities so far", page, total, entity)
+		})
+		if err != nil {
+			return err
+		}
+
+		// The API adds keys like apiAlias and translated, which cannot be imported again
+		for i, row := range rows {
+			rows[i] = stripEntityRow(schema, entity, row)
+		}
+
+		var w io.Writer = os.Stdout
+
+		if output != "" {
+			f, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+
+			w = f
+		}
+
+		if err := writeEntityRows(w, format, rows); err != nil {
+			return err
+		}
+
+		if output != "" {
+			logging.FromContext(cmd.Context()).Infof("Exported %d %s entities to %s", len(rows), entity, output)
+		}
+
+		return nil
+	},
+}
+
+// readEntityCriteria reads the criteria file, JSON is valid YAML so both formats are supported.
+func readEntityCriteria(file string) (map[string]any, error) {
+	criteria := map[string]any{}
+
+	if file == "" {
+		return criteria, nil
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, &criteria); err != nil {
+		return nil, fmt.Errorf("parse criteria %s: %w", file, err)
+	}
+
+	return criteria, nil
+}
+
+// addEntityAssociations adds the associations to the criteria, associations of the criteria file take precedence.
+func addEntityAssociations(criteria map[string]any, associations map[string]any) {
+	existing, ok := criteria["associations"].(map[string]any)
+	if !ok {
+		existing = map[string]any{}
+	}
+
+	for name, association := range associations {
+		if _, ok := existing[name]; !ok {
+			existing[name] = association
+		}
+	}
+
+	if len(existing) > 0 {
+		criteria["associations"] = existing
+	}
+}
+
+// searchAllEntities pages through the search results, the criteria is sorted by id to get stable pages.
+func searchAllEntities(ctx adminSdk.ApiContext, client *adminSdk.Client, entity string, criteria map[string]any, limit int, progress func(page int, total int)) ([]map[string]any, error) {
+	// The last page is detected by having less entities than the limit
+	if limit <= 0 {
+		return nil, fmt.Errorf("the limit has to be greater than 0, got %d", limit)
+	}
+
+	if _, ok := criteria["sort"]; !ok {
+		criteria["sort"] = []map[string]any{{"field": "id", "order": adminSdk.SearchSortDirectionAscending}}
+	}
+
+	criteria["limit"] = limit
+
+	rows := make([]map[string]any, 0)
+
+	for page := 1; ; page++ {
+		criteria["page"] = page
+
+		r, err := client.NewRequest(ctx, http.MethodPost, fmt.Sprintf("/api/search/%s", entity), criteria)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Data []map[string]any `json:"data"`
+		}
+
+		if _, err := client.Do(ctx.Context, r, &result); err != nil {
+			return nil, fmt.Errorf("search %s page %d: %w", entity, page, err)
+		}
+
+		rows = append(rows, result.Data...)
+		progress(page, len(rows))
+
+		if len(result.Data) < limit {
+			return rows, nil
+		}
+	}
+}
+
+func init() {
+	projectEntityCmd.AddCommand(projectEntityExportCmd)
+	projectEntityExportCmd.Flags().String("criteria", "", "JSON or YAML file with the search criteria")
+	projectEntityExportCmd.Flags().String("format", "", "Output format: csv, jsonl or yaml, detected by the output file extension and defaults to jsonl")
+	projectEntityExportCmd.Flags().StringP("output", "o", "", "File to write to, defaults to stdout")
+	projectEntityExportCmd.Flags().Int("association-depth", 0, "Load all associations up to this depth")
+	projectEntityExportCmd.Flags().Int("limit", 500, "Number of entities fetched per page")
+}
@@ -0,0 +1,388 @@
+package project
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	entityFormatCSV   = "csv"
+	entityFormatJSONL = "jsonl"
+	entityFormatYAML  = "yaml"
+)
+
+// entitySchema is the entity definition of /api/_info/entity-schema.json by entity name.
+type entitySchema map[string]entityDefinition
+
+type entityDefinition struct {
+	Entity     string                    `json:"entity"`
+	Properties map[string]entityProperty `json:"properties"`
+}
+
+type entityProperty struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+	Entity   string `json:"entity"`
+}
+
+// entityFormatFromFile detects the format by the file extension, falling back to jsonl.
+func entityFormatFromFile(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return entityFormatCSV
+	case ".yaml", ".yml":
+		return entityFormatYAML
+	}
+
+	return entityFormatJSONL
+}
+
+func writeEntityRows(w io.Writer, format string, rows []map[string]any) error {
+	switch format {
+	case entityFormatJSONL:
+		encoder := json.NewEncoder(w)
+
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case entityFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(rows); err != nil {
+			return err
+		}
+
+		return encoder.Close()
+	case entityFormatCSV:
+		return writeEntityCSV(w, rows)
+	}
+
+	return fmt.Errorf("unsupported format %s, expected %s, %s or %s", format, entityFormatCSV, entityFormatJSONL, entityFormatYAML)
+}
+
+// writeEntityCSV flattens nested objects into columns joined by a dot, lists are written as JSON.
+func writeEntityCSV(w io.Writer, rows []map[string]any) error {
+	flattened := make([]map[string]string, 0, len(rows))
+	columns := map[string]bool{}
+
+	for _, row := range rows {
+		flat := map[string]string{}
+
+		if err := flattenEntityRow("", row, flat); err != nil {
+			return err
+		}
+
+		for column := range flat {
+			columns[column] = true
+		}
+
+		flattened = append(flattened, flat)
+	}
+
+	header := slices.Sorted(maps.Keys(columns))
+
+	// The id is the most important column, keep it first
+	if index := slices.Index(header, "id"); index > 0 {
+		header = append([]string{"id"}, slices.Delete(header, index, index+1)...)
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, flat := range flattened {
+		record := make([]string, 0, len(header))
+
+		for _, column := range header {
+			record = append(record, flat[column])
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func flattenEntityRow(prefix string, row map[string]any, flat map[string]string) error {
+	for key, value := range row {
+		column := prefix + key
+
+		switch v := value.(type) {
+		case nil:
+		case map[string]any:
+			if err := flattenEntityRow(column+".", v, flat); err != nil {
+				return err
+			}
+		case string:
+			flat[column] = v
+		case bool:
+			flat[column] = strconv.FormatBool(v)
+		case float64:
+			flat[column] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			content, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+
+			flat[column] = string(content)
+		}
+	}
+
+	return nil
+}
+
+// readEntityRows reads the rows of an import file, CSV values are converted to the types of the entity schema.
+func readEntityRows(r io.Reader, format, entity string, schema entitySchema) ([]map[string]any, error) {
+	switch format {
+	case entityFormatJSONL:
+		rows := make([]map[string]any, 0)
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+
+			var row map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+
+			rows = append(rows, row)
+		}
+
+		return rows, scanner.Err()
+	case entityFormatYAML:
+		var rows []map[string]any
+
+		if err := yaml.NewDecoder(r).Decode(&rows); err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		return rows, nil
+	case entityFormatCSV:
+		return readEntityCSV(r, entity, schema)
+	}
+
+	return nil, fmt.Errorf("unsupported format %s, expected %s, %s or %s", format, entityFormatCSV, entityFormatJSONL, entityFormatYAML)
+}
+
+func readEntityCSV(r io.Reader, entity string, schema entitySchema) ([]map[string]any, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]any, 0, len(records)-1)
+
+	for i, record := range records[1:] {
+		row := map[string]any{}
+
+		for index, column := range header {
+			// Empty cells are not written, so they keep the current value of the shop
+			if index >= len(record) || record[index] == "" {
+				continue
+			}
+
+			value, err := convertEntityValue(schema, entity, column, record[index])
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column %s: %w", i+1, column, err)
+			}
+
+			setEntityPath(row, strings.Split(column, "."), value)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func setEntityPath(row map[string]any, path []string, value any) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := row[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			row[key] = child
+		}
+
+		row = child
+	}
+
+	row[path[len(path)-1]] = value
+}
+
+// lookupEntityProperty follows the associations of the dotted column to the property of the field.
+func lookupEntityProperty(schema entitySchema, entity, column string) (entityProperty, bool) {
+	parts := strings.Split(column, ".")
+
+	for i, part := range parts {
+		property, ok := schema[entity].Properties[part]
+		if !ok {
+			return entityProperty{}, false
+		}
+
+		if i == len(parts)-1 || property.Type != "association" {
+			return property, i == len(parts)-1
+		}
+
+		entity = property.Entity
+	}
+
+	return entityProperty{}, false
+}
+
+func convertEntityValue(schema entitySchema, entity, column, value string) (any, error) {
+	property, ok := lookupEntityProperty(schema, entity, column)
+	if !ok {
+		return value, nil
+	}
+
+	switch {
+	case property.Type == "int":
+		return strconv.ParseInt(value, 10, 64)
+	case property.Type == "float":
+		return strconv.ParseFloat(value, 64)
+	case property.Type == "boolean":
+		return strconv.ParseBool(value)
+	case property.Type == "association" || strings.HasPrefix(property.Type, "json"):
+		var decoded any
+		if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+
+		return decoded, nil
+	}
+
+	return value, nil
+}
+
+// stripEntityRow removes the keys which are not fields of the entity, like apiAlias, translated or extensions of the API responses.
+// Associations are stripped with the schema of their entity, so the exported rows can be imported again.
+func stripEntityRow(schema entitySchema, entity string, row map[string]any) map[string]any {
+	stripped := make(map[string]any, len(row))
+
+	for key, value := range row {
+		property, ok := schema[entity].Properties[key]
+		if !ok {
+			continue
+		}
+
+		if property.Type == "association" {
+			value = stripEntityAssociation(schema, property.Entity, value)
+		}
+
+		stripped[key] = value
+	}
+
+	return stripped
+}
+
+func stripEntityAssociation(schema entitySchema, entity string, value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return stripEntityRow(schema, entity, v)
+	case []any:
+		items := make([]any, 0, len(v))
+
+		for _, item := range v {
+			items = append(items, stripEntityAssociation(schema, entity, item))
+		}
+
+		return items
+	}
+
+	return value
+}
+
+// validateEntityRow returns the problems of the row, which can be found without writing it.
+func validateEntityRow(schema entitySchema, entity string, row map[string]any) []string {
+	problems := make([]string, 0)
+
+	definition, ok := schema[entity]
+	if !ok {
+		return []string{fmt.Sprintf("unknown entity %s", entity)}
+	}
+
+	if id, ok := row["id"]; ok {
+		if s, isString := id.(string); !isString || !isEntityID(s) {
+			problems = append(problems, fmt.Sprintf("invalid id %v", id))
+		}
+	}
+
+	for _, field := range slices.Sorted(maps.Keys(row)) {
+		if _, known := definition.Properties[field]; !known {
+			problems = append(problems, fmt.Sprintf("unknown field %s", field))
+		}
+	}
+
+	return problems
+}
+
+func isEntityID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+
+	for _, c := range id {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// entityAssociations returns the associations of the entity up to the depth, associations back to the parent entity are skipped.
+func entityAssociations(schema entitySchema, entity, parent string, depth int) map[string]any {
+	associations := map[string]any{}
+
+	if depth <= 0 {
+		return associations
+	}
+
+	for name, property := range schema[entity].Properties {
+		if property.Type != "association" || property.Entity == parent {
+			continue
+		}
+
+		criteria := map[string]any{}
+
+		if nested := entityAssociations(schema, property.Entity, entity, depth-1); len(nested) > 0 {
+			criteria["associations"] = nested
+		}
+
+		associations[name] = criteria
+	}
+
+	return associations
+}
@@ -0,0 +1,252 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var projectEntityImportCmd = &cobra.Command{
+	Use:   "import [entity] [file]",
+	Short: "Imports entities from a csv, jsonl or yaml file",
+	Long: `Upserts the rows of the file in batches using the sync API. Rows with an id update the existing entity.
+
+Failed batches are retried, when they still fail each row is written on its own to find the failing rows.
+The failing rows are printed and can be written with --report as JSON. Use --dry-run to only validate the rows against the entity schema.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		batchSize, _ := cmd.Flags().GetInt("batch-size")
+		retries, _ := cmd.Flags().GetInt("retries")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		report, _ := cmd.Flags().GetString("report")
+
+		entity, file := args[0], args[1]
+
+		if format == "" {
+			format = entityFormatFromFile(file)
+		}
+
+		client, err := newEntityClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		apiCtx := adminSdk.NewApiContext(cmd.Context())
+
+		schema, err := loadEntitySchema(apiCtx, client)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		rows, err := readEntityRows(f, format, entity, schema)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+
+		var failed []entityImportError
+
+		if dryRun {
+			for i, row := range rows {
+				for _, problem := range validateEntityRow(schema, entity, row) {
+					failed = append(failed, newEntityImportError(i, row, problem))
+				}
+			}
+		} else {
+			importer := entityImporter{
+				BatchSize: batchSize,
+				Retries:   retries,
+				Backoff:   time.Second,
+				Sync: func(ctx context.Context, batch []map[string]any) error {
+					syncCtx := apiCtx
+					syncCtx.Context = ctx
+
+					return syncEntities(syncCtx, client, entity, batch)
+				},
+				Progress: func(done int) {
+					logging.FromContext(cmd.Context()).Infof("Imported %d/%d rows", done, len(rows))
+				},
+			}
+
+			if failed, err = importer.Import(cmd.Context(), rows); err != nil {
+				return err
+			}
+		}
+
+		if report != "" {
+			content, err := json.MarshalIndent(failed, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			if err := os.WriteFile(report, content, 0o644); err != nil {
+				return err
+			}
+		}
+
+		if len(failed) == 0 {
+			if dryRun {
+				logging.FromContext(cmd.Context()).Infof("All %d rows are valid", len(rows))
+			} else {
+				logging.FromContext(cmd.Context()).Infof("Imported %d %s entities", len(rows), entity)
+			}
+
+			return nil
+		}
+
+		t := table.NewWriter(os.Stdout)
+		t.Header([]string{"Row", "ID", "Error"})
+
+		for _, e := range failed {
+			_ = t.Append([]string{fmt.Sprint(e.Row), e.ID, e.Error})
+		}
+
+		if err := t.Render(); err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%d of %d rows failed", countFailedRows(failed), len(rows))
+	},
+}
+
+// entityImportError is the report entry of a failed row, the row number starts at 1.
+type entityImportError struct {
+	Row   int    `json:"row"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error"`
+}
+
+func newEntityImportError(index int, row map[string]any, message string) entityImportError {
+	id, _ := row["id"].(string)
+
+	return entityImportError{Row: index + 1, ID: id, Error: message}
+}
+
+func countFailedRows(failed []entityImportError) int {
+	rows := map[int]bool{}
+
+	for _, e := range failed {
+		rows[e.Row] = true
+	}
+
+	return len(rows)
+}
+
+// entityImporter writes the rows in batches with retries for temporary failures.
+type entityImporter struct {
+	BatchSize int
+	Retries   int
+	Backoff   time.Duration
+	Sync      func(ctx context.Context, rows []map[string]any) error
+	Progress  func(done int)
+}
+
+// Import writes all rows and returns the rows which could not be written.
+// A batch failing permanently is split into single rows, so only the invalid rows are reported.
+func (i entityImporter) Import(ctx context.Context, rows []map[string]any) ([]entityImportError, error) {
+	failed := make([]entityImportError, 0)
+	batchSize := max(i.BatchSize, 1)
+
+	for start := 0; start < len(rows); start += batchSize {
+		end := min(start+batchSize, len(rows))
+
+		err := i.syncWithRetry(ctx, rows[start:end])
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if err != nil && end-start == 1 {
+			failed = append(failed, newEntityImportError(start, rows[start], err.Error()))
+		} else if err != nil {
+			for index := start; index < end; index++ {
+				if err := i.syncWithRetry(ctx, rows[index:index+1]); err != nil {
+					if ctx.Err() != nil {
+						return nil, ctx.Err()
+					}
+
+					failed = append(failed, newEntityImportError(index, rows[index], err.Error()))
+				}
+			}
+		}
+
+		if i.Progress != nil {
+			i.Progress(end)
+		}
+	}
+
+	return failed, nil
+}
+
+func (i entityImporter) syncWithRetry(ctx context.Context, rows []map[string]any) error {
+	var err error
+
+	for attempt := 0; attempt <= i.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(i.Backoff * time.Duration(attempt)):
+			}
+		}
+
+		if err = i.Sync(ctx, rows); err == nil || !isTemporaryAPIError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// isTemporaryAPIError reports whether retrying can succeed, validation errors of the API fail again.
+func isTemporaryAPIError(err error) bool {
+	var apiErr *adminSdk.ErrorResponse
+	if errors.As(err, &apiErr) {
+		return apiErr.Response.StatusCode >= http.StatusInternalServerError || apiErr.Response.StatusCode == http.StatusTooManyRequests
+	}
+
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func syncEntities(ctx adminSdk.ApiContext, client *adminSdk.Client, entity string, rows []map[string]any) error {
+	payload := map[string]adminSdk.SyncOperation{
+		"entity-import": {Entity: entity, Action: "upsert", Payload: rows},
+	}
+
+	r, err := client.NewRequest(ctx, http.MethodPost, "/api/_action/sync", payload)
+	if err != nil {
+		return err
+	}
+
+	// Large imports should not block the request until all indexers ran
+	r.Header.Set("indexing-behavior", "use-queue-indexing")
+
+	_, err = client.Do(ctx.Context, r, nil)
+
+	return err
+}
+
+func init() {
+	projectEntityCmd.AddCommand(projectEntityImportCmd)
+	projectEntityImportCmd.Flags().String("format", "", "Input format: csv, jsonl or yaml, detected by the file extension")
+	projectEntityImportCmd.Flags().Int("batch-size", 100, "Number of rows written per sync request")
+	projectEntityImportCmd.Flags().Int("retries", 3, "How often a batch is retried on server errors")
+	projectEntityImportCmd.Flags().Bool("dry-run", false, "Only validate the rows against the entity schema")
+	projectEntityImportCmd.Flags().String("report", "", "Write the failed rows as JSON to this file")
+}
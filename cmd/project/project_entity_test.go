@@ -0,0 +1,194 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntitySchema = entitySchema{
+	"product": {Entity: "product", Properties: map[string]entityProperty{
+		"id":            {Type: "uuid"},
+		"productNumber": {Type: "string"},
+		"stock":         {Type: "int"},
+		"active":        {Type: "boolean"},
+		"price":         {Type: "json_object"},
+		"manufacturer":  {Type: "association", Relation: "many_to_one", Entity: "product_manufacturer"},
+		"categories":    {Type: "association", Relation: "many_to_many", Entity: "category"},
+	}},
+	"product_manufacturer": {Entity: "product_manufacturer", Properties: map[string]entityProperty{
+		"name":     {Type: "string"},
+		"products": {Type: "association", Relation: "one_to_many", Entity: "product"},
+		"media":    {Type: "association", Relation: "many_to_one", Entity: "media"},
+	}},
+	"category": {Entity: "category", Properties: map[string]entityProperty{
+		"id": {Type: "uuid"},
+	}},
+}
+
+func TestEntityCSVRoundTrip(t *testing.T) {
+	rows := []map[string]any{
+		{
+			"id":            "0190a2d4b0f57c2fa3b2d9c4e5f60718",
+			"productNumber": "10001",
+			"stock":         float64(10),
+			"active":        true,
+			"price":         []any{map[string]any{"gross": float64(19.99)}},
+			"manufacturer":  map[string]any{"name": "shopware AG"},
+			"categories":    []any{map[string]any{"id": "0190a2d4b0f57c2fa3b2d9c4e5f60719"}},
+		},
+		{"productNumber": "10002", "manufacturer": nil},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeEntityRows(&buf, entityFormatCSV, rows))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "id,active,categories,manufacturer.name,price,productNumber,stock", lines[0])
+
+	read, err := readEntityRows(&buf, entityFormatCSV, "product", testEntitySchema)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"id":            "0190a2d4b0f57c2fa3b2d9c4e5f60718",
+		"productNumber": "10001",
+		"stock":         int64(10),
+		"active":        true,
+		"price":         []any{map[string]any{"gross": 19.99}},
+		"manufacturer":  map[string]any{"name": "shopware AG"},
+		"categories":    []any{map[string]any{"id": "0190a2d4b0f57c2fa3b2d9c4e5f60719"}},
+	}, read[0])
+
+	// Empty cells are not written
+	assert.Equal(t, map[string]any{"productNumber": "10002"}, read[1])
+
+	_, err = readEntityRows(strings.NewReader("stock\nmany\n"), entityFormatCSV, "product", testEntitySchema)
+	assert.ErrorContains(t, err, "row 1, column stock")
+}
+
+func TestEntityJSONLAndYAML(t *testing.T) {
+	rows := []map[string]any{{"productNumber": "10001"}, {"productNumber": "10002"}}
+
+	for _, format := range []string{entityFormatJSONL, entityFormatYAML} {
+		var buf bytes.Buffer
+		require.NoError(t, writeEntityRows(&buf, format, rows))
+
+		read, err := readEntityRows(&buf, format, "product", testEntitySchema)
+		require.NoError(t, err)
+		assert.Equal(t, rows, read, format)
+	}
+
+	assert.Error(t, writeEntityRows(&bytes.Buffer{}, "xml", rows))
+	assert.Equal(t, entityFormatYAML, entityFormatFromFile("products.yml"))
+	assert.Equal(t, entityFormatCSV, entityFormatFromFile("products.CSV"))
+	assert.Equal(t, entityFormatJSONL, entityFormatFromFile(""))
+}
+
+func TestValidateEntityRow(t *testing.T) {
+	assert.Empty(t, validateEntityRow(testEntitySchema, "product", map[string]any{"id": "0190a2d4b0f57c2fa3b2d9c4e5f60718", "stock": 1}))
+
+	assert.Equal(t, []string{"invalid id 42", "unknown field name", "unknown field stok"}, validateEntityRow(testEntitySchema, "product", map[string]any{"id": 42, "stok": 1, "name": "x"}))
+	assert.Equal(t, []string{"unknown entity foo"}, validateEntityRow(testEntitySchema, "foo", map[string]any{}))
+}
+
+func TestEntityAssociations(t *testing.T) {
+	assert.Empty(t, entityAssociations(testEntitySchema, "product", "", 0))
+
+	assert.Equal(t, map[string]any{
+		"manufacturer": map[string]any{"associations": map[string]any{"media": map[string]any{}}},
+		"categories":   map[string]any{},
+	}, entityAssociations(testEntitySchema, "product", "", 2))
+
+	criteria := map[string]any{"associations": map[string]any{"manufacturer": map[string]any{"limit": 1}}}
+	addEntityAssociations(criteria, entityAssociations(testEntitySchema, "product", "", 1))
+
+	assert.Equal(t, map[string]any{"manufacturer": map[string]any{"limit": 1}, "categories": map[string]any{}}, criteria["associations"])
+}
+
+func TestEntityImporterSplitsFailedBatches(t *testing.T) {
+	rows := []map[string]any{{"id": "a"}, {"id": "invalid"}, {"id": "c"}, {"id": "d"}}
+	calls := 0
+	temporaryFailures := 1
+
+	importer := entityImporter{
+		BatchSize: 2,
+		Retries:   2,
+		Sync: func(_ context.Context, batch []map[string]any) error {
+			calls++
+
+			if batch[0]["id"] == "c" && temporaryFailures > 0 {
+				temporaryFailures--
+
+				return &adminSdk.ErrorResponse{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+			}
+
+			for _, row := range batch {
+				if row["id"] == "invalid" {
+					return &adminSdk.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadRequest}, Content: "invalid"}
+				}
+			}
+
+			return nil
+		},
+	}
+
+	failed, err := importer.Import(t.Context(), rows)
+	require.NoError(t, err)
+	assert.Equal(t, []entityImportError{{Row: 2, ID: "invalid", Error: "API request failed, got http code 400 with content: invalid"}}, failed)
+
+	// First batch, both single rows, the retried second batch
+	assert.Equal(t, 5, calls)
+}
+
+func TestIsTemporaryAPIError(t *testing.T) {
+	assert.True(t, isTemporaryAPIError(errors.New("connection reset")))
+	assert.True(t, isTemporaryAPIError(&adminSdk.ErrorResponse{Response: &http.Response{StatusCode: http.StatusTooManyRequests}}))
+	assert.False(t, isTemporaryAPIError(&adminSdk.ErrorResponse{Response: &http.Response{StatusCode: http.StatusBadRequest}}))
+	assert.False(t, isTemporaryAPIError(context.Canceled))
+}
+
+func TestEntityExportImportRoundTrip(t *testing.T) {
+	// A product as returned by the search API
+	apiRow := map[string]any{
+		"id":                "0190a2d4b0f57c2fa3b2d9c4e5f60718",
+		"productNumber":     "10001",
+		"stock":             float64(10),
+		"apiAlias":          "product",
+		"_uniqueIdentifier": "0190a2d4b0f57c2fa3b2d9c4e5f60718",
+		"translated":        map[string]any{"name": "Shirt"},
+		"extensions":        map[string]any{"foreignKeys": map[string]any{"apiAlias": "product_foreign_keys_extension"}},
+		"manufacturer":      map[string]any{"name": "shopware AG", "apiAlias": "product_manufacturer", "translated": map[string]any{"name": "shopware AG"}},
+		"categories":        []any{map[string]any{"id": "0190a2d4b0f57c2fa3b2d9c4e5f60719", "apiAlias": "category"}},
+	}
+
+	stripped := stripEntityRow(testEntitySchema, "product", apiRow)
+	assert.Equal(t, map[string]any{
+		"id":            "0190a2d4b0f57c2fa3b2d9c4e5f60718",
+		"productNumber": "10001",
+		"stock":         float64(10),
+		"manufacturer":  map[string]any{"name": "shopware AG"},
+		"categories":    []any{map[string]any{"id": "0190a2d4b0f57c2fa3b2d9c4e5f60719"}},
+	}, stripped)
+
+	for _, format := range []string{entityFormatCSV, entityFormatJSONL, entityFormatYAML} {
+		var buf bytes.Buffer
+		require.NoError(t, writeEntityRows(&buf, format, []map[string]any{stripped}))
+
+		read, err := readEntityRows(&buf, format, "product", testEntitySchema)
+		require.NoError(t, err, format)
+		require.Len(t, read, 1, format)
+		assert.Empty(t, validateEntityRow(testEntitySchema, "product", read[0]), format)
+	}
+}
+
+func TestSearchAllEntitiesRejectsInvalidLimit(t *testing.T) {
+	_, err := searchAllEntities(adminSdk.NewApiContext(t.Context()), nil, "product", map[string]any{}, 0, func(int, int) {})
+	assert.ErrorContains(t, err, "greater than 0")
+}
@@ -0,0 +1,41 @@
+package project
+
+import (
+	"fmt"
+	"os"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+var projectExtensionApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Install, update, activate and deactivate extensions to reach the extension state of the project config",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		client, changes, err := planProjectExtensions(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := writeExtensionChanges(os.Stdout, changes); err != nil {
+			return err
+		}
+
+		for i, change := range changes {
+			if err := applyExtensionChange(adminSdk.NewApiContext(cmd.Context()), client, change); err != nil {
+				// Later steps can depend on this one, so stop here
+				return fmt.Errorf("step %d, %s of %s failed: %w", i+1, change.Action, change.Name, err)
+			}
+
+			logging.FromContext(cmd.Context()).Infof("Step %d/%d: %s %s", i+1, len(changes), change.Action, change.Name)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	projectExtensionCmd.AddCommand(projectExtensionApplyCmd)
+}
@@ -0,0 +1,106 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectExtensionPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show the changes needed to reach the extension state of the project config",
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		outputAsJson, _ := cmd.Flags().GetBool("json")
+		failOnChanges, _ := cmd.Flags().GetBool("fail-on-changes")
+
+		_, changes, err := planProjectExtensions(cmd)
+		if err != nil {
+			return err
+		}
+
+		if outputAsJson {
+			content, err := json.Marshal(changes)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(string(content))
+		} else if err := writeExtensionChanges(os.Stdout, changes); err != nil {
+			return err
+		}
+
+		if failOnChanges && len(changes) > 0 {
+			return fmt.Errorf("the shop differs from the extension state, %d changes planned", len(changes))
+		}
+
+		return nil
+	},
+}
+
+// planProjectExtensions compares the extension state of the project config with the live shop.
+func planProjectExtensions(cmd *cobra.Command) (*adminSdk.Client, []extensionChange, error) {
+	cfg, err := shop.ReadConfig(projectConfigPath, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Extensions == nil || len(cfg.Extensions.State) == 0 {
+		return nil, nil, fmt.Errorf("no extension state configured, add extensions.state to %s", projectConfigPath)
+	}
+
+	client, err := shop.NewShopClient(cmd.Context(), cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := client.ExtensionManager.Refresh(adminSdk.NewApiContext(cmd.Context())); err != nil {
+		return nil, nil, err
+	}
+
+	extensions, _, err := client.ExtensionManager.ListAvailableExtensions(adminSdk.NewApiContext(cmd.Context()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changes, err := planExtensionChanges(cfg.Extensions.State, extensions, loadExtensionDependencies(cmd.Context(), cfg.Extensions.State))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client, changes, nil
+}
+
+func writeExtensionChanges(out io.Writer, changes []extensionChange) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(out, "The extensions are up to date, no changes planned")
+
+		return err
+	}
+
+	t := table.NewWriter(out)
+	t.Header([]string{"Step", "Extension", "Type", "Action", "Version"})
+
+	for i, change := range changes {
+		versions := ""
+		if change.To != "" {
+			versions = fmt.Sprintf("%s -> %s", change.From, change.To)
+		}
+
+		_ = t.Append([]string{fmt.Sprint(i + 1), change.Name, change.Type, change.Action, versions})
+	}
+
+	return t.Render()
+}
+
+func init() {
+	projectExtensionCmd.AddCommand(projectExtensionPlanCmd)
+	projectExtensionPlanCmd.Flags().Bool("json", false, "Output as json")
+	projectExtensionPlanCmd.Flags().Bool("fail-on-changes", false, "Exit with an error when changes are planned")
+}
@@ -0,0 +1,263 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const (
+	extensionActionDownload   = "download"
+	extensionActionInstall    = "install"
+	extensionActionUpdate     = "update"
+	extensionActionActivate   = "activate"
+	extensionActionDeactivate = "deactivate"
+	extensionActionUninstall  = "uninstall"
+)
+
+// extensionChange is a single lifecycle step to reach the desired state of an extension.
+type extensionChange struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// planExtensionChanges compares the desired state with the live extensions of the shop.
+// Deactivations and uninstalls come first and dependents before their dependencies, all other steps follow with the dependencies first.
+func planExtensionChanges(desired map[string]shop.ConfigExtensionState, live adminSdk.ExtensionList, dependencies map[string][]string) ([]extensionChange, error) {
+	order, err := sortByDependencies(slices.Sorted(maps.Keys(desired)), dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Disabling steps are grouped by extension, so the groups can be reversed
+	disabling := make([][]extensionChange, 0)
+	enabling := make([]extensionChange, 0)
+
+	for _, name := range order {
+		state := desired[name]
+		current := live.GetByName(name)
+
+		if current == nil {
+			return nil, fmt.Errorf("extension %s is not available in the shop", name)
+		}
+
+		isInstalled := current.InstalledAt != nil
+		wantInstalled := isInstalled
+		wantActive := current.Active
+
+		if state.Installed != nil {
+			wantInstalled = *state.Installed
+		}
+
+		if state.Active != nil {
+			wantActive = *state.Active
+
+			if wantActive && state.Installed == nil {
+				wantInstalled = true
+			}
+		}
+
+		if !wantInstalled {
+			if wantActive && state.Active != nil {
+				return nil, fmt.Errorf("extension %s cannot be active without being installed", name)
+			}
+
+			wantActive = false
+		}
+
+		change := func(action string) extensionChange {
+			return extensionChange{Name: name, Type: current.Type, Action: action}
+		}
+
+		disable := make([]extensionChange, 0)
+
+		if current.Active && !wantActive {
+			disable = append(disable, change(extensionActionDeactivate))
+		}
+
+		if isInstalled && !wantInstalled {
+			disable = append(disable, change(extensionActionUninstall))
+		}
+
+		disabling = append(disabling, disable)
+
+		if !wantInstalled {
+			continue
+		}
+
+		needsUpdate := state.Version != "" && state.Version != current.Version
+
+		if needsUpdate && state.Version != current.LatestVersion {
+			return nil, fmt.Errorf("version %s of extension %s is not available, the current version is %s and the latest version is %s", state.Version, name, current.Version, current.LatestVersion)
+		}
+
+		// Store extensions have to be downloaded before they can be installed or updated
+		if (!isInstalled && current.Source == "store") || (needsUpdate && current.UpdateSource == "store") {
+			enabling = append(enabling, change(extensionActionDownload))
+		}
+
+		if !isInstalled {
+			enabling = append(enabling, change(extensionActionInstall))
+		}
+
+		if needsUpdate {
+			update := change(extensionActionUpdate)
+			update.From = current.Version
+			update.To = state.Version
+
+			enabling = append(enabling, update)
+		}
+
+		if wantActive && !current.Active {
+			enabling = append(enabling, change(extensionActionActivate))
+		}
+	}
+
+	changes := make([]extensionChange, 0, len(enabling))
+
+	for i := len(disabling) - 1; i >= 0; i-- {
+		changes = append(changes, disabling[i]...)
+	}
+
+	return append(changes, enabling...), nil
+}
+
+// sortByDependencies orders the names so every extension comes after its dependencies.
+// Dependencies outside of names are ignored, as they are not managed.
+func sortByDependencies(names []string, dependencies map[string][]string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+
+	managed := map[string]bool{}
+	for _, name := range names {
+		managed[name] = true
+	}
+
+	state := map[string]int{}
+	order := make([]string, 0, len(names))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("extensions have a circular dependency: %v", append(path, name))
+		}
+
+		state[name] = visiting
+
+		deps := slices.Clone(dependencies[name])
+		slices.Sort(deps)
+
+		for _, dep := range deps {
+			if managed[dep] {
+				if err := visit(dep, append(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[name] = done
+		order = append(order, name)
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// loadExtensionDependencies returns the dependencies of the configured state and the composer requirements between the project plugins.
+func loadExtensionDependencies(ctx context.Context, desired map[string]shop.ConfigExtensionState) map[string][]string {
+	projectRoot, err := findClosestShopwareProject()
+	if err != nil {
+		projectRoot = ""
+	}
+
+	return loadProjectExtensionDependencies(ctx, projectRoot, desired)
+}
+
+// loadProjectExtensionDependencies returns the dependencies of the extensions in the project root, an empty root only returns the configured dependencies.
+func loadProjectExtensionDependencies(ctx context.Context, projectRoot string, desired map[string]shop.ConfigExtensionState) map[string][]string {
+	dependencies := map[string][]string{}
+
+	for name, state := range desired {
+		dependencies[name] = append(dependencies[name], state.DependsOn...)
+	}
+
+	if projectRoot == "" {
+		return dependencies
+	}
+
+	byComposerName := map[string]string{}
+	plugins := map[string]*extension.PlatformPlugin{}
+
+	for _, ext := range extension.FindExtensionsFromProject(ctx, projectRoot) {
+		plugin, ok := ext.(*extension.PlatformPlugin)
+		if !ok {
+			continue
+		}
+
+		name, err := plugin.GetName()
+		if err != nil {
+			continue
+		}
+
+		byComposerName[plugin.Composer.Name] = name
+		plugins[name] = plugin
+	}
+
+	for name, plugin := range plugins {
+		for requirement := range plugin.Composer.Require {
+			if dependency, ok := byComposerName[requirement]; ok && dependency != name {
+				dependencies[name] = append(dependencies[name], dependency)
+			}
+		}
+	}
+
+	logging.FromContext(ctx).Debugf("Resolved extension dependencies: %v", dependencies)
+
+	return dependencies
+}
+
+// applyExtensionChange executes a single change with the extension manager of the shop.
+func applyExtensionChange(ctx adminSdk.ApiContext, client *adminSdk.Client, change extensionChange) error {
+	var err error
+
+	switch change.Action {
+	case extensionActionDownload:
+		_, err = client.ExtensionManager.DownloadExtension(ctx, change.Name)
+	case extensionActionInstall:
+		_, err = client.ExtensionManager.InstallExtension(ctx, change.Type, change.Name)
+	case extensionActionUpdate:
+		_, err = client.ExtensionManager.UpdateExtension(ctx, change.Type, change.Name)
+	case extensionActionActivate:
+		_, err = client.ExtensionManager.ActivateExtension(ctx, change.Type, change.Name)
+	case extensionActionDeactivate:
+		_, err = client.ExtensionManager.DeactivateExtension(ctx, change.Type, change.Name)
+	case extensionActionUninstall:
+		_, err = client.ExtensionManager.UninstallExtension(ctx, change.Type, change.Name)
+	default:
+		err = fmt.Errorf("unknown action %s", change.Action)
+	}
+
+	return err
+}
@@ -0,0 +1,137 @@
+package project
+
+import (
+	"bytes"
+	"testing"
+
+	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+func liveExtension(name string, installed, active bool, version, latest string) *adminSdk.ExtensionDetail {
+	ext := &adminSdk.ExtensionDetail{Name: name, Type: "plugin", Active: active, Version: version, LatestVersion: latest, Source: "local"}
+
+	if installed {
+		ext.InstalledAt = &struct {
+			Date         string `json:"date"`
+			TimezoneType int    `json:"timezone_type"`
+			Timezone     string `json:"timezone"`
+		}{Date: "2024-01-01 00:00:00.000000"}
+	}
+
+	return ext
+}
+
+func actions(changes []extensionChange) []string {
+	result := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		result = append(result, change.Action+" "+change.Name)
+	}
+
+	return result
+}
+
+func TestPlanExtensionChanges(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	live := adminSdk.ExtensionList{
+		liveExtension("SwagBase", false, false, "1.0.0", ""),
+		liveExtension("SwagAddon", false, false, "2.0.0", ""),
+		liveExtension("SwagPayPal", true, true, "9.0.0", "9.1.0"),
+		liveExtension("SwagLegacy", true, true, "1.0.0", ""),
+		liveExtension("SwagLegacyAddon", true, true, "1.0.0", ""),
+		liveExtension("Untouched", true, true, "1.0.0", ""),
+	}
+
+	live[0].Source = "store"
+
+	desired := map[string]shop.ConfigExtensionState{
+		"SwagAddon":       {Active: &enabled},
+		"SwagBase":        {Active: &enabled},
+		"SwagPayPal":      {Version: "9.1.0"},
+		"SwagLegacy":      {Installed: &disabled},
+		"SwagLegacyAddon": {Active: &disabled},
+	}
+
+	dependencies := map[string][]string{
+		"SwagAddon":       {"SwagBase", "NotManaged"},
+		"SwagLegacyAddon": {"SwagLegacy"},
+	}
+
+	changes, err := planExtensionChanges(desired, live, dependencies)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"deactivate SwagLegacyAddon",
+		"deactivate SwagLegacy",
+		"uninstall SwagLegacy",
+		"download SwagBase",
+		"install SwagBase",
+		"activate SwagBase",
+		"install SwagAddon",
+		"activate SwagAddon",
+		"update SwagPayPal",
+	}, actions(changes))
+
+	var out bytes.Buffer
+	require.NoError(t, writeExtensionChanges(&out, changes))
+	assert.Contains(t, out.String(), "9.0.0 -> 9.1.0")
+}
+
+func TestPlanExtensionChangesUpToDate(t *testing.T) {
+	enabled := true
+
+	changes, err := planExtensionChanges(
+		map[string]shop.ConfigExtensionState{"SwagPayPal": {Active: &enabled, Version: "9.0.0"}},
+		adminSdk.ExtensionList{liveExtension("SwagPayPal", true, true, "9.0.0", "9.1.0")},
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Installed store extensions are not downloaded again
+	store := liveExtension("SwagPayPal", true, true, "9.0.0", "9.1.0")
+	store.Source = "store"
+	store.UpdateSource = "store"
+
+	changes, err = planExtensionChanges(
+		map[string]shop.ConfigExtensionState{"SwagPayPal": {Active: &enabled, Version: "9.0.0"}},
+		adminSdk.ExtensionList{store},
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// An update from the store is downloaded first
+	changes, err = planExtensionChanges(
+		map[string]shop.ConfigExtensionState{"SwagPayPal": {Active: &enabled, Version: "9.1.0"}},
+		adminSdk.ExtensionList{store},
+		nil,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"download SwagPayPal", "update SwagPayPal"}, actions(changes))
+}
+
+func TestPlanExtensionChangesErrors(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	live := adminSdk.ExtensionList{liveExtension("SwagPayPal", true, true, "9.0.0", "9.1.0")}
+
+	_, err := planExtensionChanges(map[string]shop.ConfigExtensionState{"Missing": {Active: &enabled}}, live, nil)
+	assert.ErrorContains(t, err, "not available in the shop")
+
+	_, err = planExtensionChanges(map[string]shop.ConfigExtensionState{"SwagPayPal": {Version: "10.0.0"}}, live, nil)
+	assert.ErrorContains(t, err, "version 10.0.0")
+
+	_, err = planExtensionChanges(map[string]shop.ConfigExtensionState{"SwagPayPal": {Installed: &disabled, Active: &enabled}}, live, nil)
+	assert.ErrorContains(t, err, "cannot be active")
+
+	_, err = sortByDependencies([]string{"A", "B"}, map[string][]string{"A": {"B"}, "B": {"A"}})
+	assert.ErrorContains(t, err, "circular")
+}
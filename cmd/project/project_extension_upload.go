@@ -3,21 +3,17 @@ package project
 import (
 	"archive/zip"
 	"bytes"
-	"context"
-	"encoding/json"
-	"encoding/xml"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
 	cp "github.com/otiai10/copy"
-	"github.com/shyim/go-version"
 	"github.com/spf13/cobra"
 
 	"github.com/shopware/shopware-cli/extension"
+	"github.com/shopware/shopware-cli/internal/changelog"
 	"github.com/shopware/shopware-cli/logging"
 	"github.com/shopware/shopware-cli/shop"
 )
@@ -60,13 +56,31 @@ var projectExtensionUploadCmd = &cobra.Command{
 			return err
 		}
 
+		requireSignature, _ := cmd.PersistentFlags().GetBool("require-signature")
+
+		if requireSignature {
+			if isFolder {
+				return fmt.Errorf("signature verification requires a zip file, got folder %s", path)
+			}
+
+			if increaseVersionBeforeUpload {
+				return fmt.Errorf("--increase-version cannot be combined with --require-signature as it modifies the signed zip")
+			}
+
+			if err := verifyUploadSignature(cmd, path); err != nil {
+				return fmt.Errorf("refusing to upload %s: %w", path, err)
+			}
+
+			logging.FromContext(cmd.Context()).Infof("Verified signature of %s", path)
+		}
+
 		extCfg := ext.GetExtensionConfig()
 		if err != nil {
 			logging.FromContext(cmd.Context()).Fatalln(fmt.Errorf("update: %v", err))
 		}
 
 		if increaseVersionBeforeUpload {
-			if err := increaseExtensionVersion(cmd.Context(), ext); err != nil {
+			if err := increaseExtensionVersion(ext); err != nil {
 				return err
 			}
 
@@ -233,118 +247,55 @@ var projectExtensionUploadCmd = &cobra.Command{
 	},
 }
 
-func increaseExtensionVersion(ctx context.Context, ext extension.Extension) error {
-	if ext.GetType() == "app" {
-		manifestPath := fmt.Sprintf("%s/manifest.xml", ext.GetPath())
-		file, err := os.Open(manifestPath)
-		if err != nil {
-			return fmt.Errorf("cannot read manifest file: %w", err)
-		}
-
-		defer func() {
-			if err := file.Close(); err != nil {
-				logging.FromContext(ctx).Errorf("increaseExtensionVersion: %v", err)
-			}
-		}()
-
-		var buf bytes.Buffer
-		decoder := xml.NewDecoder(file)
-		encoder := xml.NewEncoder(&buf)
-
-		for {
-			token, err := decoder.Token()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				logging.FromContext(ctx).Errorf("error getting token: %v\n", err)
-				break
-			}
-
-			if v, ok := token.(xml.StartElement); ok {
-				if v.Name.Local == "version" {
-					var versionStr string
-					if err = decoder.DecodeElement(&versionStr, &v); err != nil {
-						return err
-					}
-
-					ver, err := version.NewVersion(versionStr)
-					if err != nil {
-						return err
-					}
-
-					ver.IncreasePatch()
-
-					if err = encoder.EncodeElement(ver.String(), v); err != nil {
-						return err
-					}
-					continue
-				}
-			}
-
-			if err := encoder.EncodeToken(token); err != nil {
-				return err
-			}
-		}
-
-		// must call flush, otherwise some elements will be missing
-		if err := encoder.Flush(); err != nil {
-			return err
-		}
-
-		newManifest := buf.String()
-		newManifest = strings.ReplaceAll(newManifest, "xmlns:_xmlns=\"xmlns\" _xmlns:xsi=", "xmlns:xsi=")
-		newManifest = strings.ReplaceAll(newManifest, "xmlns:_XMLSchema-instance=\"http://www.w3.org/2001/XMLSchema-instance\" _XMLSchema-instance:noNamespaceSchemaLocation=", "xsi:noNamespaceSchemaLocation=")
-
-		if err := os.WriteFile(manifestPath, []byte(newManifest), os.ModePerm); err != nil {
-			return err
-		}
-
-		return nil
-	}
+func verifyUploadSignature(cmd *cobra.Command, zipFile string) error {
+	keyFile, _ := cmd.PersistentFlags().GetString("verify-key")
+	signatureFile, _ := cmd.PersistentFlags().GetString("signature")
 
-	composerJsonPath := fmt.Sprintf("%s/composer.json", ext.GetPath())
-
-	composerJsonContent, err := os.ReadFile(composerJsonPath)
-	if err != nil {
-		return err
+	if signatureFile == "" {
+		signatureFile = extension.SignatureFileName(zipFile)
 	}
 
-	var composerJson map[string]interface{}
-
-	if err := json.Unmarshal(composerJsonContent, &composerJson); err != nil {
-		return err
+	if _, err := os.Stat(signatureFile); os.IsNotExist(err) {
+		return fmt.Errorf("zip is not signed, signature %s does not exist", signatureFile)
 	}
 
-	versionStr, ok := composerJson["version"].(string)
-
-	if !ok {
-		return nil
+	key, err := extension.LoadVerifyKey(keyFile)
+	if err != nil {
+		return fmt.Errorf("load public key: %w", err)
 	}
 
-	ver, err := version.NewVersion(versionStr)
+	signature, err := extension.ReadSignature(signatureFile)
 	if err != nil {
 		return err
 	}
 
-	ver.IncreasePatch()
-
-	composerJson["version"] = ver.String()
+	return extension.VerifyZipSignature(zipFile, signature, key)
+}
 
-	composerJsonContent, err = json.Marshal(composerJson)
+func increaseExtensionVersion(ext extension.Extension) error {
+	currentVersion, err := ext.GetVersion()
 	if err != nil {
+		// Extensions without a version in composer.json get their version from the repository
+		if ext.GetType() != extension.TypePlatformApp {
+			return nil
+		}
+
 		return err
 	}
 
-	if err := os.WriteFile(composerJsonPath, composerJsonContent, os.ModePerm); err != nil {
+	newVersion, err := extension.BumpVersion(currentVersion, changelog.BumpPatch)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return extension.UpdateExtensionVersion(ext, newVersion)
 }
 
 func init() {
 	projectExtensionCmd.AddCommand(projectExtensionUploadCmd)
 	projectExtensionUploadCmd.PersistentFlags().Bool("activate", false, "Installs, Activates, Updates the extension")
 	projectExtensionUploadCmd.PersistentFlags().Bool("increase-version", false, "Increases extension version before uploading")
+	projectExtensionUploadCmd.PersistentFlags().Bool("require-signature", false, "Refuse to upload zips without a valid signature")
+	projectExtensionUploadCmd.PersistentFlags().String("verify-key", "", "Path to the public key, defaults to the SHOPWARE_CLI_SIGNING_PUBLIC_KEY environment variable")
+	projectExtensionUploadCmd.PersistentFlags().String("signature", "", "Path to the signature file, defaults to the zip path with .sig suffix")
 }
@@ -25,6 +25,7 @@ var (
 	imageProxyClear       bool
 	imageProxyExternalURL string
 	imageProxySkipConfig  bool
+	imageProxyThumbnail   string
 )
 
 type responseCapture struct {
@@ -59,7 +60,8 @@ var projectImageProxyCmd = &cobra.Command{
 	Use:   "image-proxy",
 	Short: "Start a proxy server for serving images from the public folder",
 	Long: `Start an HTTP server that serves files from the public folder of the closest Shopware project.
-If a file is not found locally, it proxies the request to the upstream server.`,
+If a file is not found locally, it proxies the request to the upstream server.
+Thumbnails missing in both places are generated from the original media.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		path, err := findClosestShopwareProject()
 		if err != nil {
@@ -87,6 +89,15 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 			return fmt.Errorf("invalid upstream URL: %w", err)
 		}
 
+		thumbnailFormat := imageProxyThumbnail
+		if thumbnailFormat == "" && cfg.ImageProxy != nil {
+			thumbnailFormat = cfg.ImageProxy.ThumbnailFormat
+		}
+
+		if err := validateThumbnailFormat(thumbnailFormat); err != nil {
+			return err
+		}
+
 		// Determine public folder path
 		publicPath := filepath.Join(path, "public")
 		stat, err := os.Stat(publicPath)
@@ -115,6 +126,14 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 			http.Error(w, "Bad Gateway", http.StatusBadGateway)
 		}
 
+		thumbnails := thumbnailGenerator{
+			publicPath: publicPath,
+			cacheDir:   cacheDir,
+			upstream:   upstream,
+			client:     http.DefaultClient,
+			format:     thumbnailFormat,
+		}
+
 		// Create handler
 		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Clean the path
@@ -140,14 +159,11 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 			}
 
 			// Check cache
-			cachePath := filepath.Join(cacheDir, strings.ReplaceAll(cleanPath, "/", "_"))
-			cacheMetaPath := cachePath + ".meta"
-			if data, err := os.ReadFile(cachePath); err == nil {
+			if data, contentType, err := readImageProxyCache(cacheDir, cleanPath); err == nil {
 				logging.FromContext(cmd.Context()).Debugf("Serving from cache: %s", cleanPath)
 
-				// Read content type from meta file if it exists
-				if metaData, err := os.ReadFile(cacheMetaPath); err == nil {
-					w.Header().Set("Content-Type", string(metaData))
+				if contentType != "" {
+					w.Header().Set("Content-Type", contentType)
 				}
 
 				w.Header().Set("X-Cache", "HIT")
@@ -155,6 +171,12 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 				return
 			}
 
+			// Thumbnails missing upstream are generated from the original media
+			if thumbnail, ok := parseThumbnailPath(filepath.ToSlash(cleanPath)); ok {
+				serveThumbnail(w, r, thumbnails, thumbnail, filepath.ToSlash(cleanPath))
+				return
+			}
+
 			// If not found locally or in cache, proxy to upstream
 			logging.FromContext(cmd.Context()).Debugf("Proxying to upstream: %s", cleanPath)
 
@@ -178,20 +200,7 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 
 			// Cache successful responses
 			if statusCode == http.StatusOK && buf.Len() > 0 {
-				cachePath := filepath.Join(cacheDir, strings.ReplaceAll(cleanPath, "/", "_"))
-				cacheMetaPath := cachePath + ".meta"
-
-				// Ensure cache subdirectories exist
-				if dir := filepath.Dir(cachePath); dir != cacheDir {
-					_ = os.MkdirAll(dir, 0755)
-				}
-
-				// Write the file content
-				if err := os.WriteFile(cachePath, buf.Bytes(), 0644); err == nil {
-					// Write content type to meta file
-					if contentType != "" {
-						_ = os.WriteFile(cacheMetaPath, []byte(contentType), 0644)
-					}
+				if err := writeImageProxyCache(cacheDir, cleanPath, buf.Bytes(), contentType); err == nil {
 					logging.FromContext(cmd.Context()).Debugf("Cached file: %s", cleanPath)
 				}
 			}
@@ -296,6 +305,81 @@ If a file is not found locally, it proxies the request to the upstream server.`,
 	},
 }
 
+// serveThumbnail serves the thumbnail from upstream, or generates it from the original media when upstream does not have it.
+func serveThumbnail(w http.ResponseWriter, r *http.Request, thumbnails thumbnailGenerator, thumbnail thumbnailRequest, path string) {
+	logger := logging.FromContext(r.Context())
+
+	data, contentType, status, err := fetchUpstream(r.Context(), thumbnails.client, thumbnails.upstream, path)
+	if err != nil {
+		logger.Debugf("Cannot fetch thumbnail from upstream: %v", err)
+		status = http.StatusBadGateway
+	}
+
+	cacheHeader := "MISS"
+
+	if status != http.StatusOK {
+		generated, generatedType, err := thumbnails.generate(r.Context(), thumbnail)
+		if err != nil {
+			logger.Debugf("Cannot generate thumbnail %s: %v", path, err)
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+
+		logger.Debugf("Generated thumbnail: %s", path)
+		data, contentType, cacheHeader = generated, generatedType, "GENERATED"
+	}
+
+	if err := writeImageProxyCache(thumbnails.cacheDir, path, data, contentType); err == nil {
+		logger.Debugf("Cached file: %s", path)
+	}
+
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	w.Header().Set("X-Cache", cacheHeader)
+	_, _ = w.Write(data)
+}
+
+func imageProxyCachePath(cacheDir, path string) string {
+	return filepath.Join(cacheDir, strings.ReplaceAll(path, "/", "_"))
+}
+
+// readImageProxyCache returns the cached file and its content type.
+func readImageProxyCache(cacheDir, path string) ([]byte, string, error) {
+	cachePath := imageProxyCachePath(cacheDir, path)
+
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Read content type from meta file if it exists
+	contentType, _ := os.ReadFile(cachePath + ".meta")
+
+	return data, string(contentType), nil
+}
+
+func writeImageProxyCache(cacheDir, path string, data []byte, contentType string) error {
+	cachePath := imageProxyCachePath(cacheDir, path)
+
+	// Ensure cache subdirectories exist
+	if dir := filepath.Dir(cachePath); dir != cacheDir {
+		_ = os.MkdirAll(dir, 0755)
+	}
+
+	if err := os.WriteFile(cachePath, data, 0644); err != nil {
+		return err
+	}
+
+	// Write content type to meta file
+	if contentType != "" {
+		return os.WriteFile(cachePath+".meta", []byte(contentType), 0644)
+	}
+
+	return nil
+}
+
 func init() {
 	projectRootCmd.AddCommand(projectImageProxyCmd)
 	projectImageProxyCmd.Flags().StringVar(&imageProxyPort, "port", "8080", "Port to listen on")
@@ -303,4 +387,5 @@ func init() {
 	projectImageProxyCmd.Flags().BoolVar(&imageProxyClear, "clear", false, "Clear cache before starting")
 	projectImageProxyCmd.Flags().StringVar(&imageProxyExternalURL, "external-url", "", "External URL for Shopware config (e.g., for reverse proxy setups)")
 	projectImageProxyCmd.Flags().BoolVar(&imageProxySkipConfig, "skip-config", false, "Skip creating Shopware config file")
+	projectImageProxyCmd.Flags().StringVar(&imageProxyThumbnail, "thumbnail-format", "", "Format of generated thumbnails: original or webp (overrides config)")
 }
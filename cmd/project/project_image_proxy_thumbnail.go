@@ -0,0 +1,220 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/shopware/shopware-cli/internal/webp"
+)
+
+const (
+	thumbnailFormatOriginal = "original"
+	thumbnailFormatWebP     = "webp"
+	thumbnailFormatAVIF     = "avif"
+
+	// Same default quality as the thumbnails of Shopware
+	thumbnailJPEGQuality = 80
+)
+
+// thumbnailPathPattern matches Shopware thumbnail URLs like /thumbnail/01/23/45/1700000000/shirt_400x400.jpg.
+var thumbnailPathPattern = regexp.MustCompile(`^/thumbnail/(.+)[_-](\d+)x(\d+)\.([A-Za-z0-9]+)$`)
+
+// thumbnailOriginalExtensions are tried for the original media, after the extension of the thumbnail.
+var thumbnailOriginalExtensions = []string{"jpg", "jpeg", "png", "gif", "webp"}
+
+type thumbnailRequest struct {
+	// Path of the original media without the extension, like /media/01/23/45/1700000000/shirt
+	original  string
+	width     int
+	height    int
+	extension string
+}
+
+func parseThumbnailPath(path string) (thumbnailRequest, bool) {
+	matches := thumbnailPathPattern.FindStringSubmatch(path)
+	if matches == nil {
+		return thumbnailRequest{}, false
+	}
+
+	width, err := strconv.Atoi(matches[2])
+	if err != nil || width == 0 {
+		return thumbnailRequest{}, false
+	}
+
+	height, err := strconv.Atoi(matches[3])
+	if err != nil || height == 0 {
+		return thumbnailRequest{}, false
+	}
+
+	return thumbnailRequest{
+		original:  "/media/" + matches[1],
+		width:     width,
+		height:    height,
+		extension: strings.ToLower(matches[4]),
+	}, true
+}
+
+// originalPaths returns the possible paths of the original media.
+func (t thumbnailRequest) originalPaths() []string {
+	paths := []string{t.original + "." + t.extension}
+
+	for _, extension := range thumbnailOriginalExtensions {
+		if extension != t.extension {
+			paths = append(paths, t.original+"."+extension)
+		}
+	}
+
+	return paths
+}
+
+// thumbnailSize fits the original into the thumbnail size and keeps the aspect ratio. Like Shopware, images are never upscaled.
+func thumbnailSize(originalWidth, originalHeight, width, height int) (int, int) {
+	scale := min(float64(width)/float64(originalWidth), float64(height)/float64(originalHeight), 1)
+
+	return max(int(float64(originalWidth)*scale+0.5), 1), max(int(float64(originalHeight)*scale+0.5), 1)
+}
+
+func validateThumbnailFormat(format string) error {
+	switch format {
+	case "", thumbnailFormatOriginal, thumbnailFormatWebP:
+		return nil
+	case thumbnailFormatAVIF:
+		return fmt.Errorf("thumbnail format avif is not supported, as there is no AVIF encoder in pure Go, use webp instead")
+	default:
+		return fmt.Errorf("unknown thumbnail format %s, supported are %s and %s", format, thumbnailFormatOriginal, thumbnailFormatWebP)
+	}
+}
+
+// thumbnailGenerator creates missing thumbnails from the original media in the public folder, the cache or the upstream server.
+type thumbnailGenerator struct {
+	publicPath string
+	cacheDir   string
+	upstream   *url.URL
+	client     *http.Client
+	format     string
+}
+
+// generate returns the thumbnail and its content type.
+func (g thumbnailGenerator) generate(ctx context.Context, thumbnail thumbnailRequest) ([]byte, string, error) {
+	original, err := g.loadOriginal(ctx, thumbnail)
+	if err != nil {
+		return nil, "", err
+	}
+
+	bounds := original.Bounds()
+	width, height := thumbnailSize(bounds.Dx(), bounds.Dy(), thumbnail.width, thumbnail.height)
+
+	resized := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), original, bounds, draw.Src, nil)
+
+	format := thumbnail.extension
+	if g.format == thumbnailFormatWebP {
+		format = thumbnailFormatWebP
+	}
+
+	var buf bytes.Buffer
+
+	switch format {
+	case "jpg", "jpeg":
+		err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: thumbnailJPEGQuality})
+		format = "jpeg"
+	case "png":
+		err = png.Encode(&buf, resized)
+	case "gif":
+		err = gif.Encode(&buf, resized, nil)
+	case thumbnailFormatWebP:
+		err = webp.Encode(&buf, resized)
+	default:
+		return nil, "", fmt.Errorf("cannot create thumbnails in the format %s", format)
+	}
+
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot encode the thumbnail: %w", err)
+	}
+
+	return buf.Bytes(), "image/" + format, nil
+}
+
+func (g thumbnailGenerator) loadOriginal(ctx context.Context, thumbnail thumbnailRequest) (image.Image, error) {
+	for _, path := range thumbnail.originalPaths() {
+		data, err := g.readOriginal(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if data == nil {
+			continue
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode the original media %s: %w", path, err)
+		}
+
+		return img, nil
+	}
+
+	return nil, fmt.Errorf("original media of %s not found", thumbnail.original)
+}
+
+// readOriginal returns the original media from the public folder, the cache or the upstream server, or nil when it does not exist.
+func (g thumbnailGenerator) readOriginal(ctx context.Context, path string) ([]byte, error) {
+	if data, err := os.ReadFile(filepath.Join(g.publicPath, filepath.FromSlash(path))); err == nil {
+		return data, nil
+	}
+
+	if data, _, err := readImageProxyCache(g.cacheDir, path); err == nil {
+		return data, nil
+	}
+
+	data, contentType, status, err := fetchUpstream(ctx, g.client, g.upstream, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, nil
+	}
+
+	_ = writeImageProxyCache(g.cacheDir, path, data, contentType)
+
+	return data, nil
+}
+
+// fetchUpstream requests the path from the upstream server and returns the body, the content type and the status code.
+func fetchUpstream(ctx context.Context, client *http.Client, upstream *url.URL, path string) ([]byte, string, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.JoinPath(path).String(), nil)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("cannot request %s from upstream: %w", path, err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("cannot read %s from upstream: %w", path, err)
+	}
+
+	return data, resp.Header.Get("Content-Type"), resp.StatusCode, nil
+}
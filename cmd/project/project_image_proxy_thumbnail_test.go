@@ -0,0 +1,171 @@
+package project
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestParseThumbnailPath(t *testing.T) {
+	thumbnail, ok := parseThumbnailPath("/thumbnail/01/23/45/1700000000/summer_shirt_400x300.jpg")
+	require.True(t, ok)
+	assert.Equal(t, thumbnailRequest{original: "/media/01/23/45/1700000000/summer_shirt", width: 400, height: 300, extension: "jpg"}, thumbnail)
+	assert.Equal(t, []string{
+		"/media/01/23/45/1700000000/summer_shirt.jpg",
+		"/media/01/23/45/1700000000/summer_shirt.jpeg",
+		"/media/01/23/45/1700000000/summer_shirt.png",
+		"/media/01/23/45/1700000000/summer_shirt.gif",
+		"/media/01/23/45/1700000000/summer_shirt.webp",
+	}, thumbnail.originalPaths())
+
+	thumbnail, ok = parseThumbnailPath("/thumbnail/ab/cd/logo-1920x1920.PNG")
+	require.True(t, ok)
+	assert.Equal(t, thumbnailRequest{original: "/media/ab/cd/logo", width: 1920, height: 1920, extension: "png"}, thumbnail)
+
+	_, ok = parseThumbnailPath("/media/ab/cd/logo_400x400.png")
+	assert.False(t, ok)
+
+	_, ok = parseThumbnailPath("/thumbnail/ab/cd/logo.png")
+	assert.False(t, ok)
+
+	_, ok = parseThumbnailPath("/thumbnail/ab/cd/logo_0x400.png")
+	assert.False(t, ok)
+}
+
+func TestThumbnailSize(t *testing.T) {
+	width, height := thumbnailSize(1600, 1200, 400, 400)
+	assert.Equal(t, []int{400, 300}, []int{width, height})
+
+	width, height = thumbnailSize(1000, 2000, 800, 800)
+	assert.Equal(t, []int{400, 800}, []int{width, height})
+
+	// Smaller images are not upscaled
+	width, height = thumbnailSize(200, 100, 1920, 1920)
+	assert.Equal(t, []int{200, 100}, []int{width, height})
+
+	width, height = thumbnailSize(10000, 10, 400, 400)
+	assert.Equal(t, []int{400, 1}, []int{width, height})
+}
+
+func TestValidateThumbnailFormat(t *testing.T) {
+	assert.NoError(t, validateThumbnailFormat(""))
+	assert.NoError(t, validateThumbnailFormat("webp"))
+	assert.ErrorContains(t, validateThumbnailFormat("avif"), "not supported")
+	assert.ErrorContains(t, validateThumbnailFormat("bmp"), "unknown thumbnail format bmp")
+}
+
+func testOriginalMedia(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 160, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 160; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func TestServeThumbnailGeneratesFromUpstreamOriginal(t *testing.T) {
+	original := testOriginalMedia(t)
+	requested := []string{}
+
+	upstreamServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+
+		if r.URL.Path == "/media/ab/cd/banner.png" {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write(original)
+			return
+		}
+
+		http.NotFound(w, r)
+	}))
+	defer upstreamServer.Close()
+
+	upstream, err := url.Parse(upstreamServer.URL)
+	require.NoError(t, err)
+
+	thumbnails := thumbnailGenerator{publicPath: t.TempDir(), cacheDir: t.TempDir(), upstream: upstream, client: upstreamServer.Client(), format: thumbnailFormatOriginal}
+	thumbnail, ok := parseThumbnailPath("/thumbnail/ab/cd/banner_40x40.png")
+	require.True(t, ok)
+
+	recorder := httptest.NewRecorder()
+	serveThumbnail(recorder, httptest.NewRequest(http.MethodGet, "/thumbnail/ab/cd/banner_40x40.png", nil), thumbnails, thumbnail, "/thumbnail/ab/cd/banner_40x40.png")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "GENERATED", recorder.Header().Get("X-Cache"))
+	assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, []string{"/thumbnail/ab/cd/banner_40x40.png", "/media/ab/cd/banner.png"}, requested)
+
+	img, err := png.Decode(recorder.Body)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(40, 20), img.Bounds().Size())
+
+	// The original and the thumbnail are cached
+	cached, contentType, err := readImageProxyCache(thumbnails.cacheDir, "/media/ab/cd/banner.png")
+	require.NoError(t, err)
+	assert.Equal(t, original, cached)
+	assert.Equal(t, "image/png", contentType)
+
+	_, contentType, err = readImageProxyCache(thumbnails.cacheDir, "/thumbnail/ab/cd/banner_40x40.png")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+}
+
+func TestServeThumbnailConvertsLocalOriginalToWebP(t *testing.T) {
+	upstreamServer := httptest.NewServer(http.NotFoundHandler())
+	defer upstreamServer.Close()
+
+	upstream, err := url.Parse(upstreamServer.URL)
+	require.NoError(t, err)
+
+	publicPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(publicPath, "media", "ab"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(publicPath, "media", "ab", "banner.png"), testOriginalMedia(t), 0o644))
+
+	thumbnails := thumbnailGenerator{publicPath: publicPath, cacheDir: t.TempDir(), upstream: upstream, client: upstreamServer.Client(), format: thumbnailFormatWebP}
+	thumbnail, ok := parseThumbnailPath("/thumbnail/ab/banner_400x400.jpg")
+	require.True(t, ok)
+
+	recorder := httptest.NewRecorder()
+	serveThumbnail(recorder, httptest.NewRequest(http.MethodGet, "/thumbnail/ab/banner_400x400.jpg", nil), thumbnails, thumbnail, "/thumbnail/ab/banner_400x400.jpg")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "image/webp", recorder.Header().Get("Content-Type"))
+
+	img, err := webp.Decode(recorder.Body)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(160, 80), img.Bounds().Size())
+}
+
+func TestServeThumbnailWithoutOriginal(t *testing.T) {
+	upstreamServer := httptest.NewServer(http.NotFoundHandler())
+	defer upstreamServer.Close()
+
+	upstream, err := url.Parse(upstreamServer.URL)
+	require.NoError(t, err)
+
+	thumbnails := thumbnailGenerator{publicPath: t.TempDir(), cacheDir: t.TempDir(), upstream: upstream, client: upstreamServer.Client()}
+	thumbnail, _ := parseThumbnailPath("/thumbnail/ab/missing_400x400.jpg")
+
+	recorder := httptest.NewRecorder()
+	serveThumbnail(recorder, httptest.NewRequest(http.MethodGet, "/thumbnail/ab/missing_400x400.jpg", nil), thumbnails, thumbnail, "/thumbnail/ab/missing_400x400.jpg")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
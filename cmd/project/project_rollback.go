@@ -0,0 +1,94 @@
+package project
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/internal/release"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+var projectRollbackCmd = &cobra.Command{
+	Use:   "rollback [release]",
+	Short: "Switches the current symlink back to the previous release",
+	Long: `Switches the current symlink of the release layout created by project deploy --release back to the previous release or the given one.
+
+The database is not rolled back, the migrations of the newer release stay applied.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		releasePath, _ := cmd.Flags().GetString("release-path")
+
+		var projectRoot string
+
+		// A relative path of the project config is resolved from the project root
+		if releasePath == "" {
+			var err error
+
+			if projectRoot, err = findClosestShopwareProject(); err != nil {
+				return err
+			}
+		}
+
+		cfg, err := shop.ReadConfig(projectConfigPath, true)
+		if err != nil {
+			return err
+		}
+
+		deployment := cfg.ConfigDeployment
+		if deployment == nil {
+			deployment = &shop.ConfigDeployment{}
+		}
+
+		layout, err := releaseLayout(releasePath, projectRoot, deployment)
+		if err != nil {
+			return err
+		}
+
+		var name string
+
+		if len(args) > 0 {
+			name = args[0]
+		} else if name, err = layout.Previous(); err != nil {
+			return err
+		}
+
+		if err := layout.Activate(name); err != nil {
+			return err
+		}
+
+		logging.FromContext(cmd.Context()).Infof("Activated release %s", name)
+
+		return nil
+	},
+}
+
+// resolveReleaseLayout returns the release layout of the --release-path flag or the project config.
+func resolveReleaseLayout(cmd *cobra.Command, projectRoot string, deployment *shop.ConfigDeployment) (release.Layout, error) {
+	releasePath, _ := cmd.Flags().GetString("release-path")
+
+	return releaseLayout(releasePath, projectRoot, deployment)
+}
+
+func releaseLayout(releasePath, projectRoot string, deployment *shop.ConfigDeployment) (release.Layout, error) {
+	if releasePath != "" {
+		return release.Layout{Base: releasePath}, nil
+	}
+
+	if deployment.Releases.Path == "" {
+		return release.Layout{}, errors.New("no release path configured, set deployment.releases.path in the project config or use --release-path")
+	}
+
+	if filepath.IsAbs(deployment.Releases.Path) {
+		return release.Layout{Base: deployment.Releases.Path}, nil
+	}
+
+	return release.Layout{Base: filepath.Join(projectRoot, deployment.Releases.Path)}, nil
+}
+
+func init() {
+	projectRootCmd.AddCommand(projectRollbackCmd)
+	projectRollbackCmd.Flags().String("release-path", "", "Directory of the releases, overrides deployment.releases.path of the project config")
+}
@@ -0,0 +1,28 @@
+package project
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+func TestReleaseLayout(t *testing.T) {
+	deployment := &shop.ConfigDeployment{}
+
+	_, err := releaseLayout("", "/project", deployment)
+	assert.ErrorContains(t, err, "no release path configured")
+
+	deployment.Releases.Path = "../releases"
+
+	layout, err := releaseLayout("", "/srv/project", deployment)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/srv/releases"), layout.Base)
+
+	layout, err = releaseLayout("/var/www", "/srv/project", deployment)
+	require.NoError(t, err)
+	assert.Equal(t, "/var/www", layout.Base)
+}
@@ -0,0 +1,140 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+// schedulerPoolName is the pool running scheduled-task:run, it cannot be used by the worker pools of the config.
+const schedulerPoolName = "scheduler"
+
+var projectSchedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run the scheduled task runner in background.",
+	RunE: func(cobraCmd *cobra.Command, _ []string) error {
+		projectRoot, err := findClosestShopwareProject()
+		if err != nil {
+			return err
+		}
+
+		worker, err := resolveSchedulerConfig(cobraCmd)
+		if err != nil {
+			return err
+		}
+
+		cancelCtx, cancel := context.WithCancel(cobraCmd.Context())
+		cancelOnTermination(cancelCtx, cancel)
+
+		superviseWorkerPools(cancelCtx, worker, newWorkerOptions(cobraCmd, projectRoot), fmt.Sprintf("shopware-cli-%d", os.Getpid()))
+
+		return nil
+	},
+}
+
+// resolveSchedulerConfig returns the worker config without the pools, as the consumers are run by project worker.
+// The metrics listener is only taken from the flag, so it does not conflict with the one of project worker.
+func resolveSchedulerConfig(cmd *cobra.Command) (shop.ConfigWorker, error) {
+	cfg, err := shop.ReadConfig(projectConfigPath, true)
+	if err != nil {
+		return shop.ConfigWorker{}, err
+	}
+
+	worker := shop.ConfigWorker{}
+	if cfg.Worker != nil {
+		worker = *cfg.Worker
+	}
+
+	worker.Pools = nil
+	worker.Scheduler = true
+	worker.MetricsListen, _ = cmd.Flags().GetString("metrics-listen")
+
+	return normalizeWorkerConfig(worker)
+}
+
+// overdueScheduledTasks returns the scheduled tasks which are waiting longer than the threshold for their execution,
+// with the seconds they are overdue.
+func overdueScheduledTasks(ctx context.Context, db *sql.DB, threshold time.Duration) (map[string]int, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name, TIMESTAMPDIFF(SECOND, next_execution_time, UTC_TIMESTAMP()) FROM scheduled_task WHERE status IN ('scheduled', 'queued') AND next_execution_time < UTC_TIMESTAMP() - INTERVAL ? SECOND", int(threshold.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the scheduled tasks: %w", err)
+	}
+
+	defer rows.Close()
+
+	overdue := map[string]int{}
+
+	for rows.Next() {
+		var name string
+		var seconds int
+
+		if err := rows.Scan(&name, &seconds); err != nil {
+			return nil, fmt.Errorf("cannot read the scheduled tasks: %w", err)
+		}
+
+		overdue[name] = seconds
+	}
+
+	return overdue, rows.Err()
+}
+
+// overdueTaskReporter logs a scheduled task once when it becomes overdue, and again after it was executed in between.
+type overdueTaskReporter struct {
+	threshold time.Duration
+	reported  map[string]bool
+}
+
+func newOverdueTaskReporter(threshold time.Duration) *overdueTaskReporter {
+	return &overdueTaskReporter{threshold: threshold, reported: map[string]bool{}}
+}
+
+func (r *overdueTaskReporter) check(ctx context.Context, db *sql.DB, metrics *workerMetrics) {
+	overdue, err := overdueScheduledTasks(ctx, db, r.threshold)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Cannot check for overdue scheduled tasks: %s", err.Error())
+		return
+	}
+
+	metrics.setOverdueTasks(overdue)
+
+	for _, task := range r.newlyOverdue(overdue) {
+		logging.FromContext(ctx).Warnf("Scheduled task %s is overdue by %s", task, time.Duration(overdue[task])*time.Second)
+	}
+}
+
+// newlyOverdue returns the overdue tasks which were not reported yet.
+func (r *overdueTaskReporter) newlyOverdue(overdue map[string]int) []string {
+	tasks := make([]string, 0)
+
+	for task := range overdue {
+		if !r.reported[task] {
+			tasks = append(tasks, task)
+		}
+	}
+
+	r.reported = make(map[string]bool, len(overdue))
+	for task := range overdue {
+		r.reported[task] = true
+	}
+
+	sort.Strings(tasks)
+
+	return tasks
+}
+
+func init() {
+	projectRootCmd.AddCommand(projectSchedulerCmd)
+	projectSchedulerCmd.PersistentFlags().Bool("verbose", false, "Enable verbose output")
+	projectSchedulerCmd.PersistentFlags().String("memory-limit", "", "Memory Limit")
+	projectSchedulerCmd.PersistentFlags().String("time-limit", "", "Time Limit")
+	projectSchedulerCmd.PersistentFlags().Uint("graceful-stop-limit", 0, "Graceful Stop Limit")
+	projectSchedulerCmd.PersistentFlags().String("metrics-listen", "", "Address of the HTTP listener for /healthz and /metrics, e.g. :9091")
+}
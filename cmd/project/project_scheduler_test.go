@@ -0,0 +1,36 @@
+package project
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerArgs(t *testing.T) {
+	options := workerOptions{memoryLimit: "512M", timeLimit: "120", verbose: true}
+
+	assert.Equal(t, []string{"scheduled-task:run", "--memory-limit=512M", "--time-limit=120", "-vvv"}, options.schedulerArgs())
+}
+
+func TestOverdueTaskReporter(t *testing.T) {
+	reporter := newOverdueTaskReporter(10 * time.Minute)
+
+	assert.Equal(t, []string{"log_entry.cleanup", "product_export_generate_task"}, reporter.newlyOverdue(map[string]int{"product_export_generate_task": 900, "log_entry.cleanup": 700}))
+	assert.Empty(t, reporter.newlyOverdue(map[string]int{"product_export_generate_task": 960}))
+
+	// A task is reported again when it becomes overdue after it was executed
+	assert.Equal(t, []string{"log_entry.cleanup"}, reporter.newlyOverdue(map[string]int{"log_entry.cleanup": 610, "product_export_generate_task": 1020}))
+}
+
+func TestOverdueTaskMetrics(t *testing.T) {
+	metrics := newWorkerMetrics()
+	metrics.setOverdueTasks(map[string]int{"log_entry.cleanup": 700})
+
+	var output strings.Builder
+	require.NoError(t, metrics.writePrometheus(&output))
+
+	assert.Contains(t, output.String(), "shopware_scheduled_task_overdue_seconds{task=\"log_entry.cleanup\"} 700\n")
+}
@@ -5,15 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"os/signal"
 	"strconv"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/time/rate"
 
 	"github.com/shopware/shopware-cli/internal/phpexec"
 	"github.com/shopware/shopware-cli/logging"
@@ -28,12 +27,7 @@ var projectWorkerCmd = &cobra.Command{
 		var err error
 		workerAmount := 1
 
-		isVerbose, _ := cobraCmd.Flags().GetBool("verbose")
 		queuesToConsume, _ := cobraCmd.Flags().GetString("queue")
-		memoryLimit, _ := cobraCmd.Flags().GetString("memory-limit")
-		timeLimit, _ := cobraCmd.Flags().GetString("time-limit")
-		gracefulStopLimit, _ := cobraCmd.Flags().GetUint("graceful-stop-limit")
-		messagesLimit, _ := cobraCmd.Flags().GetUint("limit")
 
 		if projectRoot, err = findClosestShopwareProject(); err != nil {
 			return err
@@ -46,95 +40,192 @@ var projectWorkerCmd = &cobra.Command{
 			}
 		}
 
-		if memoryLimit == "" {
-			memoryLimit = "512M"
-		}
+		cancelCtx, cancel := context.WithCancel(cobraCmd.Context())
+		cancelOnTermination(cancelCtx, cancel)
 
-		if timeLimit == "" {
-			timeLimit = "120"
+		transports := strings.Split(queuesToConsume, ",")
+		if queuesToConsume == "" {
+			transports = defaultWorkerTransports(projectRoot)
 		}
 
-		cancelCtx, cancel := context.WithCancel(cobraCmd.Context())
-		cancelOnTermination(cancelCtx, cancel)
+		options := newWorkerOptions(cobraCmd, projectRoot)
+		baseName := fmt.Sprintf("shopware-cli-%d", os.Getpid())
 
-		consumeArgs := []string{
-			"messenger:consume",
-			fmt.Sprintf("--memory-limit=%s", memoryLimit),
-			fmt.Sprintf("--time-limit=%s", timeLimit),
-			"--failure-limit=5",
+		worker, err := resolveWorkerPools(cobraCmd, transports, workerAmount, len(args) > 0)
+		if err != nil {
+			return err
 		}
 
-		if messagesLimit > 0 {
-			consumeArgs = append(consumeArgs, fmt.Sprintf("--limit=%d", messagesLimit))
+		superviseWorkerPools(cancelCtx, worker, options, baseName)
+
+		return nil
+	},
+}
+
+// newWorkerOptions reads the flags shared by project worker and project scheduler.
+func newWorkerOptions(cmd *cobra.Command, projectRoot string) workerOptions {
+	isVerbose, _ := cmd.Flags().GetBool("verbose")
+	memoryLimit, _ := cmd.Flags().GetString("memory-limit")
+	timeLimit, _ := cmd.Flags().GetString("time-limit")
+	gracefulStopLimit, _ := cmd.Flags().GetUint("graceful-stop-limit")
+	messagesLimit, _ := cmd.Flags().GetUint("limit")
+
+	if memoryLimit == "" {
+		memoryLimit = "512M"
+	}
+
+	if timeLimit == "" {
+		timeLimit = "120"
+	}
+
+	return workerOptions{
+		projectRoot:       projectRoot,
+		memoryLimit:       memoryLimit,
+		timeLimit:         timeLimit,
+		messagesLimit:     messagesLimit,
+		verbose:           isVerbose,
+		gracefulStopLimit: gracefulStopLimit,
+	}
+}
+
+type workerOptions struct {
+	projectRoot       string
+	memoryLimit       string
+	timeLimit         string
+	messagesLimit     uint
+	verbose           bool
+	gracefulStopLimit uint
+}
+
+// consumeArgs returns the arguments of messenger:consume, the limits of a pool take precedence over the flags.
+func (o workerOptions) consumeArgs(transports []string, memoryLimit, timeLimit string) []string {
+	if memoryLimit == "" {
+		memoryLimit = o.memoryLimit
+	}
+
+	if timeLimit == "" {
+		timeLimit = o.timeLimit
+	}
+
+	consumeArgs := []string{
+		"messenger:consume",
+		fmt.Sprintf("--memory-limit=%s", memoryLimit),
+		fmt.Sprintf("--time-limit=%s", timeLimit),
+		"--failure-limit=5",
+	}
+
+	if o.messagesLimit > 0 {
+		consumeArgs = append(consumeArgs, fmt.Sprintf("--limit=%d", o.messagesLimit))
+	}
+
+	consumeArgs = append(consumeArgs, transports...)
+
+	if o.verbose {
+		consumeArgs = append(consumeArgs, "-vvv")
+	}
+
+	return consumeArgs
+}
+
+// schedulerArgs returns the arguments of scheduled-task:run.
+func (o workerOptions) schedulerArgs() []string {
+	args := []string{
+		"scheduled-task:run",
+		fmt.Sprintf("--memory-limit=%s", o.memoryLimit),
+		fmt.Sprintf("--time-limit=%s", o.timeLimit),
+	}
+
+	if o.verbose {
+		args = append(args, "-vvv")
+	}
+
+	return args
+}
+
+func defaultWorkerTransports(projectRoot string) []string {
+	if is, _ := shop.IsShopwareVersion(projectRoot, ">=6.5.7"); is {
+		return []string{"async", "failed", "low_priority"}
+	} else if is, _ := shop.IsShopwareVersion(projectRoot, ">=6.5"); is {
+		return []string{"async", "failed"}
+	}
+
+	return nil
+}
+
+// runWorker starts the console command again whenever it exits, until the context is canceled.
+// A command crashing repeatedly is restarted with an increasing delay.
+func runWorker(ctx context.Context, options workerOptions, consumeArgs []string, pool, consumerName string, metrics *workerMetrics) {
+	backoff := workerBackoff{}
+
+	defer metrics.workerRemoved(pool, consumerName)
+
+	for restarts := 0; ; restarts++ {
+		if restarts > 0 {
+			metrics.workerRestarted(pool, consumerName)
 		}
 
-		if queuesToConsume == "" {
-			if is, _ := shop.IsShopwareVersion(projectRoot, ">=6.5.7"); is {
-				consumeArgs = append(consumeArgs, "async", "failed", "low_priority")
-			} else if is, _ := shop.IsShopwareVersion(projectRoot, ">=6.5"); is {
-				consumeArgs = append(consumeArgs, "async", "failed")
+		cmd := phpexec.ConsoleCommand(ctx, consumeArgs...)
+		cmd.Dir = options.projectRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Env = append(os.Environ(), fmt.Sprintf("MESSENGER_CONSUMER_NAME=%s", consumerName))
+		cmd.WaitDelay = time.Second
+		cmd.Cancel = func() error {
+			if options.gracefulStopLimit > 0 {
+				if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
+					return err
+				}
+
+				now := time.Now()
+
+				for time.Since(now) < time.Second*time.Duration(options.gracefulStopLimit) {
+					if isProcessStopped(cmd.Process) {
+						return os.ErrProcessDone
+					}
+					time.Sleep(time.Millisecond * 250)
+				}
 			}
-		} else {
-			consumeArgs = append(consumeArgs, strings.Split(queuesToConsume, ",")...)
+			return cmd.Process.Kill()
 		}
 
-		if isVerbose {
-			consumeArgs = append(consumeArgs, "-vvv")
+		startedAt := time.Now()
+
+		metrics.workerStarted(pool)
+		err := cmd.Run()
+		metrics.workerStopped(pool)
+
+		if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return
 		}
 
-		baseName := fmt.Sprintf("shopware-cli-%d", os.Getpid())
-		workerRatelimit := rate.NewLimiter(rate.Every(10*time.Second), workerAmount)
-
-		var wg sync.WaitGroup
-		for a := 0; a < workerAmount; a++ {
-			wg.Add(1)
-			go func(ctx context.Context, index int) {
-				defer wg.Done()
-
-				for {
-					if err = workerRatelimit.Wait(ctx); err != nil {
-						logging.FromContext(ctx).Error(err)
-						continue
-					}
+		exitCode := 0
 
-					cmd := phpexec.ConsoleCommand(cancelCtx, consumeArgs...)
-					cmd.Dir = projectRoot
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-					cmd.Env = append(os.Environ(), fmt.Sprintf("MESSENGER_CONSUMER_NAME=%s-%d", baseName, index))
-					cmd.WaitDelay = time.Second
-					cmd.Cancel = func() error {
-						if gracefulStopLimit > 0 {
-							if err := cmd.Process.Signal(syscall.SIGTERM); err != nil {
-								return err
-							}
-
-							now := time.Now()
-
-							for time.Since(now) < time.Second*time.Duration(gracefulStopLimit) {
-								if isProcessStopped(cmd.Process) {
-									return os.ErrProcessDone
-								}
-								time.Sleep(time.Millisecond * 250)
-							}
-						}
-						return cmd.Process.Kill()
-					}
+		if err != nil {
+			exitCode = -1
 
-					if err := cmd.Run(); err != nil {
-						if errors.Is(err, context.Canceled) {
-							break
-						}
-						logging.FromContext(ctx).Error(err)
-					}
-				}
-			}(cancelCtx, a)
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			}
+
+			logging.FromContext(ctx).Error(err)
 		}
 
-		wg.Wait()
+		delay := backoff.Next(exitCode, time.Since(startedAt))
+		metrics.workerExited(pool, consumerName, exitCode, backoff.failures)
 
-		return nil
-	},
+		if delay == 0 {
+			continue
+		}
+
+		logging.FromContext(ctx).Warnf("Worker %s failed %d times in a row, restarting in %s", consumerName, backoff.failures, delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
 }
 
 func init() {
@@ -145,6 +236,10 @@ func init() {
 	projectWorkerCmd.PersistentFlags().String("time-limit", "", "Time Limit")
 	projectWorkerCmd.PersistentFlags().Uint("graceful-stop-limit", 0, "Graceful Stop Limit")
 	projectWorkerCmd.PersistentFlags().Uint("limit", 0, "Messages Limit")
+	projectWorkerCmd.PersistentFlags().Int("min-workers", 1, "Minimum amount of workers in autoscaling mode")
+	projectWorkerCmd.PersistentFlags().Int("max-workers", 0, "Enables autoscaling by the queue depth up to this amount of workers")
+	projectWorkerCmd.PersistentFlags().String("metrics-listen", "", "Address of the HTTP listener for /healthz and /metrics, e.g. :9090")
+	projectWorkerCmd.PersistentFlags().Bool("with-scheduler", false, "Run scheduled-task:run next to the consumers")
 }
 
 func cancelOnTermination(ctx context.Context, cancel context.CancelFunc) {
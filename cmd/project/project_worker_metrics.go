@@ -0,0 +1,307 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+const (
+	// A worker running longer resets the failures in a row
+	workerStableRuntime = time.Minute
+
+	workerBackoffInitial = time.Second
+	workerBackoffMax     = 2 * time.Minute
+
+	// Workers failing this often in a row mark the supervisor as unhealthy
+	workerUnhealthyFailures = 5
+)
+
+// workerBackoff delays the restart of a crash looping worker. The delay doubles with every failure in a row
+// and is reset by a successful exit or after the worker ran stable for a while.
+type workerBackoff struct {
+	failures int
+}
+
+// Next records the exit of the worker and returns the delay before it is started again.
+func (b *workerBackoff) Next(exitCode int, runtime time.Duration) time.Duration {
+	if runtime >= workerStableRuntime {
+		b.failures = 0
+	}
+
+	if exitCode == 0 {
+		b.failures = 0
+
+		return 0
+	}
+
+	b.failures++
+
+	return min(workerBackoffInitial<<min(b.failures-1, 8), workerBackoffMax)
+}
+
+type workerMetricKey struct {
+	pool   string
+	worker string
+}
+
+type workerExitKey struct {
+	pool string
+	code int
+}
+
+// workerMetrics collects the state of all workers for the /metrics and /healthz endpoints.
+type workerMetrics struct {
+	mu          sync.Mutex
+	running     map[string]int
+	restarts    map[workerMetricKey]int
+	exits       map[workerExitKey]int
+	lastSuccess map[workerMetricKey]time.Time
+	failures    map[workerMetricKey]int
+	queueDepth  map[string]int
+	overdue     map[string]int
+}
+
+func newWorkerMetrics() *workerMetrics {
+	return &workerMetrics{
+		running:     map[string]int{},
+		restarts:    map[workerMetricKey]int{},
+		exits:       map[workerExitKey]int{},
+		lastSuccess: map[workerMetricKey]time.Time{},
+		failures:    map[workerMetricKey]int{},
+		queueDepth:  map[string]int{},
+		overdue:     map[string]int{},
+	}
+}
+
+func (m *workerMetrics) workerStarted(pool string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running[pool]++
+}
+
+func (m *workerMetrics) workerStopped(pool string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.running[pool]--
+}
+
+func (m *workerMetrics) workerRestarted(pool, worker string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.restarts[workerMetricKey{pool, worker}]++
+}
+
+func (m *workerMetrics) workerExited(pool, worker string, exitCode, failures int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := workerMetricKey{pool, worker}
+
+	m.exits[workerExitKey{pool, exitCode}]++
+	m.failures[key] = failures
+
+	if exitCode == 0 {
+		m.lastSuccess[key] = time.Now()
+	}
+}
+
+// workerRemoved forgets the failures of a worker stopped by the supervisor, so it does not affect the health anymore.
+func (m *workerMetrics) workerRemoved(pool, worker string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.failures, workerMetricKey{pool, worker})
+}
+
+func (m *workerMetrics) setQueueDepth(depths map[string]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for transport, depth := range depths {
+		m.queueDepth[transport] = depth
+	}
+}
+
+// setOverdueTasks replaces the scheduled tasks which are overdue with the seconds they are overdue.
+func (m *workerMetrics) setOverdueTasks(overdue map[string]int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.overdue = overdue
+}
+
+// unhealthyWorkers returns the workers which are crash looping.
+func (m *workerMetrics) unhealthyWorkers() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	unhealthy := make([]string, 0)
+
+	for key, failures := range m.failures {
+		if failures >= workerUnhealthyFailures {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s failed %d times in a row", key.worker, failures))
+		}
+	}
+
+	sort.Strings(unhealthy)
+
+	return unhealthy
+}
+
+// writePrometheus writes the metrics in the Prometheus text format.
+func (m *workerMetrics) writePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+
+	writeMetricHeader(&b, "shopware_worker_running", "gauge", "Workers currently running per pool.")
+	for _, pool := range sortedKeys(m.running) {
+		fmt.Fprintf(&b, "shopware_worker_running{pool=%s} %d\n", quoteLabel(pool), m.running[pool])
+	}
+
+	writeMetricHeader(&b, "shopware_worker_restarts_total", "counter", "Restarts of a worker after its consumer exited.")
+	for _, key := range sortedWorkerKeys(m.restarts) {
+		fmt.Fprintf(&b, "shopware_worker_restarts_total{pool=%s,worker=%s} %d\n", quoteLabel(key.pool), quoteLabel(key.worker), m.restarts[key])
+	}
+
+	writeMetricHeader(&b, "shopware_worker_exits_total", "counter", "Exits of the consumers per pool and exit code.")
+	exits := make([]workerExitKey, 0, len(m.exits))
+	for key := range m.exits {
+		exits = append(exits, key)
+	}
+	sort.Slice(exits, func(i, j int) bool {
+		if exits[i].pool != exits[j].pool {
+			return exits[i].pool < exits[j].pool
+		}
+
+		return exits[i].code < exits[j].code
+	})
+	for _, key := range exits {
+		fmt.Fprintf(&b, "shopware_worker_exits_total{pool=%s,code=\"%d\"} %d\n", quoteLabel(key.pool), key.code, m.exits[key])
+	}
+
+	writeMetricHeader(&b, "shopware_worker_last_success_timestamp_seconds", "gauge", "Unix time of the last successful exit of a worker.")
+	for _, key := range sortedWorkerKeys(m.lastSuccess) {
+		fmt.Fprintf(&b, "shopware_worker_last_success_timestamp_seconds{pool=%s,worker=%s} %d\n", quoteLabel(key.pool), quoteLabel(key.worker), m.lastSuccess[key].Unix())
+	}
+
+	writeMetricHeader(&b, "shopware_worker_consecutive_failures", "gauge", "Failures of a worker in a row.")
+	for _, key := range sortedWorkerKeys(m.failures) {
+		fmt.Fprintf(&b, "shopware_worker_consecutive_failures{pool=%s,worker=%s} %d\n", quoteLabel(key.pool), quoteLabel(key.worker), m.failures[key])
+	}
+
+	writeMetricHeader(&b, "shopware_worker_queue_depth", "gauge", "Messages waiting in a transport.")
+	for _, transport := range sortedKeys(m.queueDepth) {
+		fmt.Fprintf(&b, "shopware_worker_queue_depth{transport=%s} %d\n", quoteLabel(transport), m.queueDepth[transport])
+	}
+
+	writeMetricHeader(&b, "shopware_scheduled_task_overdue_seconds", "gauge", "Seconds a scheduled task is past its next execution time.")
+	for _, task := range sortedKeys(m.overdue) {
+		fmt.Fprintf(&b, "shopware_scheduled_task_overdue_seconds{task=%s} %d\n", quoteLabel(task), m.overdue[task])
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeMetricHeader(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quoteLabel(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedWorkerKeys[V any](m map[workerMetricKey]V) []workerMetricKey {
+	keys := make([]workerMetricKey, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pool != keys[j].pool {
+			return keys[i].pool < keys[j].pool
+		}
+
+		return keys[i].worker < keys[j].worker
+	})
+
+	return keys
+}
+
+func (m *workerMetrics) handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if unhealthy := m.unhealthyWorkers(); len(unhealthy) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = io.WriteString(w, strings.Join(unhealthy, "\n")+"\n")
+
+			return
+		}
+
+		_, _ = io.WriteString(w, "ok\n")
+	})
+
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = m.writePrometheus(w)
+	})
+
+	return mux
+}
+
+// serveWorkerMetrics serves /healthz and /metrics on the address until the context is canceled.
+func serveWorkerMetrics(ctx context.Context, address string, metrics *workerMetrics) {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           metrics.handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	go func() {
+		logging.FromContext(ctx).Infof("Serving worker health and metrics on %s", address)
+
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.FromContext(ctx).Errorf("Worker metrics listener failed: %s", err.Error())
+		}
+	}()
+}
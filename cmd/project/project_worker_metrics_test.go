@@ -0,0 +1,87 @@
+package project
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerBackoff(t *testing.T) {
+	backoff := workerBackoff{}
+
+	assert.Equal(t, time.Duration(0), backoff.Next(0, time.Second))
+	assert.Equal(t, time.Second, backoff.Next(1, time.Second))
+	assert.Equal(t, 2*time.Second, backoff.Next(1, time.Second))
+	assert.Equal(t, 4*time.Second, backoff.Next(255, time.Second))
+	assert.Equal(t, 3, backoff.failures)
+
+	for range 10 {
+		backoff.Next(1, time.Second)
+	}
+
+	assert.Equal(t, workerBackoffMax, backoff.Next(1, time.Second))
+
+	// A worker running stable before crashing starts with the initial delay again
+	assert.Equal(t, time.Second, backoff.Next(1, 2*time.Minute))
+
+	assert.Equal(t, time.Duration(0), backoff.Next(0, time.Second))
+	assert.Equal(t, 0, backoff.failures)
+}
+
+func TestWorkerMetrics(t *testing.T) {
+	metrics := newWorkerMetrics()
+
+	metrics.workerStarted("default")
+	metrics.workerStarted("default")
+	metrics.workerStopped("default")
+	metrics.workerExited("default", "worker-0", 1, 1)
+	metrics.workerRestarted("default", "worker-0")
+	metrics.workerExited("default", "worker-1", 0, 0)
+	metrics.setQueueDepth(map[string]int{"async": 12, "failed": 0})
+
+	var output strings.Builder
+	require.NoError(t, metrics.writePrometheus(&output))
+
+	assert.Contains(t, output.String(), "# TYPE shopware_worker_running gauge\nshopware_worker_running{pool=\"default\"} 1\n")
+	assert.Contains(t, output.String(), "shopware_worker_restarts_total{pool=\"default\",worker=\"worker-0\"} 1\n")
+	assert.Contains(t, output.String(), "shopware_worker_exits_total{pool=\"default\",code=\"0\"} 1\nshopware_worker_exits_total{pool=\"default\",code=\"1\"} 1\n")
+	assert.Contains(t, output.String(), "shopware_worker_last_success_timestamp_seconds{pool=\"default\",worker=\"worker-1\"}")
+	assert.NotContains(t, output.String(), "shopware_worker_last_success_timestamp_seconds{pool=\"default\",worker=\"worker-0\"}")
+	assert.Contains(t, output.String(), "shopware_worker_queue_depth{transport=\"async\"} 12\nshopware_worker_queue_depth{transport=\"failed\"} 0\n")
+
+	assert.Equal(t, `"a\"b\\c"`, quoteLabel(`a"b\c`))
+}
+
+func TestWorkerHealth(t *testing.T) {
+	metrics := newWorkerMetrics()
+	handler := metrics.handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "ok\n", recorder.Body.String())
+
+	metrics.workerExited("default", "worker-0", 1, workerUnhealthyFailures)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "worker-0 failed 5 times in a row\n", recorder.Body.String())
+
+	// Workers stopped by the supervisor do not affect the health anymore
+	metrics.workerRemoved("default", "worker-0")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "# TYPE shopware_worker_restarts_total counter")
+}
@@ -0,0 +1,408 @@
+package project
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shopware/shopware-cli/shop"
+)
+
+const (
+	workerDepthSourceMessenger  = "messenger_messages"
+	workerDepthSourceQueueStats = "message_queue_stats"
+
+	// failedTransport keeps the failed messages, they are not consumed by the workers
+	failedTransport = "failed"
+
+	defaultWorkerPollInterval      = 10
+	defaultWorkerMessagesPerWorker = 100
+	defaultWorkerScaleDownDelay    = 60
+	defaultSchedulerOverdueAfter   = 600
+)
+
+// resolveWorkerPools returns the worker pools to supervise. Pools are taken from the project config, --max-workers
+// autoscales the given transports. Without pools, or when an amount is given, a pool with the fixed amount of workers is started.
+func resolveWorkerPools(cmd *cobra.Command, transports []string, amount int, fixedAmount bool) (shop.ConfigWorker, error) {
+	cfg, err := shop.ReadConfig(projectConfigPath, true)
+	if err != nil {
+		return shop.ConfigWorker{}, err
+	}
+
+	worker := shop.ConfigWorker{}
+	if cfg.Worker != nil {
+		worker = *cfg.Worker
+	}
+
+	minWorkers, _ := cmd.Flags().GetInt("min-workers")
+	maxWorkers, _ := cmd.Flags().GetInt("max-workers")
+
+	switch {
+	case fixedAmount || (len(worker.Pools) == 0 && maxWorkers == 0):
+		worker.Pools = []shop.ConfigWorkerPool{{Name: "default", Transports: transports, MinWorkers: amount, MaxWorkers: amount}}
+	case len(worker.Pools) == 0:
+		worker.Pools = []shop.ConfigWorkerPool{{Name: "default", Transports: transports, MinWorkers: minWorkers, MaxWorkers: maxWorkers}}
+	}
+
+	if cmd.Flags().Changed("with-scheduler") {
+		worker.Scheduler, _ = cmd.Flags().GetBool("with-scheduler")
+	}
+
+	if cmd.Flags().Changed("metrics-listen") {
+		worker.MetricsListen, _ = cmd.Flags().GetString("metrics-listen")
+	}
+
+	return normalizeWorkerConfig(worker)
+}
+
+// normalizeWorkerConfig applies the defaults and validates the pools.
+func normalizeWorkerConfig(worker shop.ConfigWorker) (shop.ConfigWorker, error) {
+	if worker.PollInterval <= 0 {
+		worker.PollInterval = defaultWorkerPollInterval
+	}
+
+	if worker.DepthSource == "" {
+		worker.DepthSource = workerDepthSourceMessenger
+	}
+
+	if worker.DepthSource != workerDepthSourceMessenger && worker.DepthSource != workerDepthSourceQueueStats {
+		return shop.ConfigWorker{}, fmt.Errorf("unknown worker depth source %s, supported are %s and %s", worker.DepthSource, workerDepthSourceMessenger, workerDepthSourceQueueStats)
+	}
+
+	if worker.OverdueAfter <= 0 {
+		worker.OverdueAfter = defaultSchedulerOverdueAfter
+	}
+
+	pools := make([]shop.ConfigWorkerPool, 0, len(worker.Pools))
+	names := map[string]bool{schedulerPoolName: true}
+
+	for _, pool := range worker.Pools {
+		if pool.Name == "" || names[pool.Name] {
+			return shop.ConfigWorker{}, fmt.Errorf("worker pools need a unique name, got \"%s\"", pool.Name)
+		}
+
+		names[pool.Name] = true
+
+		pool.MinWorkers = max(pool.MinWorkers, 1)
+		pool.MaxWorkers = max(pool.MaxWorkers, pool.MinWorkers)
+
+		// Without transports the consumer asks for them, which is only possible with a fixed amount of workers
+		if len(pool.Transports) == 0 && pool.MaxWorkers > pool.MinWorkers {
+			return shop.ConfigWorker{}, fmt.Errorf("worker pool %s has no transports", pool.Name)
+		}
+
+		if pool.MessagesPerWorker <= 0 {
+			pool.MessagesPerWorker = defaultWorkerMessagesPerWorker
+		}
+
+		if pool.ScaleDownDelay <= 0 {
+			pool.ScaleDownDelay = defaultWorkerScaleDownDelay
+		}
+
+		pools = append(pools, pool)
+	}
+
+	worker.Pools = pools
+
+	return worker, nil
+}
+
+// workerScaler calculates the amount of workers of a pool. It adds workers as soon as the queue grows,
+// but removes them one at a time only after the queue stayed low for the scale down delay, so the pool does not flap.
+type workerScaler struct {
+	minWorkers        int
+	maxWorkers        int
+	messagesPerWorker int
+	scaleDownDelay    time.Duration
+	lowSince          time.Time
+}
+
+func newWorkerScaler(pool shop.ConfigWorkerPool) *workerScaler {
+	return &workerScaler{
+		minWorkers:        pool.MinWorkers,
+		maxWorkers:        pool.MaxWorkers,
+		messagesPerWorker: pool.MessagesPerWorker,
+		scaleDownDelay:    time.Duration(pool.ScaleDownDelay) * time.Second,
+	}
+}
+
+func (s *workerScaler) Desired(current, depth int, now time.Time) int {
+	desired := (depth + s.messagesPerWorker - 1) / s.messagesPerWorker
+	desired = min(max(desired, s.minWorkers), s.maxWorkers)
+
+	if desired >= current {
+		s.lowSince = time.Time{}
+
+		return desired
+	}
+
+	if s.lowSince.IsZero() {
+		s.lowSince = now
+
+		return current
+	}
+
+	if now.Sub(s.lowSince) < s.scaleDownDelay {
+		return current
+	}
+
+	// The next worker is stopped after another delay
+	s.lowSince = now
+
+	return current - 1
+}
+
+// queueDepthSource returns the amount of messages waiting per transport.
+type queueDepthSource func(ctx context.Context, transports []string) (map[string]int, error)
+
+// messengerQueueNames returns the queue names used by the Doctrine transports of Shopware, async uses the default queue.
+func messengerQueueNames(transports []string) map[string]string {
+	queues := make(map[string]string, len(transports))
+
+	for _, transport := range transports {
+		queue := transport
+		if transport == "async" {
+			queue = "default"
+		}
+
+		queues[queue] = transport
+	}
+
+	return queues
+}
+
+func messengerQueueDepth(db *sql.DB) queueDepthSource {
+	return func(ctx context.Context, transports []string) (map[string]int, error) {
+		queues := messengerQueueNames(transports)
+		depths := make(map[string]int, len(transports))
+		args := make([]interface{}, 0, len(queues))
+
+		for queue, transport := range queues {
+			args = append(args, queue)
+			depths[transport] = 0
+		}
+
+		query := fmt.Sprintf("SELECT queue_name, COUNT(*) FROM messenger_messages WHERE delivered_at IS NULL AND available_at <= UTC_TIMESTAMP() AND queue_name IN (%s) GROUP BY queue_name", strings.TrimSuffix(strings.Repeat("?,", len(queues)), ","))
+
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the queue depth from messenger_messages: %w", err)
+		}
+
+		defer rows.Close()
+
+		for rows.Next() {
+			var queue string
+			var depth int
+
+			if err := rows.Scan(&queue, &depth); err != nil {
+				return nil, fmt.Errorf("cannot read the queue depth from messenger_messages: %w", err)
+			}
+
+			depths[queues[queue]] = depth
+		}
+
+		return depths, rows.Err()
+	}
+}
+
+// messageQueueStatsDepth returns the size of all queues as transport "all", as the statistics of Shopware are not separated by transport.
+func messageQueueStatsDepth(db *sql.DB) queueDepthSource {
+	return func(ctx context.Context, _ []string) (map[string]int, error) {
+		var depth int
+		if err := db.QueryRowContext(ctx, "SELECT COALESCE(SUM(size), 0) FROM message_queue_stats").Scan(&depth); err != nil {
+			return nil, fmt.Errorf("cannot read the queue depth from message_queue_stats: %w", err)
+		}
+
+		return map[string]int{"all": depth}, nil
+	}
+}
+
+// sumQueueDepth returns the amount of messages to scale by, failed messages stay in the queue until they are retried manually.
+func sumQueueDepth(depths map[string]int) int {
+	sum := 0
+	for transport, depth := range depths {
+		if transport == failedTransport {
+			continue
+		}
+
+		sum += depth
+	}
+
+	return sum
+}
+
+type workerPool struct {
+	config      shop.ConfigWorkerPool
+	consumeArgs []string
+	scaler      *workerScaler
+	metrics     *workerMetrics
+	workers     []context.CancelFunc
+	started     int
+	wg          sync.WaitGroup
+}
+
+// scale starts or stops workers until the desired amount is running, the newest workers are stopped first.
+func (p *workerPool) scale(ctx context.Context, options workerOptions, baseName string, desired int) {
+	for len(p.workers) < desired {
+		workerCtx, cancel := context.WithCancel(ctx)
+		consumerName := fmt.Sprintf("%s-%s-%d", baseName, p.config.Name, p.started)
+
+		p.workers = append(p.workers, cancel)
+		p.started++
+		p.wg.Add(1)
+
+		go func() {
+			defer p.wg.Done()
+
+			runWorker(workerCtx, options, p.consumeArgs, p.config.Name, consumerName, p.metrics)
+		}()
+	}
+
+	for len(p.workers) > desired {
+		p.workers[len(p.workers)-1]()
+		p.workers = p.workers[:len(p.workers)-1]
+	}
+}
+
+// superviseWorkerPools runs the workers of all pools and scales the pools with a max_workers above min_workers by their queue depth.
+// With the scheduler enabled, scheduled-task:run is supervised like a pool with a single worker.
+func superviseWorkerPools(ctx context.Context, worker shop.ConfigWorker, options workerOptions, baseName string) {
+	metrics := newWorkerMetrics()
+	pools := make([]*workerPool, 0, len(worker.Pools)+1)
+	autoscale := false
+
+	for _, config := range worker.Pools {
+		pools = append(pools, &workerPool{
+			config:      config,
+			consumeArgs: options.consumeArgs(config.Transports, config.MemoryLimit, config.TimeLimit),
+			scaler:      newWorkerScaler(config),
+			metrics:     metrics,
+		})
+
+		autoscale = autoscale || config.MaxWorkers > config.MinWorkers
+	}
+
+	if worker.Scheduler {
+		config := shop.ConfigWorkerPool{Name: schedulerPoolName, MinWorkers: 1, MaxWorkers: 1}
+
+		pools = append(pools, &workerPool{
+			config:      config,
+			consumeArgs: options.schedulerArgs(),
+			scaler:      newWorkerScaler(config),
+			metrics:     metrics,
+		})
+	}
+
+	if worker.MetricsListen != "" {
+		serveWorkerMetrics(ctx, worker.MetricsListen, metrics)
+	}
+
+	for _, pool := range pools {
+		if pool.config.Name == schedulerPoolName {
+			logging.FromContext(ctx).Infof("Starting the scheduled task runner")
+		} else {
+			logging.FromContext(ctx).Infof("Starting worker pool %s with %d workers consuming %s", pool.config.Name, pool.config.MinWorkers, strings.Join(pool.config.Transports, ", "))
+		}
+
+		pool.scale(ctx, options, baseName, pool.config.MinWorkers)
+	}
+
+	var polling <-chan struct{}
+
+	// The database is also polled for the metrics and overdue scheduled tasks, when no pool is autoscaled
+	if autoscale || worker.MetricsListen != "" || worker.Scheduler {
+		done, err := pollWorkerPools(ctx, worker, pools, metrics, options, baseName)
+		if err != nil {
+			logging.FromContext(ctx).Errorf("Cannot connect to the database, autoscaling and the queue monitoring are disabled: %s", err.Error())
+		}
+
+		polling = done
+	}
+
+	<-ctx.Done()
+
+	// The poller scales the pools, so it has to stop before the workers are awaited
+	if polling != nil {
+		<-polling
+	}
+
+	for _, pool := range pools {
+		pool.wg.Wait()
+	}
+}
+
+// pollWorkerPools reads the queue depth of the pools periodically and scales the autoscaled pools.
+// With the scheduler enabled, overdue scheduled tasks are reported as well. The returned channel is closed once polling stopped.
+func pollWorkerPools(ctx context.Context, worker shop.ConfigWorker, pools []*workerPool, metrics *workerMetrics, options workerOptions, baseName string) (<-chan struct{}, error) {
+	connection := defaultConnectionConfig()
+
+	if err := loadDatabaseURLIntoConnection(ctx, options.projectRoot, connection); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("mysql", connection.FormatDSN())
+	if err != nil {
+		return nil, err
+	}
+
+	depth := messengerQueueDepth(db)
+	if worker.DepthSource == workerDepthSourceQueueStats {
+		depth = messageQueueStatsDepth(db)
+	}
+
+	overdue := newOverdueTaskReporter(time.Duration(worker.OverdueAfter) * time.Second)
+	ticker := time.NewTicker(time.Duration(worker.PollInterval) * time.Second)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		defer db.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if worker.Scheduler {
+					overdue.check(ctx, db, metrics)
+				}
+
+				for _, pool := range pools {
+					if len(pool.config.Transports) == 0 {
+						continue
+					}
+
+					depths, err := depth(ctx, pool.config.Transports)
+					if err != nil {
+						logging.FromContext(ctx).Warnf("Keeping %d workers of pool %s: %s", len(pool.workers), pool.config.Name, err.Error())
+						continue
+					}
+
+					metrics.setQueueDepth(depths)
+
+					if pool.config.MaxWorkers == pool.config.MinWorkers {
+						continue
+					}
+
+					queued := sumQueueDepth(depths)
+					desired := pool.scaler.Desired(len(pool.workers), queued, now)
+
+					if desired != len(pool.workers) {
+						logging.FromContext(ctx).Infof("Scaling worker pool %s from %d to %d workers, %d messages are queued", pool.config.Name, len(pool.workers), desired, queued)
+						pool.scale(ctx, options, baseName, desired)
+					}
+				}
+			}
+		}
+	}()
+
+	return done, nil
+}
@@ -0,0 +1,72 @@
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/shop"
+)
+
+func TestWorkerScaler(t *testing.T) {
+	scaler := newWorkerScaler(shop.ConfigWorkerPool{MinWorkers: 1, MaxWorkers: 4, MessagesPerWorker: 100, ScaleDownDelay: 60})
+	now := time.Now()
+
+	assert.Equal(t, 1, scaler.Desired(1, 0, now))
+	assert.Equal(t, 3, scaler.Desired(1, 250, now))
+	assert.Equal(t, 4, scaler.Desired(3, 5000, now))
+
+	// The queue has to stay low for the delay before a worker is stopped
+	assert.Equal(t, 4, scaler.Desired(4, 10, now.Add(10*time.Second)))
+	assert.Equal(t, 4, scaler.Desired(4, 10, now.Add(60*time.Second)))
+	assert.Equal(t, 3, scaler.Desired(4, 10, now.Add(71*time.Second)))
+	assert.Equal(t, 3, scaler.Desired(3, 10, now.Add(100*time.Second)))
+
+	// A growing queue resets the delay
+	assert.Equal(t, 3, scaler.Desired(3, 300, now.Add(110*time.Second)))
+	assert.Equal(t, 3, scaler.Desired(3, 10, now.Add(120*time.Second)))
+	assert.Equal(t, 3, scaler.Desired(3, 10, now.Add(170*time.Second)))
+	assert.Equal(t, 2, scaler.Desired(3, 10, now.Add(180*time.Second)))
+}
+
+func TestNormalizeWorkerConfig(t *testing.T) {
+	worker, err := normalizeWorkerConfig(shop.ConfigWorker{Pools: []shop.ConfigWorkerPool{
+		{Name: "async", Transports: []string{"async"}, MaxWorkers: 4},
+		{Name: "failed", Transports: []string{"failed"}, MinWorkers: 2},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultWorkerPollInterval, worker.PollInterval)
+	assert.Equal(t, workerDepthSourceMessenger, worker.DepthSource)
+	assert.Equal(t, shop.ConfigWorkerPool{Name: "async", Transports: []string{"async"}, MinWorkers: 1, MaxWorkers: 4, MessagesPerWorker: 100, ScaleDownDelay: 60}, worker.Pools[0])
+	assert.Equal(t, 2, worker.Pools[1].MaxWorkers)
+
+	_, err = normalizeWorkerConfig(shop.ConfigWorker{Pools: []shop.ConfigWorkerPool{{Name: "async", Transports: []string{"async"}}, {Name: "async", Transports: []string{"failed"}}}})
+	assert.ErrorContains(t, err, "unique name")
+
+	_, err = normalizeWorkerConfig(shop.ConfigWorker{Pools: []shop.ConfigWorkerPool{{Name: "async", MaxWorkers: 2}}})
+	assert.ErrorContains(t, err, "worker pool async has no transports")
+
+	// A fixed amount of workers can consume the transports chosen by the consumer
+	_, err = normalizeWorkerConfig(shop.ConfigWorker{Pools: []shop.ConfigWorkerPool{{Name: "default", MinWorkers: 2, MaxWorkers: 2}}})
+	assert.NoError(t, err)
+
+	_, err = normalizeWorkerConfig(shop.ConfigWorker{DepthSource: "redis"})
+	assert.ErrorContains(t, err, "unknown worker depth source redis")
+}
+
+func TestWorkerConsumeArgs(t *testing.T) {
+	options := workerOptions{memoryLimit: "512M", timeLimit: "120", messagesLimit: 10}
+
+	assert.Equal(t, []string{"messenger:consume", "--memory-limit=1G", "--time-limit=120", "--failure-limit=5", "--limit=10", "async", "low_priority"}, options.consumeArgs([]string{"async", "low_priority"}, "1G", ""))
+	assert.Equal(t, map[string]string{"default": "async", "low_priority": "low_priority", "failed": "failed"}, messengerQueueNames([]string{"async", "low_priority", "failed"}))
+	assert.Equal(t, 10, sumQueueDepth(map[string]int{"async": 10, "failed": 5}))
+	assert.Equal(t, 15, sumQueueDepth(map[string]int{"async": 10, "low_priority": 5}))
+}
+
+func TestNormalizeWorkerConfigReservesSchedulerPool(t *testing.T) {
+	_, err := normalizeWorkerConfig(shop.ConfigWorker{Pools: []shop.ConfigWorkerPool{{Name: schedulerPoolName, Transports: []string{"async"}}}})
+	assert.ErrorContains(t, err, "unique name")
+}
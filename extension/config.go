@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/dustin/go-humanize"
 	"gopkg.in/yaml.v3"
 
 	"github.com/shopware/shopware-cli/internal/changelog"
@@ -31,6 +32,23 @@ type ConfigBuildZip struct {
 	Pack ConfigBuildZipPack `yaml:"pack,omitempty"`
 
 	Checksum ConfigBuildZipChecksum `yaml:"checksum,omitempty"`
+	// Size limits for the created zip file
+	SizeBudget ConfigBuildZipSizeBudget `yaml:"size_budget,omitempty"`
+}
+
+// Configuration for size limits of the zip file.
+type ConfigBuildZipSizeBudget struct {
+	// Maximum size of the zip file, e.g. 5MB or 512KiB
+	Max string `yaml:"max,omitempty"`
+	// Maximum compressed sizes of folders inside the zip
+	Paths []ConfigBuildZipSizeBudgetPath `yaml:"paths,omitempty"`
+}
+
+type ConfigBuildZipSizeBudgetPath struct {
+	// Path to the folder, relative from the extension root
+	Path string `yaml:"path"`
+	// Maximum compressed size of the folder, e.g. 1MB
+	Max string `yaml:"max"`
 }
 
 // Configuration for checksum calculation.
@@ -245,6 +263,22 @@ func validateExtensionConfig(config *Config) error {
 		return fmt.Errorf("store.info.videos.de can contain maximal 2 items")
 	}
 
+	if config.Build.Zip.SizeBudget.Max != "" {
+		if _, err := humanize.ParseBytes(config.Build.Zip.SizeBudget.Max); err != nil {
+			return fmt.Errorf("build.zip.size_budget.max: %w", err)
+		}
+	}
+
+	for _, budget := range config.Build.Zip.SizeBudget.Paths {
+		if budget.Path == "" {
+			return fmt.Errorf("build.zip.size_budget.paths: path cannot be empty")
+		}
+
+		if _, err := humanize.ParseBytes(budget.Max); err != nil {
+			return fmt.Errorf("build.zip.size_budget.paths %s: %w", budget.Path, err)
+		}
+	}
+
 	return nil
 }
 
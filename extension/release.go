@@ -0,0 +1,147 @@
+package extension
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shyim/go-version"
+
+	"github.com/shopware/shopware-cli/internal/changelog"
+)
+
+var manifestVersionRegex = regexp.MustCompile(`(<version>)([^<]*)(</version>)`)
+
+// BumpVersion returns the next version for the given bump type, dropping any pre-release suffix.
+func BumpVersion(current *version.Version, bump string) (*version.Version, error) {
+	major, minor, patch := current.Major(), current.Minor(), current.Patch()
+
+	switch bump {
+	case changelog.BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case changelog.BumpMinor:
+		minor, patch = minor+1, 0
+	case changelog.BumpPatch:
+		patch++
+	default:
+		return nil, fmt.Errorf("unknown version bump %q, expected patch, minor or major", bump)
+	}
+
+	return version.NewVersion(fmt.Sprintf("%d.%d.%d", major, minor, patch))
+}
+
+// UpdateExtensionVersion writes the version into the composer.json or manifest.xml of the extension, keeping the formatting of the file.
+func UpdateExtensionVersion(ext Extension, newVersion *version.Version) error {
+	fileName := "composer.json"
+	locateVersion := composerVersionLocation
+
+	if ext.GetType() == TypePlatformApp {
+		fileName = "manifest.xml"
+		locateVersion = manifestVersionLocation
+	}
+
+	filePath := filepath.Join(ext.GetPath(), fileName)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", fileName, err)
+	}
+
+	start, end, err := locateVersion(content)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	updated := make([]byte, 0, len(content))
+	updated = append(updated, content[:start]...)
+	updated = append(updated, newVersion.String()...)
+	updated = append(updated, content[end:]...)
+
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, updated, stat.Mode())
+}
+
+// composerVersionLocation returns the byte range of the top-level version of the composer.json,
+// nested versions like the ones of packages in repositories are skipped.
+func composerVersionLocation(content []byte) (int, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return 0, 0, fmt.Errorf("expected a JSON object")
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if key != "version" {
+			var value json.RawMessage
+			if err := decoder.Decode(&value); err != nil {
+				return 0, 0, err
+			}
+
+			continue
+		}
+
+		// The offset is directly after the key, the value follows after the colon
+		afterKey := decoder.InputOffset()
+
+		value, err := decoder.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if _, ok := value.(string); !ok {
+			return 0, 0, fmt.Errorf("version is not a string")
+		}
+
+		end := int(decoder.InputOffset())
+		start := int(afterKey) + bytes.IndexByte(content[afterKey:end], '"') + 1
+
+		return start, end - 1, nil
+	}
+
+	return 0, 0, fmt.Errorf("does not contain a version")
+}
+
+// manifestVersionLocation returns the byte range of the version of the manifest.xml, which is the first version element in its meta section.
+func manifestVersionLocation(content []byte) (int, int, error) {
+	loc := manifestVersionRegex.FindSubmatchIndex(content)
+	if loc == nil {
+		return 0, 0, fmt.Errorf("does not contain a version")
+	}
+
+	return loc[4], loc[5], nil
+}
+
+// PrependChangelog adds a new version section at the top of the CHANGELOG_<language>.md of the extension.
+func PrependChangelog(ext Extension, language, newVersion, content string) (string, error) {
+	filePath := filepath.Join(ext.GetPath(), fmt.Sprintf("CHANGELOG_%s.md", language))
+
+	existing, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("read changelog: %w", err)
+	}
+
+	entry := fmt.Sprintf("# %s\n\n%s\n", newVersion, strings.TrimSpace(content))
+
+	if len(strings.TrimSpace(string(existing))) > 0 {
+		entry += "\n" + string(existing)
+	}
+
+	if err := os.WriteFile(filePath, []byte(entry), 0o644); err != nil {
+		return "", fmt.Errorf("write changelog: %w", err)
+	}
+
+	return filePath, nil
+}
@@ -0,0 +1,117 @@
+package extension
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shyim/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpVersion(t *testing.T) {
+	current := version.Must(version.NewVersion("1.2.3-beta"))
+
+	patch, err := BumpVersion(current, "patch")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.4", patch.String())
+
+	minor, err := BumpVersion(current, "minor")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", minor.String())
+
+	major, err := BumpVersion(current, "major")
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", major.String())
+
+	_, err = BumpVersion(current, "huge")
+	assert.Error(t, err)
+}
+
+func TestUpdateExtensionVersionApp(t *testing.T) {
+	appPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(appPath, "manifest.xml"), []byte(testAppManifest), os.ModePerm))
+
+	app, err := newApp(appPath)
+	require.NoError(t, err)
+
+	require.NoError(t, UpdateExtensionVersion(app, version.Must(version.NewVersion("1.1.0"))))
+
+	content, err := os.ReadFile(filepath.Join(appPath, "manifest.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<version>1.1.0</version>")
+	assert.Contains(t, string(content), `xsi:noNamespaceSchemaLocation="https://raw.githubusercontent.com`)
+}
+
+func TestUpdateExtensionVersionPlugin(t *testing.T) {
+	pluginPath := t.TempDir()
+	composerJSON := `{
+    "name": "frosh/tools",
+    "version": "1.0.0",
+    "require": {
+        "shopware/core": "~6.6.0"
+    }
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginPath, "composer.json"), []byte(composerJSON), os.ModePerm))
+
+	plugin := getTestPlugin(pluginPath)
+
+	require.NoError(t, UpdateExtensionVersion(plugin, version.Must(version.NewVersion("2.0.0"))))
+
+	content, err := os.ReadFile(filepath.Join(pluginPath, "composer.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `    "version": "2.0.0",`)
+	assert.Contains(t, string(content), `"shopware/core": "~6.6.0"`)
+}
+
+func TestUpdateExtensionVersionPluginWithNestedVersions(t *testing.T) {
+	pluginPath := t.TempDir()
+	composerJSON := `{
+    "name": "frosh/tools",
+    "repositories": [
+        {
+            "type": "package",
+            "package": {"name": "vendor/lib", "version": "0.1.0"}
+        }
+    ],
+    "extra": {"version": "legacy"},
+    "version" : "1.0.0"
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pluginPath, "composer.json"), []byte(composerJSON), os.ModePerm))
+
+	plugin := getTestPlugin(pluginPath)
+
+	require.NoError(t, UpdateExtensionVersion(plugin, version.Must(version.NewVersion("1.1.0"))))
+
+	content, err := os.ReadFile(filepath.Join(pluginPath, "composer.json"))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Replace(composerJSON, `"version" : "1.0.0"`, `"version" : "1.1.0"`, 1), string(content))
+
+	require.NoError(t, os.WriteFile(filepath.Join(pluginPath, "composer.json"), []byte(`{"extra": {"version": "1.0.0"}}`), os.ModePerm))
+	assert.ErrorContains(t, UpdateExtensionVersion(plugin, version.Must(version.NewVersion("1.1.0"))), "does not contain a version")
+}
+
+func TestPrependChangelog(t *testing.T) {
+	pluginPath := t.TempDir()
+	plugin := getTestPlugin(pluginPath)
+
+	require.NoError(t, os.WriteFile(filepath.Join(pluginPath, "CHANGELOG_en-GB.md"), []byte("# 1.0.0\n\n- Initial release\n"), os.ModePerm))
+
+	file, err := PrependChangelog(plugin, "en-GB", "1.1.0", "- New feature\n")
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "# 1.1.0\n\n- New feature\n\n# 1.0.0\n\n- Initial release\n", string(content))
+
+	file, err = PrependChangelog(plugin, "de-DE", "1.1.0", "- New feature")
+	require.NoError(t, err)
+
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "# 1.1.0\n\n- New feature\n", string(content))
+}
@@ -0,0 +1,308 @@
+package extension
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	SignatureAlgorithmEd25519 = "ed25519"
+
+	// SigningKeyEnv can contain the private key used to sign extension zips
+	SigningKeyEnv = "SHOPWARE_CLI_SIGNING_KEY"
+	// VerifyKeyEnv can contain the public key used to verify extension zips
+	VerifyKeyEnv = "SHOPWARE_CLI_SIGNING_PUBLIC_KEY"
+
+	signatureMessageFormat = "shopware-extension-signature:v1\nzip:%s\nchecksum:%s\n"
+)
+
+var ErrSignatureInvalid = errors.New("signature does not match")
+
+// Signature is a detached signature over an extension zip and its checksum.json.
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	KeyID     string `json:"keyId"`
+	// SHA256 of the zip file
+	ZipHash string `json:"zipHash"`
+	// SHA256 of the checksum.json inside the zip, empty when the zip has none
+	ChecksumHash string `json:"checksumHash"`
+	Signature    string `json:"signature"`
+}
+
+// SignatureFileName returns the default location of the detached signature of a zip file.
+func SignatureFileName(zipFile string) string {
+	return zipFile + ".sig"
+}
+
+// SignZip creates a detached signature for the given zip file.
+func SignZip(zipFile string, key ed25519.PrivateKey) (*Signature, error) {
+	zipHash, checksumHash, err := hashSignedZip(zipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("cannot derive public key")
+	}
+
+	signature := ed25519.Sign(key, []byte(fmt.Sprintf(signatureMessageFormat, zipHash, checksumHash)))
+
+	return &Signature{
+		Algorithm:    SignatureAlgorithmEd25519,
+		KeyID:        SigningKeyID(publicKey),
+		ZipHash:      zipHash,
+		ChecksumHash: checksumHash,
+		Signature:    base64.StdEncoding.EncodeToString(signature),
+	}, nil
+}
+
+// VerifyZipSignature checks that the signature was created with the given key for exactly this zip file.
+func VerifyZipSignature(zipFile string, signature *Signature, key ed25519.PublicKey) error {
+	if signature.Algorithm != SignatureAlgorithmEd25519 {
+		return fmt.Errorf("unsupported signature algorithm %q", signature.Algorithm)
+	}
+
+	if keyID := SigningKeyID(key); signature.KeyID != keyID {
+		return fmt.Errorf("%w: signed with key %s, but verifying with key %s", ErrSignatureInvalid, signature.KeyID, keyID)
+	}
+
+	zipHash, checksumHash, err := hashSignedZip(zipFile)
+	if err != nil {
+		return err
+	}
+
+	if zipHash != signature.ZipHash {
+		return fmt.Errorf("%w: zip file has been modified", ErrSignatureInvalid)
+	}
+
+	if checksumHash != signature.ChecksumHash {
+		return fmt.Errorf("%w: checksum.json has been modified", ErrSignatureInvalid)
+	}
+
+	rawSignature, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("decode signature: %w", err)
+	}
+
+	if !ed25519.Verify(key, []byte(fmt.Sprintf(signatureMessageFormat, zipHash, checksumHash)), rawSignature) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// hashSignedZip returns the SHA256 of the zip file and of the checksum.json contained in it.
+func hashSignedZip(zipFile string) (string, string, error) {
+	file, err := os.Open(zipFile)
+	if err != nil {
+		return "", "", fmt.Errorf("open zip file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	zipHasher := sha256.New()
+	if _, err := io.Copy(zipHasher, file); err != nil {
+		return "", "", fmt.Errorf("hash zip file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", "", fmt.Errorf("stat zip file: %w", err)
+	}
+
+	r, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return "", "", fmt.Errorf("open zip file: %w", err)
+	}
+
+	checksumHash := ""
+
+	for _, f := range r.File {
+		parts := strings.Split(f.Name, "/")
+		if len(parts) != 2 || parts[1] != "checksum.json" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return "", "", fmt.Errorf("open checksum.json: %w", err)
+		}
+
+		checksumHasher := sha256.New()
+		_, err = io.Copy(checksumHasher, rc) //nolint:gosec
+		_ = rc.Close()
+
+		if err != nil {
+			return "", "", fmt.Errorf("hash checksum.json: %w", err)
+		}
+
+		checksumHash = hex.EncodeToString(checksumHasher.Sum(nil))
+
+		break
+	}
+
+	return hex.EncodeToString(zipHasher.Sum(nil)), checksumHash, nil
+}
+
+// WriteSignature writes the signature as JSON to the given file.
+func WriteSignature(fileName string, signature *Signature) error {
+	content, err := json.MarshalIndent(signature, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal signature: %w", err)
+	}
+
+	return os.WriteFile(fileName, content, 0o644)
+}
+
+// ReadSignature reads a signature created by WriteSignature.
+func ReadSignature(fileName string) (*Signature, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read signature: %w", err)
+	}
+
+	var signature Signature
+	if err := json.Unmarshal(content, &signature); err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+
+	return &signature, nil
+}
+
+// SigningKeyID returns a short fingerprint of the public key.
+func SigningKeyID(key ed25519.PublicKey) string {
+	hash := sha256.Sum256(key)
+
+	return hex.EncodeToString(hash[:8])
+}
+
+// GenerateSigningKey creates a new key pair, both PEM encoded.
+func GenerateSigningKey() ([]byte, []byte, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	publicDer, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer}), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer}), nil
+}
+
+// LoadSigningKey reads the private key from the given file, or from SHOPWARE_CLI_SIGNING_KEY when no file is given.
+func LoadSigningKey(keyFile string) (ed25519.PrivateKey, error) {
+	content, err := readKeyContent(keyFile, SigningKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseSigningKey(content)
+}
+
+// LoadVerifyKey reads the public key from the given file, or from SHOPWARE_CLI_SIGNING_PUBLIC_KEY when no file is given.
+func LoadVerifyKey(keyFile string) (ed25519.PublicKey, error) {
+	content, err := readKeyContent(keyFile, VerifyKeyEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseVerifyKey(content)
+}
+
+func readKeyContent(keyFile, envName string) ([]byte, error) {
+	if keyFile != "" {
+		content, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read key file: %w", err)
+		}
+
+		return content, nil
+	}
+
+	if content := os.Getenv(envName); content != "" {
+		return []byte(content), nil
+	}
+
+	return nil, fmt.Errorf("no key given, pass a key file or set %s", envName)
+}
+
+// ParseSigningKey accepts a PEM encoded PKCS8 key or a base64 encoded ed25519 seed or private key.
+func ParseSigningKey(content []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(content); block != nil {
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse private key: %w", err)
+		}
+
+		privateKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private key is not an ed25519 key")
+		}
+
+		return privateKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("decode private key: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return raw, nil
+	}
+
+	return nil, fmt.Errorf("private key has invalid length %d", len(raw))
+}
+
+// ParseVerifyKey accepts a PEM encoded PKIX key or a base64 encoded ed25519 public key.
+func ParseVerifyKey(content []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(content); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %w", err)
+		}
+
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key is not an ed25519 key")
+		}
+
+		return publicKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key has invalid length %d", len(raw))
+	}
+
+	return raw, nil
+}
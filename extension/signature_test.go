@@ -0,0 +1,78 @@
+package extension
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerifyZip(t *testing.T) {
+	privatePem, publicPem, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	privateKey, err := ParseSigningKey(privatePem)
+	require.NoError(t, err)
+
+	publicKey, err := ParseVerifyKey(publicPem)
+	require.NoError(t, err)
+
+	zipPath := createReportTestZip(t, map[string]string{
+		"Test/composer.json": "{}",
+		"Test/checksum.json": `{"algorithm":"xxh128"}`,
+	})
+
+	signature, err := SignZip(zipPath, privateKey)
+	require.NoError(t, err)
+	assert.NotEmpty(t, signature.ChecksumHash)
+
+	sigPath := SignatureFileName(zipPath)
+	require.NoError(t, WriteSignature(sigPath, signature))
+
+	readSignature, err := ReadSignature(sigPath)
+	require.NoError(t, err)
+	assert.Equal(t, signature, readSignature)
+
+	assert.NoError(t, VerifyZipSignature(zipPath, readSignature, publicKey))
+
+	_, otherPublicPem, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	otherPublicKey, err := ParseVerifyKey(otherPublicPem)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, VerifyZipSignature(zipPath, readSignature, otherPublicKey), ErrSignatureInvalid)
+
+	tamperedZip := createReportTestZip(t, map[string]string{
+		"Test/composer.json": `{"name": "evil"}`,
+		"Test/checksum.json": `{"algorithm":"xxh128"}`,
+	})
+	require.NoError(t, os.Rename(tamperedZip, zipPath))
+
+	assert.ErrorIs(t, VerifyZipSignature(zipPath, readSignature, publicKey), ErrSignatureInvalid)
+}
+
+func TestLoadSigningKeyFromEnv(t *testing.T) {
+	privatePem, _, err := GenerateSigningKey()
+	require.NoError(t, err)
+
+	t.Setenv(SigningKeyEnv, string(privatePem))
+
+	key, err := LoadSigningKey("")
+	require.NoError(t, err)
+
+	seedKey, err := ParseSigningKey([]byte(base64.StdEncoding.EncodeToString(key.Seed())))
+	require.NoError(t, err)
+	assert.Equal(t, key, seedKey)
+
+	t.Setenv(SigningKeyEnv, "")
+
+	_, err = LoadSigningKey("")
+	assert.Error(t, err)
+
+	_, err = LoadSigningKey(filepath.Join(t.TempDir(), "missing.key"))
+	assert.Error(t, err)
+}
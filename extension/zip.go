@@ -313,9 +313,10 @@ func AddZipFiles(w *zip.Writer, basePath, baseInZip string) error {
 }
 
 func CleanupExtensionFolder(path string, additionalPaths []string) error {
-	defaultNotAllowedPaths = append(defaultNotAllowedPaths, additionalPaths...)
+	// Copy the defaults, as multiple extensions can be cleaned up concurrently
+	notAllowedPaths := append(slices.Clone(defaultNotAllowedPaths), additionalPaths...)
 
-	for _, folder := range defaultNotAllowedPaths {
+	for _, folder := range notAllowedPaths {
 		if _, err := os.Stat(path + folder); !os.IsNotExist(err) {
 			err := os.RemoveAll(path + folder)
 			if err != nil {
@@ -666,12 +667,19 @@ func PrepareExtensionForRelease(ctx context.Context, sourceRoot, extensionRoot s
 			return err
 		}
 
-		changelogFile := fmt.Sprintf("# %s\n%s", v.String(), content)
+		changelogs, err := changelog.TranslateChangelogs(ctx, content, ext.GetExtensionConfig().Changelog.Translation)
+		if err != nil {
+			return err
+		}
 
-		logging.FromContext(ctx).Debugf("Changelog:\n%s", changelogFile)
+		for _, language := range changelog.Languages {
+			changelogFile := fmt.Sprintf("# %s\n%s", v.String(), changelogs[language])
 
-		if err := os.WriteFile(path.Join(extensionRoot, "CHANGELOG_en-GB.md"), []byte(changelogFile), os.ModePerm); err != nil {
-			return err
+			logging.FromContext(ctx).Debugf("Changelog %s:\n%s", language, changelogFile)
+
+			if err := os.WriteFile(path.Join(extensionRoot, fmt.Sprintf("CHANGELOG_%s.md", language)), []byte(changelogFile), os.ModePerm); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -0,0 +1,257 @@
+package extension
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/shopware/shopware-cli/internal/table"
+	"github.com/shopware/shopware-cli/internal/validation"
+)
+
+const (
+	zipReportDirectoryDepth = 2
+	zipReportTopEntries     = 10
+)
+
+// ZipReportEntry contains the aggregated sizes of a file, folder or vendor package inside a zip.
+type ZipReportEntry struct {
+	Path             string
+	Files            int
+	CompressedSize   uint64
+	UncompressedSize uint64
+}
+
+// ZipReport contains the size breakdown of a created extension zip.
+type ZipReport struct {
+	// Size of the zip file on disk
+	Size             uint64
+	UncompressedSize uint64
+	// Aggregated sizes of all folders, relative to the extension root
+	Directories map[string]*ZipReportEntry
+	Files       []ZipReportEntry
+	// Aggregated sizes of all composer packages inside the vendor folder
+	VendorPackages map[string]*ZipReportEntry
+}
+
+// AnalyzeZip reads the given zip file and aggregates the sizes per folder, file and vendor package.
+func AnalyzeZip(zipFile string) (*ZipReport, error) {
+	stat, err := os.Stat(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("stat zip file: %w", err)
+	}
+
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return nil, fmt.Errorf("open zip file: %w", err)
+	}
+
+	defer func() {
+		_ = r.Close()
+	}()
+
+	report := &ZipReport{
+		Size:           uint64(stat.Size()),
+		Directories:    make(map[string]*ZipReportEntry),
+		VendorPackages: make(map[string]*ZipReportEntry),
+	}
+
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		// All files are placed inside a folder named after the extension
+		relPath := f.Name
+		if idx := strings.Index(relPath, "/"); idx != -1 {
+			relPath = relPath[idx+1:]
+		}
+
+		entry := ZipReportEntry{
+			Path:             relPath,
+			Files:            1,
+			CompressedSize:   f.CompressedSize64,
+			UncompressedSize: f.UncompressedSize64,
+		}
+
+		report.UncompressedSize += f.UncompressedSize64
+		report.Files = append(report.Files, entry)
+
+		for dir := path.Dir(relPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			report.Directories[dir] = addZipReportEntry(report.Directories[dir], dir, entry)
+		}
+
+		if packageName := getVendorPackageName(relPath); packageName != "" {
+			report.VendorPackages[packageName] = addZipReportEntry(report.VendorPackages[packageName], packageName, entry)
+		}
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].CompressedSize > report.Files[j].CompressedSize
+	})
+
+	return report, nil
+}
+
+func addZipReportEntry(aggregate *ZipReportEntry, name string, entry ZipReportEntry) *ZipReportEntry {
+	if aggregate == nil {
+		aggregate = &ZipReportEntry{Path: name}
+	}
+
+	aggregate.Files += entry.Files
+	aggregate.CompressedSize += entry.CompressedSize
+	aggregate.UncompressedSize += entry.UncompressedSize
+
+	return aggregate
+}
+
+// getVendorPackageName returns the composer package name (vendor/name) of a file inside the vendor folder.
+func getVendorPackageName(relPath string) string {
+	parts := strings.Split(relPath, "/")
+
+	if len(parts) < 4 || parts[0] != "vendor" {
+		return ""
+	}
+
+	return parts[1] + "/" + parts[2]
+}
+
+// DirectorySize returns the aggregated sizes of the given folder, relative to the extension root.
+func (r *ZipReport) DirectorySize(dir string) ZipReportEntry {
+	dir = strings.Trim(path.Clean(strings.ReplaceAll(dir, "\\", "/")), "/")
+
+	if entry, ok := r.Directories[dir]; ok {
+		return *entry
+	}
+
+	return ZipReportEntry{Path: dir}
+}
+
+// ValidateSizeBudget adds a validation error for the zip and each folder exceeding the configured size budget.
+func (r *ZipReport) ValidateSizeBudget(budget ConfigBuildZipSizeBudget, check validation.Check) {
+	if budget.Max != "" {
+		maxSize, err := humanize.ParseBytes(budget.Max)
+		if err != nil {
+			check.AddResult(validation.CheckResult{
+				Path:       ".shopware-extension.yml",
+				Identifier: "zip.size_budget",
+				Message:    fmt.Sprintf("Could not parse size budget %s: %s", budget.Max, err.Error()),
+				Severity:   validation.SeverityError,
+			})
+		} else if r.Size > maxSize {
+			check.AddResult(validation.CheckResult{
+				Path:       ".",
+				Identifier: "zip.size_budget",
+				Message:    fmt.Sprintf("The zip file has a size of %s and exceeds the size budget of %s", humanize.Bytes(r.Size), humanize.Bytes(maxSize)),
+				Severity:   validation.SeverityError,
+			})
+		}
+	}
+
+	for _, pathBudget := range budget.Paths {
+		maxSize, err := humanize.ParseBytes(pathBudget.Max)
+		if err != nil {
+			check.AddResult(validation.CheckResult{
+				Path:       ".shopware-extension.yml",
+				Identifier: "zip.size_budget",
+				Message:    fmt.Sprintf("Could not parse size budget %s of %s: %s", pathBudget.Max, pathBudget.Path, err.Error()),
+				Severity:   validation.SeverityError,
+			})
+
+			continue
+		}
+
+		entry := r.DirectorySize(pathBudget.Path)
+
+		if entry.CompressedSize > maxSize {
+			check.AddResult(validation.CheckResult{
+				Path:       entry.Path,
+				Identifier: "zip.size_budget",
+				Message:    fmt.Sprintf("The folder %s has a compressed size of %s and exceeds the size budget of %s", entry.Path, humanize.Bytes(entry.CompressedSize), humanize.Bytes(maxSize)),
+				Severity:   validation.SeverityError,
+			})
+		}
+	}
+}
+
+// Write prints the size breakdown as tables to the given writer.
+func (r *ZipReport) Write(out io.Writer) error {
+	if _, err := fmt.Fprintf(out, "Zip size: %s (uncompressed %s, %d files)\n\n", humanize.Bytes(r.Size), humanize.Bytes(r.UncompressedSize), len(r.Files)); err != nil {
+		return err
+	}
+
+	directories := make([]ZipReportEntry, 0, len(r.Directories))
+	for _, entry := range r.Directories {
+		if strings.Count(entry.Path, "/") < zipReportDirectoryDepth {
+			directories = append(directories, *entry)
+		}
+	}
+
+	sort.Slice(directories, func(i, j int) bool {
+		return directories[i].Path < directories[j].Path
+	})
+
+	if err := writeZipReportTable(out, "Directories", directories); err != nil {
+		return err
+	}
+
+	files := r.Files
+	if len(files) > zipReportTopEntries {
+		files = files[:zipReportTopEntries]
+	}
+
+	if err := writeZipReportTable(out, "Largest files", files); err != nil {
+		return err
+	}
+
+	if len(r.VendorPackages) == 0 {
+		return nil
+	}
+
+	packages := make([]ZipReportEntry, 0, len(r.VendorPackages))
+	for _, entry := range r.VendorPackages {
+		packages = append(packages, *entry)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].CompressedSize > packages[j].CompressedSize
+	})
+
+	if len(packages) > zipReportTopEntries {
+		packages = packages[:zipReportTopEntries]
+	}
+
+	return writeZipReportTable(out, "Largest vendor packages", packages)
+}
+
+func writeZipReportTable(out io.Writer, title string, entries []ZipReportEntry) error {
+	if _, err := fmt.Fprintln(out, title); err != nil {
+		return err
+	}
+
+	t := table.NewWriter(out)
+	t.Header([]string{"Path", "Files", "Compressed", "Uncompressed"})
+
+	for _, entry := range entries {
+		_ = t.Append([]string{
+			entry.Path,
+			fmt.Sprintf("%d", entry.Files),
+			humanize.Bytes(entry.CompressedSize),
+			humanize.Bytes(entry.UncompressedSize),
+		})
+	}
+
+	if err := t.Render(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(out)
+
+	return err
+}
@@ -0,0 +1,111 @@
+package extension
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createReportTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	zipPath := filepath.Join(t.TempDir(), "Test.zip")
+
+	out, err := os.Create(zipPath)
+	require.NoError(t, err)
+
+	w := zip.NewWriter(out)
+
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, w.Close())
+	require.NoError(t, out.Close())
+
+	return zipPath
+}
+
+func TestAnalyzeZip(t *testing.T) {
+	zipPath := createReportTestZip(t, map[string]string{
+		"Test/composer.json":                                 "{}",
+		"Test/src/Test.php":                                  "<?php",
+		"Test/src/Resources/public/app.js.map":               strings.Repeat("a", 2048),
+		"Test/vendor/autoload.php":                           "<?php",
+		"Test/vendor/symfony/polyfill/bootstrap.php":         strings.Repeat("b", 512),
+		"Test/vendor/symfony/polyfill/Resources/unidata.php": strings.Repeat("c", 1024),
+	})
+
+	report, err := AnalyzeZip(zipPath)
+	require.NoError(t, err)
+
+	assert.Len(t, report.Files, 6)
+	assert.Equal(t, "src/Resources/public/app.js.map", report.Files[0].Path)
+
+	src := report.DirectorySize("src")
+	assert.Equal(t, 2, src.Files)
+	assert.Equal(t, uint64(2048+5), src.UncompressedSize)
+
+	assert.Equal(t, 3, report.DirectorySize("/vendor/").Files)
+	assert.Equal(t, 0, report.DirectorySize("tests").Files)
+
+	assert.Len(t, report.VendorPackages, 1)
+	assert.Equal(t, 2, report.VendorPackages["symfony/polyfill"].Files)
+	assert.Equal(t, uint64(512+1024), report.VendorPackages["symfony/polyfill"].UncompressedSize)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+	assert.Contains(t, buf.String(), "src/Resources")
+	assert.Contains(t, buf.String(), "symfony/polyfill")
+}
+
+func TestZipReportSizeBudget(t *testing.T) {
+	zipPath := createReportTestZip(t, map[string]string{
+		"Test/composer.json":            "{}",
+		"Test/src/Resources/public/app": strings.Repeat("a", 4096),
+	})
+
+	report, err := AnalyzeZip(zipPath)
+	require.NoError(t, err)
+
+	check := &testCheck{}
+	report.ValidateSizeBudget(ConfigBuildZipSizeBudget{
+		Max: "10MB",
+		Paths: []ConfigBuildZipSizeBudgetPath{
+			{Path: "src/Resources", Max: "1B"},
+			{Path: "vendor", Max: "1B"},
+		},
+	}, check)
+
+	assert.Len(t, check.Results, 1)
+	assert.Equal(t, "zip.size_budget", check.Results[0].Identifier)
+	assert.Equal(t, "src/Resources", check.Results[0].Path)
+
+	check = &testCheck{}
+	report.ValidateSizeBudget(ConfigBuildZipSizeBudget{Max: "10B"}, check)
+
+	assert.Len(t, check.Results, 1)
+	assert.Contains(t, check.Results[0].Message, "The zip file has a size of")
+}
+
+func TestValidateSizeBudgetConfig(t *testing.T) {
+	config := &Config{}
+	config.Build.Zip.SizeBudget.Max = "five megabytes"
+
+	assert.Error(t, validateExtensionConfig(config))
+
+	config.Build.Zip.SizeBudget.Max = "5MB"
+	config.Build.Zip.SizeBudget.Paths = []ConfigBuildZipSizeBudgetPath{{Path: "vendor", Max: "2MiB"}}
+
+	assert.NoError(t, validateExtensionConfig(config))
+}
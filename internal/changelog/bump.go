@@ -0,0 +1,34 @@
+package changelog
+
+import (
+	"github.com/shopware/shopware-cli/internal/git"
+)
+
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// DetectVersionBump infers the semantic version bump from conventional commit prefixes.
+// Breaking changes result in a major, features in a minor and everything else in a patch bump.
+func DetectVersionBump(commits []git.GitCommit) string {
+	bump := BumpPatch
+
+	for _, commit := range commits {
+		parsed, ok := ParseConventionalCommit(commit.Message, commit.Body)
+		if !ok {
+			continue
+		}
+
+		if parsed.Breaking {
+			return BumpMajor
+		}
+
+		if parsed.Type == "feat" {
+			bump = BumpMinor
+		}
+	}
+
+	return bump
+}
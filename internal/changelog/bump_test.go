@@ -0,0 +1,36 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shopware/shopware-cli/internal/git"
+)
+
+func TestDetectVersionBump(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Messages []string
+		Body     string
+		Expected string
+	}{
+		{Name: "no commits", Messages: []string{}, Expected: BumpPatch},
+		{Name: "fixes only", Messages: []string{"fix: typo", "chore(deps): update"}, Expected: BumpPatch},
+		{Name: "feature", Messages: []string{"fix: typo", "feat(admin): new module"}, Expected: BumpMinor},
+		{Name: "breaking marker", Messages: []string{"feat: new module", "refactor(api)!: drop route"}, Expected: BumpMajor},
+		{Name: "breaking footer", Messages: []string{"fix: something"}, Body: "BREAKING CHANGE: removed option", Expected: BumpMajor},
+		{Name: "not conventional", Messages: []string{"NEXT-1234 - feat: nope"}, Expected: BumpPatch},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			commits := make([]git.GitCommit, 0, len(tc.Messages))
+			for _, message := range tc.Messages {
+				commits = append(commits, git.GitCommit{Message: message, Body: tc.Body, Hash: "abc"})
+			}
+
+			assert.Equal(t, tc.Expected, DetectVersionBump(commits))
+		})
+	}
+}
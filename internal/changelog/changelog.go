@@ -15,6 +15,9 @@ import (
 //go:embed changelog.tpl
 var defaultChangelogTpl string
 
+//go:embed keep_a_changelog.tpl
+var keepAChangelogTpl string
+
 type Config struct {
 	// Specifies whether the changelog should be generated.
 	Enabled bool `yaml:"enabled"`
@@ -24,6 +27,13 @@ type Config struct {
 	Template string `yaml:"template,omitempty"`
 	// Specifies the variables to use for the changelog.
 	Variables map[string]string `yaml:"variables,omitempty"`
+	// Specifies whether the commits are grouped into Keep a Changelog sections by their conventional commit type.
+	// When not set, the sections are used with the default template if all commits follow the conventional commits specification.
+	ConventionalCommits *bool `yaml:"conventional_commits,omitempty"`
+	// Specifies additional mappings of conventional commit types to changelog sections, e.g. docs: Changed.
+	Sections map[string]string `yaml:"sections,omitempty"`
+	// Specifies the AI provider used to translate the changelog into the other languages.
+	Translation TranslationConfig `yaml:"translation,omitempty"`
 	// Specifies the URL of the VCS repository.
 	VCSURL string `yaml:"-"`
 }
@@ -32,25 +42,49 @@ type Commit struct {
 	Message   string
 	Hash      string
 	Variables map[string]string
+	// The following fields are only set for conventional commits
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	Section     string
+}
+
+// Section is a group of commits in a Keep a Changelog formatted changelog.
+type Section struct {
+	Name    string
+	Commits []Commit
 }
 
 // GenerateChangelog generates a changelog from the git repository.
 func GenerateChangelog(ctx context.Context, currentVersion string, repository string, cfg Config) (string, error) {
-	var err error
+	commits, err := git.GetCommits(ctx, currentVersion, repository)
+	if err != nil {
+		return "", err
+	}
+
+	usesDefaultTemplate := cfg.Template == ""
+
+	if usesDefaultTemplate {
+		conventional := usesConventionalCommits(commits, cfg)
+		cfg.ConventionalCommits = &conventional
 
-	if cfg.Template == "" {
 		cfg.Template = defaultChangelogTpl
+
+		if conventional {
+			cfg.Template = keepAChangelogTpl
+		}
 	}
 
 	if strings.Contains(cfg.Template, "Config.VCSURL") {
 		cfg.VCSURL, err = git.GetPublicVCSURL(ctx, repository)
-	}
 
-	if err != nil {
-		return "", err
+		// The Keep a Changelog template links commits only when the repository is hosted on a known platform
+		if err != nil && usesDefaultTemplate && *cfg.ConventionalCommits {
+			cfg.VCSURL, err = "", nil
+		}
 	}
 
-	commits, err := git.GetCommits(ctx, currentVersion, repository)
 	if err != nil {
 		return "", err
 	}
@@ -81,6 +115,13 @@ func renderChangelog(commits []git.GitCommit, cfg Config) (string, error) {
 			Variables: make(map[string]string),
 		}
 
+		if cfg.ConventionalCommits != nil && *cfg.ConventionalCommits {
+			var ok bool
+			if parsed, ok = applyConventionalCommit(parsed, commit, cfg.Sections); !ok {
+				continue
+			}
+		}
+
 		for key, variableMatcher := range variableMatchers {
 			matches := variableMatcher.FindStringSubmatch(commit.Message)
 			if len(matches) > 0 {
@@ -96,8 +137,9 @@ func renderChangelog(commits []git.GitCommit, cfg Config) (string, error) {
 	templateParsed := template.Must(template.New("changelog").Parse(cfg.Template))
 
 	templateContext := map[string]interface{}{
-		"Commits": changelog,
-		"Config":  cfg,
+		"Commits":  changelog,
+		"Sections": groupBySection(changelog),
+		"Config":   cfg,
 	}
 
 	var buf bytes.Buffer
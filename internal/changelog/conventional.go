@@ -0,0 +1,156 @@
+package changelog
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shopware/shopware-cli/internal/git"
+)
+
+const (
+	SectionAdded   = "Added"
+	SectionChanged = "Changed"
+	SectionFixed   = "Fixed"
+	SectionRemoved = "Removed"
+)
+
+// sectionOrder is the order of the Keep a Changelog sections in the rendered changelog.
+var sectionOrder = []string{SectionAdded, SectionChanged, SectionFixed, SectionRemoved}
+
+// defaultSections maps conventional commit types to Keep a Changelog sections. Other types are not part of the changelog.
+var defaultSections = map[string]string{
+	"feat":     SectionAdded,
+	"fix":      SectionFixed,
+	"perf":     SectionChanged,
+	"refactor": SectionChanged,
+	"revert":   SectionChanged,
+	"remove":   SectionRemoved,
+}
+
+var (
+	conventionalCommitHeader = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	breakingChangeFooter     = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s*(.+)$`)
+)
+
+// ConventionalCommit is a commit message following https://www.conventionalcommits.org.
+type ConventionalCommit struct {
+	Type        string
+	Scope       string
+	Description string
+	Breaking    bool
+	// BreakingDescription is the text of the BREAKING CHANGE footer
+	BreakingDescription string
+}
+
+// ParseConventionalCommit parses the subject and body of a commit. It returns false when the subject is not a conventional commit.
+func ParseConventionalCommit(subject, body string) (ConventionalCommit, bool) {
+	matches := conventionalCommitHeader.FindStringSubmatch(strings.TrimSpace(subject))
+	if matches == nil {
+		return ConventionalCommit{}, false
+	}
+
+	commit := ConventionalCommit{
+		Type:        strings.ToLower(matches[1]),
+		Scope:       matches[2],
+		Breaking:    matches[3] == "!",
+		Description: matches[4],
+	}
+
+	if footer := breakingChangeFooter.FindStringSubmatch(body); footer != nil {
+		commit.Breaking = true
+		commit.BreakingDescription = strings.TrimSpace(footer[1])
+	}
+
+	return commit, true
+}
+
+func resolveSection(commitType string, sections map[string]string) string {
+	if section, ok := sections[commitType]; ok {
+		return section
+	}
+
+	return defaultSections[commitType]
+}
+
+// applyConventionalCommit fills the conventional commit fields. Commits of types without a section are skipped,
+// commits not following the specification are listed as changed.
+func applyConventionalCommit(parsed Commit, commit git.GitCommit, sections map[string]string) (Commit, bool) {
+	conventional, ok := ParseConventionalCommit(commit.Message, commit.Body)
+	if !ok {
+		parsed.Description = commit.Message
+		parsed.Section = SectionChanged
+
+		return parsed, true
+	}
+
+	parsed.Type = conventional.Type
+	parsed.Scope = conventional.Scope
+	parsed.Description = conventional.Description
+	parsed.Breaking = conventional.Breaking
+	parsed.Section = resolveSection(conventional.Type, sections)
+
+	if parsed.Breaking && parsed.Section == "" {
+		parsed.Section = SectionChanged
+	}
+
+	return parsed, parsed.Section != ""
+}
+
+// usesConventionalCommits returns the configured setting, or whether all commits matching the pattern
+// follow the conventional commits specification when it is not set.
+func usesConventionalCommits(commits []git.GitCommit, cfg Config) bool {
+	if cfg.ConventionalCommits != nil {
+		return *cfg.ConventionalCommits
+	}
+
+	var matcher *regexp.Regexp
+	if cfg.Pattern != "" {
+		matcher = regexp.MustCompile(cfg.Pattern)
+	}
+
+	matched := 0
+
+	for _, commit := range commits {
+		if matcher != nil && !matcher.MatchString(commit.Message) {
+			continue
+		}
+
+		if _, ok := ParseConventionalCommit(commit.Message, commit.Body); !ok {
+			return false
+		}
+
+		matched++
+	}
+
+	return matched > 0
+}
+
+// groupBySection groups the commits by their section, the Keep a Changelog sections first followed by custom sections sorted by name.
+func groupBySection(commits []Commit) []Section {
+	grouped := make(map[string][]Commit)
+
+	for _, commit := range commits {
+		if commit.Section == "" {
+			continue
+		}
+
+		grouped[commit.Section] = append(grouped[commit.Section], commit)
+	}
+
+	sections := make([]Section, 0, len(grouped))
+
+	for _, name := range sectionOrder {
+		if commits, ok := grouped[name]; ok {
+			sections = append(sections, Section{Name: name, Commits: commits})
+			delete(grouped, name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(grouped)) {
+		sections = append(sections, Section{Name: name, Commits: grouped[name]})
+	}
+
+	return sections
+}
@@ -0,0 +1,98 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/shopware/shopware-cli/internal/git"
+)
+
+func TestParseConventionalCommit(t *testing.T) {
+	commit, ok := ParseConventionalCommit("feat(admin)!: add new module", "")
+	assert.True(t, ok)
+	assert.Equal(t, ConventionalCommit{Type: "feat", Scope: "admin", Description: "add new module", Breaking: true}, commit)
+
+	commit, ok = ParseConventionalCommit("fix: handle empty cart", "Some details\n\nBREAKING CHANGE: removed the old event")
+	assert.True(t, ok)
+	assert.True(t, commit.Breaking)
+	assert.Equal(t, "removed the old event", commit.BreakingDescription)
+
+	_, ok = ParseConventionalCommit("Merge branch 'main'", "")
+	assert.False(t, ok)
+}
+
+func TestRenderKeepAChangelog(t *testing.T) {
+	enabled := true
+	commits := []git.GitCommit{
+		{Message: "feat(storefront): add wishlist", Hash: "aaa"},
+		{Message: "fix: correct price rounding", Hash: "bbb"},
+		{Message: "chore: update dependencies", Hash: "ccc"},
+		{Message: "refactor!: drop legacy API", Hash: "ddd"},
+		{Message: "docs: explain configuration", Hash: "eee"},
+		{Message: "Update readme", Hash: "fff"},
+	}
+
+	changelog, err := renderChangelog(commits, Config{
+		ConventionalCommits: &enabled,
+		Sections:            map[string]string{"docs": "Documentation"},
+		Template:            keepAChangelogTpl,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `### Added
+- **storefront:** add wishlist
+
+### Changed
+- **BREAKING** drop legacy API
+- Update readme
+
+### Fixed
+- correct price rounding
+
+### Documentation
+- explain configuration`, changelog)
+}
+
+func TestRenderKeepAChangelogWithLinks(t *testing.T) {
+	enabled := true
+	commits := []git.GitCommit{
+		{Message: "fix: correct price rounding", Hash: "bbb"},
+	}
+
+	changelog, err := renderChangelog(commits, Config{
+		ConventionalCommits: &enabled,
+		Template:            keepAChangelogTpl,
+		VCSURL:              "https://github.com/FriendsOfShopware/FroshTools/commit",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "### Fixed\n- correct price rounding ([bbb](https://github.com/FriendsOfShopware/FroshTools/commit/bbb))", changelog)
+}
+
+func TestUsesConventionalCommits(t *testing.T) {
+	conventional := []git.GitCommit{
+		{Message: "feat: add wishlist"},
+		{Message: "fix(cart): correct price rounding"},
+	}
+
+	assert.True(t, usesConventionalCommits(conventional, Config{}))
+	assert.False(t, usesConventionalCommits(append(conventional, git.GitCommit{Message: "Update readme"}), Config{}))
+	assert.False(t, usesConventionalCommits(nil, Config{}))
+
+	// Only the commits matching the pattern are checked
+	assert.True(t, usesConventionalCommits(append(conventional, git.GitCommit{Message: "Merge branch 'main'"}), Config{Pattern: "^(feat|fix)"}))
+
+	// An explicit setting always wins
+	disabled := false
+	assert.False(t, usesConventionalCommits(conventional, Config{ConventionalCommits: &disabled}))
+}
+
+func TestTranslateChangelogsWithoutProvider(t *testing.T) {
+	changelogs, err := TranslateChangelogs(t.Context(), "- fix", TranslationConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{LanguageEnglish: "- fix", LanguageGerman: "- fix"}, changelogs)
+
+	_, err = TranslateChangelogs(t.Context(), "- fix", TranslationConfig{Provider: "unknown"})
+	assert.Error(t, err)
+}
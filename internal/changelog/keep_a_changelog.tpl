@@ -0,0 +1,4 @@
+{{range .Sections}}### {{ .Name }}
+{{range .Commits}}- {{ if .Breaking }}**BREAKING** {{ end }}{{ if .Scope }}**{{ .Scope }}:** {{ end }}{{ .Description }}{{ if $.Config.VCSURL }} ([{{ .Hash }}]({{ $.Config.VCSURL }}/{{ .Hash }})){{ end }}
+{{end}}
+{{end}}
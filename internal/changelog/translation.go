@@ -0,0 +1,69 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shopware/shopware-cli/internal/llm"
+)
+
+const (
+	LanguageEnglish = "en-GB"
+	LanguageGerman  = "de-DE"
+)
+
+// Languages are the changelog languages required by the Shopware Store.
+var Languages = []string{LanguageEnglish, LanguageGerman}
+
+var translationProviders = []string{"ollama", "openai", "gemini", "openrouter"}
+
+const translationSystemPrompt = `You translate changelogs of Shopware extensions from English into %s.
+Keep the Markdown formatting, links, commit hashes, code and technical identifiers unchanged.
+Answer only with the translated changelog, without any explanation.`
+
+type TranslationConfig struct {
+	// Specifies the AI provider to use for the translation. Supported are ollama, openai, gemini and openrouter.
+	Provider string `yaml:"provider,omitempty"`
+	// Specifies the model used by the provider.
+	Model string `yaml:"model,omitempty"`
+}
+
+// TranslateChangelogs returns the changelog for each language of Languages.
+// Without a configured translation provider the English changelog is used for all languages.
+func TranslateChangelogs(ctx context.Context, content string, cfg TranslationConfig) (map[string]string, error) {
+	changelogs := map[string]string{}
+
+	var client llm.LLMClient
+
+	if cfg.Provider != "" {
+		if !slices.Contains(translationProviders, cfg.Provider) {
+			return nil, fmt.Errorf("unsupported translation provider %s, supported are %s", cfg.Provider, strings.Join(translationProviders, ", "))
+		}
+
+		var err error
+		if client, err = llm.NewLLMClient(cfg.Provider); err != nil {
+			return nil, fmt.Errorf("create translation client: %w", err)
+		}
+	}
+
+	for _, language := range Languages {
+		if language == LanguageEnglish || client == nil || strings.TrimSpace(content) == "" {
+			changelogs[language] = content
+			continue
+		}
+
+		translated, err := client.Generate(ctx, content, &llm.LLMOptions{
+			Model:        cfg.Model,
+			SystemPrompt: fmt.Sprintf(translationSystemPrompt, language),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("translate changelog into %s: %w", language, err)
+		}
+
+		changelogs[language] = strings.TrimSpace(translated)
+	}
+
+	return changelogs, nil
+}
@@ -0,0 +1,329 @@
+package dbdump
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultAnonymizationPreset is the name of the built-in anonymization preset.
+const DefaultAnonymizationPreset = "default"
+
+// anonymizedPasswordHash is the bcrypt hash of "shopware", all anonymized accounts can log in with it.
+const anonymizedPasswordHash = "$2y$10$TEUmP5WyvvCFDmF8yywvr.xSwCAdVKWh72ZbHYPX6I4vriC8SKWw6"
+
+// AnonymizationRules maps table names to columns and the strategy used to anonymize them.
+type AnonymizationRules map[string]map[string]string
+
+// AnonymizationPreset is a named set of anonymization rules, which can extend another preset.
+type AnonymizationPreset struct {
+	Extends string
+	Rules   AnonymizationRules
+}
+
+var personRules = map[string]string{
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"company":    "company",
+	"title":      "clear",
+}
+
+var addressRules = map[string]string{
+	"street":                   "street",
+	"zipcode":                  "zipcode",
+	"city":                     "city",
+	"phone_number":             "phone",
+	"additional_address_line1": "clear",
+	"additional_address_line2": "clear",
+}
+
+func mergeRules(sets ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+
+	for _, set := range sets {
+		maps.Copy(merged, set)
+	}
+
+	return merged
+}
+
+var builtinAnonymizationPresets = map[string]AnonymizationRules{
+	DefaultAnonymizationPreset: {
+		"customer": mergeRules(personRules, map[string]string{
+			"email":           "email",
+			"remote_address":  "ip",
+			"password":        "password",
+			"legacy_password": "null",
+			"legacy_encoder":  "null",
+			"birthday":        "null",
+			"vat_ids":         "null",
+		}),
+		"customer_address": mergeRules(personRules, addressRules),
+		"order_address":    mergeRules(personRules, addressRules, map[string]string{"vat_id": "null"}),
+		"order_customer": mergeRules(personRules, map[string]string{
+			"email":          "email",
+			"remote_address": "ip",
+			"vat_ids":        "null",
+		}),
+		"order": {
+			"customer_comment": "null",
+		},
+		"newsletter_recipient": {
+			"email":      "email",
+			"first_name": "first_name",
+			"last_name":  "last_name",
+			"street":     "street",
+			"zip_code":   "zipcode",
+			"city":       "city",
+		},
+		"product_review": {
+			"email":          "email",
+			"external_user":  "username",
+			"external_email": "email",
+		},
+		"log_entry": {
+			"provider": "clear",
+		},
+		"user": {
+			"username":   "username",
+			"first_name": "first_name",
+			"last_name":  "last_name",
+			"email":      "email",
+			"password":   "password",
+		},
+		"integration": {
+			"secret_access_key": "password",
+		},
+		"app": {
+			"app_secret": "secret",
+		},
+		"system_config": {
+			"configuration_value": "system_config_secret",
+		},
+	},
+}
+
+var (
+	anonymizedFirstNames = []string{"Anna", "Ben", "Clara", "David", "Emma", "Felix", "Greta", "Hannah", "Jonas", "Lea", "Lukas", "Marie", "Noah", "Olivia", "Paul", "Sophie", "Tom", "Zoe"}
+	anonymizedLastNames  = []string{"Becker", "Fischer", "Hoffmann", "Koch", "Meyer", "Müller", "Richter", "Schäfer", "Schmidt", "Schneider", "Schulz", "Wagner", "Weber", "Wolf"}
+	anonymizedCities     = []string{"Berlin", "Bremen", "Dresden", "Essen", "Hamburg", "Hannover", "Köln", "Leipzig", "München", "Münster", "Nürnberg", "Stuttgart"}
+	anonymizedStreets    = []string{"Bahnhofstraße", "Gartenstraße", "Hauptstraße", "Kirchstraße", "Lindenstraße", "Schillerstraße", "Schulstraße", "Waldweg"}
+	anonymizedCompanies  = []string{"GmbH", "AG", "KG", "UG", "Ltd."}
+)
+
+// systemConfigSecretKeys matches system_config keys holding credentials like API keys.
+const systemConfigSecretKeys = "apikey|api_key|secret|password|token|accesskey|access_key|privatekey|private_key"
+
+// ResolveAnonymizationPreset returns the rules of a custom or built-in preset including the rules of the presets it extends.
+func ResolveAnonymizationPreset(name string, custom map[string]AnonymizationPreset) (AnonymizationRules, error) {
+	return resolveAnonymizationPreset(name, custom, map[string]bool{})
+}
+
+func resolveAnonymizationPreset(name string, custom map[string]AnonymizationPreset, visited map[string]bool) (AnonymizationRules, error) {
+	preset, isCustom := custom[name]
+
+	// A custom preset can extend the built-in preset of the same name
+	if !isCustom || visited[name] {
+		builtin, ok := builtinAnonymizationPresets[name]
+		if !ok {
+			if visited[name] {
+				return nil, fmt.Errorf("anonymization preset %s extends itself", name)
+			}
+
+			return nil, fmt.Errorf("unknown anonymization preset %s", name)
+		}
+
+		return cloneRules(builtin), nil
+	}
+
+	visited[name] = true
+
+	rules := AnonymizationRules{}
+
+	if preset.Extends != "" {
+		parent, err := resolveAnonymizationPreset(preset.Extends, custom, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = parent
+	}
+
+	for table, columns := range preset.Rules {
+		if rules[table] == nil {
+			rules[table] = map[string]string{}
+		}
+
+		maps.Copy(rules[table], columns)
+	}
+
+	return rules, nil
+}
+
+func cloneRules(rules AnonymizationRules) AnonymizationRules {
+	cloned := make(AnonymizationRules, len(rules))
+
+	for table, columns := range rules {
+		cloned[table] = maps.Clone(columns)
+	}
+
+	return cloned
+}
+
+// BuildAnonymizationRewrites converts the rules into SQL select expressions for the dumper.
+// All values are pseudonymized with a hash keyed by key and the original value, so the same value results
+// in the same pseudonym across all tables and dumps using the same key.
+func BuildAnonymizationRewrites(rules AnonymizationRules, key string) (map[string]map[string]string, error) {
+	keyHash := sha256.Sum256([]byte(key))
+	anonymizer := anonymizer{key: hex.EncodeToString(keyHash[:])}
+
+	rewrites := make(map[string]map[string]string, len(rules))
+
+	for table, columns := range rules {
+		tableRewrites := make(map[string]string, len(columns))
+
+		for column, strategy := range columns {
+			expression, err := anonymizer.expression(column, strategy)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", table, column, err)
+			}
+
+			tableRewrites[strings.ToLower(column)] = expression
+		}
+
+		rewrites[strings.ToLower(table)] = tableRewrites
+	}
+
+	return rewrites, nil
+}
+
+type anonymizer struct {
+	key string
+}
+
+// AnonymizationStrategies are the supported strategies, additionally "sql:<expression>" and faker expressions can be used.
+var AnonymizationStrategies = []string{"first_name", "last_name", "company", "street", "zipcode", "city", "phone", "email", "username", "ip", "password", "secret", "clear", "null", "system_config_secret"}
+
+func (a anonymizer) expression(column, strategy string) (string, error) {
+	if strings.HasPrefix(strategy, "faker.") {
+		return strategy, nil
+	}
+
+	if expression, ok := strings.CutPrefix(strategy, "sql:"); ok {
+		return strings.TrimSpace(expression), nil
+	}
+
+	quoted := QuoteIdentifier(column)
+	hash := fmt.Sprintf("SHA2(CONCAT('%s', '%s', %s), 256)", a.key, strategy, quoted)
+	number := func(start int) string {
+		return fmt.Sprintf("CONV(SUBSTRING(%s, %d, 8), 16, 10)", hash, start)
+	}
+
+	var expression string
+
+	switch strategy {
+	case "first_name":
+		expression = pickExpression(number(1), anonymizedFirstNames)
+	case "last_name":
+		expression = pickExpression(number(1), anonymizedLastNames)
+	case "company":
+		expression = fmt.Sprintf("CONCAT(%s, ' ', %s)", pickExpression(number(1), anonymizedLastNames), pickExpression(number(9), anonymizedCompanies))
+	case "street":
+		expression = fmt.Sprintf("CONCAT(%s, ' ', 1 + %s MOD 200)", pickExpression(number(1), anonymizedStreets), number(9))
+	case "zipcode":
+		expression = fmt.Sprintf("LPAD(%s MOD 100000, 5, '0')", number(1))
+	case "city":
+		expression = pickExpression(number(1), anonymizedCities)
+	case "phone":
+		expression = fmt.Sprintf("CONCAT('+49 ', LPAD(%s MOD 1000000000, 9, '0'))", number(1))
+	case "email":
+		expression = fmt.Sprintf("CONCAT(LEFT(%s, 16), '@example.com')", hash)
+	case "username":
+		expression = fmt.Sprintf("CONCAT('user-', LEFT(%s, 10))", hash)
+	case "ip":
+		expression = fmt.Sprintf("INET_NTOA(%s)", number(1))
+	case "password":
+		expression = "'" + anonymizedPasswordHash + "'"
+	case "secret":
+		expression = hash
+	case "clear":
+		return "''", nil
+	case "null":
+		return "NULL", nil
+	case "system_config_secret":
+		return fmt.Sprintf("IF(LOWER(`configuration_key`) REGEXP '%s', JSON_OBJECT('_value', ''), %s)", systemConfigSecretKeys, quoted), nil
+	default:
+		return "", fmt.Errorf("unknown anonymization strategy %s, supported are %s", strategy, strings.Join(AnonymizationStrategies, ", "))
+	}
+
+	// Empty values stay empty, so optional fields are not filled
+	return fmt.Sprintf("IF(%s IS NULL OR %s = '', %s, %s)", quoted, quoted, quoted, expression), nil
+}
+
+func pickExpression(number string, values []string) string {
+	quoted := make([]string, len(values))
+
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+
+	return fmt.Sprintf("ELT(1 + %s MOD %d, %s)", number, len(values), strings.Join(quoted, ", "))
+}
+
+// Column is a column of a database table.
+type Column struct {
+	Table string
+	Name  string
+}
+
+// LoadColumns reads all columns of the schema from information_schema.
+func LoadColumns(ctx context.Context, db *sql.DB, schema string) ([]Column, error) {
+	rows, err := db.QueryContext(ctx, "SELECT TABLE_NAME, COLUMN_NAME FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME, ORDINAL_POSITION", schema)
+	if err != nil {
+		return nil, fmt.Errorf("load columns: %w", err)
+	}
+
+	defer rows.Close()
+
+	columns := make([]Column, 0)
+
+	for rows.Next() {
+		var column Column
+
+		if err := rows.Scan(&column.Table, &column.Name); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns, rows.Err()
+}
+
+var piiColumnPattern = regexp.MustCompile(`^(first_?name|last_?name|full_?name|e_?mail|phone(_number)?|mobile|street|zip_?code|city|birthday|date_of_birth|remote_address|ip_address|vat_ids?|iban|bic|username|company|additional_address_line\d|customer_comment|external_user|external_email)$|(^|_)(password|secret|secret_access_key|token|email)$`)
+
+// FindUncoveredPIIColumns returns the columns which look like personal data or credentials but are not anonymized.
+// Columns of tables in skipTables are not exported with data and therefore considered covered.
+func FindUncoveredPIIColumns(columns []Column, rewrites map[string]map[string]string, skipTables []string) []Column {
+	uncovered := make([]Column, 0)
+
+	for _, column := range columns {
+		if slices.Contains(skipTables, column.Table) || !piiColumnPattern.MatchString(strings.ToLower(column.Name)) {
+			continue
+		}
+
+		if _, ok := rewrites[strings.ToLower(column.Table)][strings.ToLower(column.Name)]; ok {
+			continue
+		}
+
+		uncovered = append(uncovered, column)
+	}
+
+	return uncovered
+}
@@ -0,0 +1,79 @@
+package dbdump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAnonymizationPreset(t *testing.T) {
+	custom := map[string]AnonymizationPreset{
+		"strict": {
+			Extends: DefaultAnonymizationPreset,
+			Rules: AnonymizationRules{
+				"customer":  {"email": "null"},
+				"my_plugin": {"api_token": "secret"},
+			},
+		},
+		"loop": {Extends: "loop"},
+	}
+
+	rules, err := ResolveAnonymizationPreset("strict", custom)
+	require.NoError(t, err)
+	assert.Equal(t, "null", rules["customer"]["email"])
+	assert.Equal(t, "first_name", rules["customer"]["first_name"])
+	assert.Equal(t, "secret", rules["my_plugin"]["api_token"])
+
+	// The built-in preset is not modified by extending presets
+	assert.Equal(t, "email", builtinAnonymizationPresets[DefaultAnonymizationPreset]["customer"]["email"])
+
+	_, err = ResolveAnonymizationPreset("loop", custom)
+	assert.Error(t, err)
+
+	_, err = ResolveAnonymizationPreset("missing", custom)
+	assert.Error(t, err)
+}
+
+func TestBuildAnonymizationRewritesIsDeterministic(t *testing.T) {
+	rules, err := ResolveAnonymizationPreset(DefaultAnonymizationPreset, nil)
+	require.NoError(t, err)
+
+	first, err := BuildAnonymizationRewrites(rules, "key")
+	require.NoError(t, err)
+
+	second, err := BuildAnonymizationRewrites(rules, "key")
+	require.NoError(t, err)
+
+	other, err := BuildAnonymizationRewrites(rules, "other-key")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first["customer"]["email"], other["customer"]["email"])
+
+	// Same values in different tables result in the same pseudonym to keep joins working
+	assert.Equal(t, first["customer"]["email"], first["order_customer"]["email"])
+	assert.Contains(t, first["customer"]["password"], anonymizedPasswordHash)
+	assert.Equal(t, "NULL", first["customer"]["birthday"])
+
+	_, err = BuildAnonymizationRewrites(AnonymizationRules{"customer": {"email": "unknown"}}, "key")
+	assert.Error(t, err)
+}
+
+func TestFindUncoveredPIIColumns(t *testing.T) {
+	columns := []Column{
+		{Table: "customer", Name: "email"},
+		{Table: "customer", Name: "id"},
+		{Table: "my_plugin_contact", Name: "email"},
+		{Table: "my_plugin_contact", Name: "api_token"},
+		{Table: "my_plugin_contact", Name: "created_at"},
+		{Table: "cart", Name: "email"},
+	}
+
+	uncovered := FindUncoveredPIIColumns(columns, map[string]map[string]string{"customer": {"email": "''"}}, []string{"cart"})
+
+	assert.Equal(t, []Column{
+		{Table: "my_plugin_contact", Name: "email"},
+		{Table: "my_plugin_contact", Name: "api_token"},
+	}, uncovered)
+}
@@ -0,0 +1,380 @@
+package dbdump
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// TableSchema contains the definitions of a table, each keyed by name with a comparable definition as value.
+type TableSchema struct {
+	Columns     map[string]string
+	Indexes     map[string]string
+	ForeignKeys map[string]string
+}
+
+// ConfigTableContent are the rows of a configuration table keyed by a shop independent identifier.
+type ConfigTableContent map[string]string
+
+// DatabaseSnapshot is the comparable state of a Shopware database.
+type DatabaseSnapshot struct {
+	Tables       map[string]*TableSchema
+	Migrations   []string
+	ConfigTables map[string]ConfigTableContent
+}
+
+// systemLanguageID is the id of the system language, which is the same in every Shopware installation.
+const systemLanguageID = "2FBB5FE2E29A4D70AA5854CE7CE3E20B"
+
+// configTableQueries select an identifier, which is equal across shops, and the value of each row.
+// Sales channel specific settings are keyed by the name of the sales channel in the system language, as the ids differ
+// between shops. Sales channels sharing a name cannot be told apart, only one of their settings is compared.
+var configTableQueries = map[string]string{
+	"system_config": `SELECT CONCAT(c.configuration_key, IF(c.sales_channel_id IS NULL, '', CONCAT('@', COALESCE(t.name, LOWER(HEX(c.sales_channel_id)))))), COALESCE(c.configuration_value, '')
+FROM system_config c LEFT JOIN sales_channel_translation t ON t.sales_channel_id = c.sales_channel_id AND t.language_id = UNHEX('` + systemLanguageID + `')`,
+	"plugin":               "SELECT name, CONCAT(version, IF(active = 1, ' active', ' inactive'), IF(installed_at IS NULL, ' not installed', '')) FROM plugin",
+	"app":                  "SELECT name, CONCAT(version, IF(active = 1, ' active', ' inactive')) FROM app",
+	"sales_channel_domain": "SELECT url, '' FROM sales_channel_domain",
+}
+
+// ConfigTables are the tables compared by content in a database diff.
+var ConfigTables = slices.Sorted(maps.Keys(configTableQueries))
+
+// LoadDatabaseSnapshot reads the schema, the executed migrations and the configuration tables of the database.
+func LoadDatabaseSnapshot(ctx context.Context, db *sql.DB, schema string) (*DatabaseSnapshot, error) {
+	snapshot := &DatabaseSnapshot{
+		Tables:       make(map[string]*TableSchema),
+		ConfigTables: make(map[string]ConfigTableContent),
+	}
+
+	tableSchema := func(table string) *TableSchema {
+		if _, ok := snapshot.Tables[table]; !ok {
+			snapshot.Tables[table] = &TableSchema{Columns: map[string]string{}, Indexes: map[string]string{}, ForeignKeys: map[string]string{}}
+		}
+
+		return snapshot.Tables[table]
+	}
+
+	if err := QueryRows(ctx, db, `SELECT t.TABLE_NAME, c.COLUMN_NAME, CONCAT(c.COLUMN_TYPE, IF(c.IS_NULLABLE = 'YES', ' NULL', ' NOT NULL'), IF(c.COLUMN_DEFAULT IS NULL, '', CONCAT(' DEFAULT ', c.COLUMN_DEFAULT)), IF(c.EXTRA = '', '', CONCAT(' ', c.EXTRA)))
+FROM information_schema.TABLES t LEFT JOIN information_schema.COLUMNS c ON c.TABLE_SCHEMA = t.TABLE_SCHEMA AND c.TABLE_NAME = t.TABLE_NAME
+WHERE t.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'`, func(values []sql.NullString) {
+		table := tableSchema(values[0].String)
+
+		if values[1].Valid {
+			table.Columns[values[1].String] = values[2].String
+		}
+	}, schema); err != nil {
+		return nil, fmt.Errorf("load columns: %w", err)
+	}
+
+	if err := QueryRows(ctx, db, `SELECT TABLE_NAME, INDEX_NAME, CONCAT(IF(NON_UNIQUE = 0, 'UNIQUE ', ''), INDEX_TYPE, ' (', GROUP_CONCAT(COLUMN_NAME ORDER BY SEQ_IN_INDEX SEPARATOR ', '), ')')
+FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ? GROUP BY TABLE_NAME, INDEX_NAME, NON_UNIQUE, INDEX_TYPE`, func(values []sql.NullString) {
+		tableSchema(values[0].String).Indexes[values[1].String] = values[2].String
+	}, schema); err != nil {
+		return nil, fmt.Errorf("load indexes: %w", err)
+	}
+
+	keys, err := LoadForeignKeys(ctx, db, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		tableSchema(key.Table).ForeignKeys[key.Name] = fmt.Sprintf("(%s) REFERENCES %s (%s)", strings.Join(key.Columns, ", "), key.ReferencedTable, strings.Join(key.ReferencedColumns, ", "))
+	}
+
+	if _, ok := snapshot.Tables["migration"]; ok {
+		if err := QueryRows(ctx, db, "SELECT class FROM migration WHERE `update` IS NOT NULL ORDER BY class", func(values []sql.NullString) {
+			snapshot.Migrations = append(snapshot.Migrations, values[0].String)
+		}); err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+	}
+
+	for table, query := range configTableQueries {
+		if _, ok := snapshot.Tables[table]; !ok {
+			continue
+		}
+
+		content := ConfigTableContent{}
+
+		if err := QueryRows(ctx, db, query, func(values []sql.NullString) {
+			content[values[0].String] = values[1].String
+		}); err != nil {
+			return nil, fmt.Errorf("load %s: %w", table, err)
+		}
+
+		snapshot.ConfigTables[table] = content
+	}
+
+	return snapshot, nil
+}
+
+// QueryRows calls fn with the columns of every row of the query.
+func QueryRows(ctx context.Context, db *sql.DB, query string, fn func(values []sql.NullString), args ...any) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	values := make([]sql.NullString, len(columns))
+	scanArgs := make([]any, len(values))
+
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+
+		fn(values)
+	}
+
+	return rows.Err()
+}
+
+// Checksum returns a hash over all rows, which is equal when both tables have the same content.
+func (c ConfigTableContent) Checksum() string {
+	hasher := sha256.New()
+
+	for _, key := range slices.Sorted(maps.Keys(c)) {
+		hasher.Write([]byte(key))
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(c[key]))
+		hasher.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// DefinitionDiff is a column, index or foreign key which differs, an empty side means it does not exist there.
+type DefinitionDiff struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+type TableDiff struct {
+	Table       string           `json:"table"`
+	Definitions []DefinitionDiff `json:"definitions"`
+}
+
+// ConfigTableDiff lists the identifiers of rows which differ. Values are not part of the diff, as they can contain credentials.
+type ConfigTableDiff struct {
+	Table          string   `json:"table"`
+	SourceRows     int      `json:"source_rows"`
+	TargetRows     int      `json:"target_rows"`
+	SourceChecksum string   `json:"source_checksum"`
+	TargetChecksum string   `json:"target_checksum"`
+	OnlyInSource   []string `json:"only_in_source,omitempty"`
+	OnlyInTarget   []string `json:"only_in_target,omitempty"`
+	Changed        []string `json:"changed,omitempty"`
+}
+
+type DatabaseDiff struct {
+	TablesOnlyInSource     []string          `json:"tables_only_in_source,omitempty"`
+	TablesOnlyInTarget     []string          `json:"tables_only_in_target,omitempty"`
+	ChangedTables          []TableDiff       `json:"changed_tables,omitempty"`
+	MigrationsOnlyInSource []string          `json:"migrations_only_in_source,omitempty"`
+	MigrationsOnlyInTarget []string          `json:"migrations_only_in_target,omitempty"`
+	ConfigTables           []ConfigTableDiff `json:"config_tables"`
+}
+
+// HasDifferences reports whether the databases differ.
+func (d *DatabaseDiff) HasDifferences() bool {
+	if len(d.TablesOnlyInSource) > 0 || len(d.TablesOnlyInTarget) > 0 || len(d.ChangedTables) > 0 || len(d.MigrationsOnlyInSource) > 0 || len(d.MigrationsOnlyInTarget) > 0 {
+		return true
+	}
+
+	for _, table := range d.ConfigTables {
+		if table.SourceChecksum != table.TargetChecksum {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CompareDatabases compares two snapshots.
+func CompareDatabases(source, target *DatabaseSnapshot) *DatabaseDiff {
+	diff := &DatabaseDiff{ConfigTables: make([]ConfigTableDiff, 0)}
+
+	diff.TablesOnlyInSource, diff.TablesOnlyInTarget = compareKeys(source.Tables, target.Tables)
+	diff.MigrationsOnlyInSource, diff.MigrationsOnlyInTarget = compareLists(source.Migrations, target.Migrations)
+
+	for _, name := range slices.Sorted(maps.Keys(source.Tables)) {
+		targetTable, ok := target.Tables[name]
+		if !ok {
+			continue
+		}
+
+		definitions := make([]DefinitionDiff, 0)
+		definitions = append(definitions, compareDefinitions("column", source.Tables[name].Columns, targetTable.Columns)...)
+		definitions = append(definitions, compareDefinitions("index", source.Tables[name].Indexes, targetTable.Indexes)...)
+		definitions = append(definitions, compareDefinitions("foreign key", source.Tables[name].ForeignKeys, targetTable.ForeignKeys)...)
+
+		if len(definitions) > 0 {
+			diff.ChangedTables = append(diff.ChangedTables, TableDiff{Table: name, Definitions: definitions})
+		}
+	}
+
+	for _, table := range ConfigTables {
+		sourceContent, targetContent := source.ConfigTables[table], target.ConfigTables[table]
+
+		if sourceContent == nil && targetContent == nil {
+			continue
+		}
+
+		tableDiff := ConfigTableDiff{
+			Table:          table,
+			SourceRows:     len(sourceContent),
+			TargetRows:     len(targetContent),
+			SourceChecksum: sourceContent.Checksum(),
+			TargetChecksum: targetContent.Checksum(),
+		}
+
+		tableDiff.OnlyInSource, tableDiff.OnlyInTarget = compareKeys(sourceContent, targetContent)
+
+		for _, key := range slices.Sorted(maps.Keys(sourceContent)) {
+			if value, ok := targetContent[key]; ok && value != sourceContent[key] {
+				tableDiff.Changed = append(tableDiff.Changed, key)
+			}
+		}
+
+		diff.ConfigTables = append(diff.ConfigTables, tableDiff)
+	}
+
+	return diff
+}
+
+func compareKeys[V any](source, target map[string]V) ([]string, []string) {
+	return compareLists(slices.Collect(maps.Keys(source)), slices.Collect(maps.Keys(target)))
+}
+
+func compareLists(source, target []string) ([]string, []string) {
+	var onlyInSource, onlyInTarget []string
+
+	for _, item := range source {
+		if !slices.Contains(target, item) {
+			onlyInSource = append(onlyInSource, item)
+		}
+	}
+
+	for _, item := range target {
+		if !slices.Contains(source, item) {
+			onlyInTarget = append(onlyInTarget, item)
+		}
+	}
+
+	slices.Sort(onlyInSource)
+	slices.Sort(onlyInTarget)
+
+	return onlyInSource, onlyInTarget
+}
+
+func compareDefinitions(kind string, source, target map[string]string) []DefinitionDiff {
+	diffs := make([]DefinitionDiff, 0)
+
+	for _, name := range slices.Sorted(maps.Keys(source)) {
+		if source[name] != target[name] {
+			diffs = append(diffs, DefinitionDiff{Kind: kind, Name: name, Source: source[name], Target: target[name]})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(target)) {
+		if _, ok := source[name]; !ok {
+			diffs = append(diffs, DefinitionDiff{Kind: kind, Name: name, Target: target[name]})
+		}
+	}
+
+	return diffs
+}
+
+// Write prints a human-readable report of the differences.
+func (d *DatabaseDiff) Write(out io.Writer) error {
+	var buf strings.Builder
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+
+		fmt.Fprintf(&buf, "%s:\n", title)
+
+		for _, item := range items {
+			fmt.Fprintf(&buf, "  - %s\n", item)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	writeList("Tables only in source", d.TablesOnlyInSource)
+	writeList("Tables only in target", d.TablesOnlyInTarget)
+
+	for _, table := range d.ChangedTables {
+		fmt.Fprintf(&buf, "Table %s:\n", table.Table)
+
+		for _, definition := range table.Definitions {
+			switch {
+			case definition.Target == "":
+				fmt.Fprintf(&buf, "  - %s %s only in source: %s\n", definition.Kind, definition.Name, definition.Source)
+			case definition.Source == "":
+				fmt.Fprintf(&buf, "  + %s %s only in target: %s\n", definition.Kind, definition.Name, definition.Target)
+			default:
+				fmt.Fprintf(&buf, "  ~ %s %s: %s => %s\n", definition.Kind, definition.Name, definition.Source, definition.Target)
+			}
+		}
+
+		buf.WriteString("\n")
+	}
+
+	writeList("Migrations only in source", d.MigrationsOnlyInSource)
+	writeList("Migrations only in target", d.MigrationsOnlyInTarget)
+
+	for _, table := range d.ConfigTables {
+		if table.SourceChecksum == table.TargetChecksum {
+			fmt.Fprintf(&buf, "%s: equal (%d rows)\n\n", table.Table, table.SourceRows)
+			continue
+		}
+
+		fmt.Fprintf(&buf, "%s: %d rows (%s) in source, %d rows (%s) in target\n", table.Table, table.SourceRows, table.SourceChecksum, table.TargetRows, table.TargetChecksum)
+
+		for _, key := range table.OnlyInSource {
+			fmt.Fprintf(&buf, "  - %s\n", key)
+		}
+
+		for _, key := range table.OnlyInTarget {
+			fmt.Fprintf(&buf, "  + %s\n", key)
+		}
+
+		for _, key := range table.Changed {
+			fmt.Fprintf(&buf, "  ~ %s\n", key)
+		}
+
+		buf.WriteString("\n")
+	}
+
+	if !d.HasDifferences() {
+		buf.WriteString("The databases do not differ\n")
+	}
+
+	_, err := io.WriteString(out, buf.String())
+
+	return err
+}
@@ -0,0 +1,57 @@
+package dbdump
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareDatabases(t *testing.T) {
+	source := &DatabaseSnapshot{
+		Tables: map[string]*TableSchema{
+			"product": {
+				Columns: map[string]string{"id": "binary(16) NOT NULL", "stock": "int NOT NULL"},
+				Indexes: map[string]string{"PRIMARY": "UNIQUE BTREE (id)"},
+			},
+			"my_plugin": {Columns: map[string]string{"id": "binary(16) NOT NULL"}},
+		},
+		Migrations: []string{"Migration1", "Migration2"},
+		ConfigTables: map[string]ConfigTableContent{
+			"system_config": {"core.basicInformation.email": `{"_value":"a@example.com"}`, "core.listing.perPage": `{"_value":24}`},
+		},
+	}
+
+	target := &DatabaseSnapshot{
+		Tables: map[string]*TableSchema{
+			"product": {
+				Columns: map[string]string{"id": "binary(16) NOT NULL", "stock": "bigint NOT NULL", "available": "tinyint(1) NOT NULL"},
+				Indexes: map[string]string{"PRIMARY": "UNIQUE BTREE (id)"},
+			},
+		},
+		Migrations: []string{"Migration1"},
+		ConfigTables: map[string]ConfigTableContent{
+			"system_config": {"core.basicInformation.email": `{"_value":"b@example.com"}`, "core.listing.perPage": `{"_value":24}`},
+		},
+	}
+
+	diff := CompareDatabases(source, target)
+
+	assert.True(t, diff.HasDifferences())
+	assert.Equal(t, []string{"my_plugin"}, diff.TablesOnlyInSource)
+	assert.Empty(t, diff.TablesOnlyInTarget)
+	assert.Equal(t, []TableDiff{{Table: "product", Definitions: []DefinitionDiff{
+		{Kind: "column", Name: "stock", Source: "int NOT NULL", Target: "bigint NOT NULL"},
+		{Kind: "column", Name: "available", Target: "tinyint(1) NOT NULL"},
+	}}}, diff.ChangedTables)
+	assert.Equal(t, []string{"Migration2"}, diff.MigrationsOnlyInSource)
+	assert.Len(t, diff.ConfigTables, 1)
+	assert.Equal(t, []string{"core.basicInformation.email"}, diff.ConfigTables[0].Changed)
+
+	var report bytes.Buffer
+	assert.NoError(t, diff.Write(&report))
+	assert.Contains(t, report.String(), "~ column stock: int NOT NULL => bigint NOT NULL")
+	assert.NotContains(t, report.String(), "a@example.com")
+
+	assert.False(t, CompareDatabases(source, source).HasDifferences())
+}
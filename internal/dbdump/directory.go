@@ -0,0 +1,527 @@
+package dbdump
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+const (
+	// ManifestFileName is the name of the manifest inside a directory dump
+	ManifestFileName = "manifest.json"
+	manifestVersion  = 1
+
+	// insertBatchSize is the maximum size of a single INSERT statement
+	insertBatchSize = 1024 * 1024
+)
+
+// Manifest describes a directory dump with one data file per table.
+type Manifest struct {
+	Version     int             `json:"version"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Database    string          `json:"database"`
+	Compression string          `json:"compression"`
+	Tables      []ManifestTable `json:"tables"`
+	Triggers    []string        `json:"triggers,omitempty"`
+	Views       []ManifestView  `json:"views,omitempty"`
+}
+
+// ManifestView is created after all tables have been imported, views only have a schema.
+type ManifestView struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type ManifestTable struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+	// DataFile is empty when only the schema is exported
+	DataFile string `json:"data_file,omitempty"`
+	Rows     int64  `json:"rows"`
+	// Checksum is the sha256 of the data file
+	Checksum string `json:"checksum,omitempty"`
+	Size     int64  `json:"size"`
+}
+
+// ReadManifest reads the manifest of a directory dump.
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d", manifest.Version)
+	}
+
+	return &manifest, nil
+}
+
+// IsDirectoryDump checks whether the path is a directory dump with a manifest.
+func IsDirectoryDump(p string) bool {
+	stat, err := os.Stat(filepath.Join(p, ManifestFileName))
+
+	return err == nil && !stat.IsDir()
+}
+
+// DumpRules controls which tables and rows are exported and how columns are rewritten.
+type DumpRules struct {
+	// Ignore and NoData support glob patterns like log_*
+	Ignore []string
+	NoData []string
+	Where  map[string]string
+	// Rewrite maps tables to columns and SQL expressions or faker expressions
+	Rewrite map[string]map[string]string
+	// Faker replaces values of faker expressions, e.g. faker.Internet.Email()
+	Faker func(expression string) (string, error)
+}
+
+type DirectoryDumpOptions struct {
+	Output      string
+	Database    string
+	Compression string
+	Parallel    int
+	Rules       DumpRules
+}
+
+// DumpDirectory dumps every table into its own file using parallel connections, which share a consistent snapshot.
+func DumpDirectory(ctx context.Context, db *sql.DB, options DirectoryDumpOptions) (*Manifest, error) {
+	if err := os.MkdirAll(options.Output, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	parallel := max(options.Parallel, 1)
+
+	conns, err := openSnapshotConnections(ctx, db, parallel)
+	defer func() {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := ListTables(ctx, conns[0])
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:     manifestVersion,
+		CreatedAt:   time.Now().UTC(),
+		Database:    options.Database,
+		Compression: options.Compression,
+		Tables:      make([]ManifestTable, 0, len(tables)),
+	}
+
+	for _, table := range tables {
+		if !matchesAny(table, options.Rules.Ignore) {
+			manifest.Tables = append(manifest.Tables, ManifestTable{Name: table})
+		}
+	}
+
+	var next atomic.Int64
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for _, conn := range conns {
+		group.Go(func() error {
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(manifest.Tables) {
+					return nil
+				}
+
+				if err := dumpTable(groupCtx, conn, &manifest.Tables[i], options); err != nil {
+					return fmt.Errorf("dump table %s: %w", manifest.Tables[i].Name, err)
+				}
+			}
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	if manifest.Triggers, err = ListTriggers(ctx, conns[0]); err != nil {
+		return nil, err
+	}
+
+	if manifest.Views, err = dumpViews(ctx, conns[0], options.Rules.Ignore); err != nil {
+		return nil, err
+	}
+
+	for _, conn := range conns {
+		if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+			return nil, err
+		}
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.WriteFile(filepath.Join(options.Output, ManifestFileName), content, 0o644); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// openSnapshotConnections starts a transaction with consistent snapshot on each connection.
+// A global read lock guarantees the same snapshot for all connections, without the RELOAD privilege the snapshots are only started at nearly the same time.
+func openSnapshotConnections(ctx context.Context, db *sql.DB, count int) ([]*sql.Conn, error) {
+	conns := make([]*sql.Conn, 0, count+1)
+
+	coordinator, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer coordinator.Close()
+
+	locked := true
+	if _, err := coordinator.ExecContext(ctx, "FLUSH TABLES WITH READ LOCK"); err != nil {
+		locked = false
+		logging.FromContext(ctx).Warnf("Could not acquire a global read lock, the table files may not be consistent with each other: %s", err.Error())
+	}
+
+	for i := 0; i < count; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return conns, err
+		}
+
+		conns = append(conns, conn)
+
+		for _, statement := range []string{
+			"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"SET NAMES utf8mb4",
+			"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		} {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return conns, fmt.Errorf("start snapshot: %w", err)
+			}
+		}
+	}
+
+	if locked {
+		if _, err := coordinator.ExecContext(ctx, "UNLOCK TABLES"); err != nil {
+			return conns, err
+		}
+	}
+
+	return conns, nil
+}
+
+func matchesAny(table string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(table)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func dumpTable(ctx context.Context, conn *sql.Conn, table *ManifestTable, options DirectoryDumpOptions) error {
+	schema, err := ShowCreateTable(ctx, conn, table.Name)
+	if err != nil {
+		return err
+	}
+
+	table.Schema = schema
+
+	if matchesAny(table.Name, options.Rules.NoData) {
+		return nil
+	}
+
+	table.DataFile = table.Name + ".sql" + CompressionExtension(options.Compression)
+
+	file, err := os.Create(filepath.Join(options.Output, table.DataFile))
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	hasher := sha256.New()
+	counter := &countingWriter{writer: io.MultiWriter(file, hasher)}
+
+	compressed, err := NewCompressedWriter(counter, options.Compression)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriterSize(compressed, 1024*1024)
+
+	if table.Rows, err = writeTableData(ctx, conn, table.Name, options.Rules, buffered); err != nil {
+		return err
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	if err := compressed.Close(); err != nil {
+		return err
+	}
+
+	table.Checksum = "sha256:" + hex.EncodeToString(hasher.Sum(nil))
+	table.Size = counter.count
+
+	logging.FromContext(ctx).Debugf("Dumped %d rows of %s", table.Rows, table.Name)
+
+	return file.Close()
+}
+
+func dumpViews(ctx context.Context, conn *sql.Conn, ignore []string) ([]ManifestView, error) {
+	names, err := ListViews(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]ManifestView, 0, len(names))
+
+	for _, name := range names {
+		if matchesAny(name, ignore) {
+			continue
+		}
+
+		schema, err := ShowCreateView(ctx, conn, name)
+		if err != nil {
+			return nil, err
+		}
+
+		views = append(views, ManifestView{Name: name, Schema: schema})
+	}
+
+	return views, nil
+}
+
+type tableColumn struct {
+	name   string
+	binary bool
+	faker  bool
+}
+
+func loadDumpColumns(ctx context.Context, conn *sql.Conn, table string) ([]tableColumn, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE, EXTRA FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, table)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns := make([]tableColumn, 0)
+
+	for rows.Next() {
+		var name, dataType, extra string
+
+		if err := rows.Scan(&name, &dataType, &extra); err != nil {
+			return nil, err
+		}
+
+		// Generated columns are calculated by the database on import
+		if strings.Contains(strings.ToUpper(extra), "GENERATED") {
+			continue
+		}
+
+		switch strings.ToLower(dataType) {
+		case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob", "bit", "geometry", "point", "linestring", "polygon":
+			columns = append(columns, tableColumn{name: name, binary: true})
+		default:
+			columns = append(columns, tableColumn{name: name})
+		}
+	}
+
+	return columns, rows.Err()
+}
+
+func writeTableData(ctx context.Context, conn *sql.Conn, table string, rules DumpRules, w io.Writer) (int64, error) {
+	columns, err := loadDumpColumns(ctx, conn, table)
+	if err != nil {
+		return 0, err
+	}
+
+	rewrites := rules.Rewrite[strings.ToLower(table)]
+	selects := make([]string, len(columns))
+	names := make([]string, len(columns))
+
+	for i, column := range columns {
+		names[i] = QuoteIdentifier(column.name)
+		selects[i] = names[i]
+
+		if rewrite, ok := rewrites[strings.ToLower(column.name)]; ok {
+			var expression string
+			expression, columns[i].faker = rewriteExpression(rewrite)
+
+			selects[i] = fmt.Sprintf("%s AS %s", expression, names[i])
+			columns[i].binary = columns[i].binary && !columns[i].faker
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), QuoteIdentifier(table))
+	if where, ok := rules.Where[strings.ToLower(table)]; ok && where != "" {
+		query += " WHERE " + where
+	}
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES\n", QuoteIdentifier(table), strings.Join(names, ", "))
+
+	values := make([]sql.RawBytes, len(columns))
+	scanArgs := make([]any, len(values))
+
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	var count int64
+	var batch strings.Builder
+
+	flush := func() error {
+		if batch.Len() == 0 {
+			return nil
+		}
+
+		_, err := io.WriteString(w, insert+batch.String()+";\n")
+		batch.Reset()
+
+		return err
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return count, err
+		}
+
+		if batch.Len() > 0 {
+			batch.WriteString(",\n")
+		}
+
+		batch.WriteString("(")
+
+		for i, value := range values {
+			if i > 0 {
+				batch.WriteString(", ")
+			}
+
+			formatted, err := formatValue(value, columns[i], rules.Faker)
+			if err != nil {
+				return count, err
+			}
+
+			batch.WriteString(formatted)
+		}
+
+		batch.WriteString(")")
+		count++
+
+		if batch.Len() >= insertBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+
+	return count, flush()
+}
+
+// rewriteExpression returns the SQL expression selected for a rewritten column. Faker expressions are selected as string
+// and replaced while writing the rows, an empty rewrite empties the column.
+func rewriteExpression(rewrite string) (string, bool) {
+	if strings.HasPrefix(rewrite, "faker") {
+		return quoteString(rewrite), true
+	}
+
+	if strings.TrimSpace(rewrite) == "" {
+		return "''", false
+	}
+
+	return rewrite, false
+}
+
+func formatValue(value sql.RawBytes, column tableColumn, faker func(string) (string, error)) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+
+	if column.faker && faker != nil {
+		replaced, err := faker(string(value))
+		if err != nil {
+			return "", err
+		}
+
+		return quoteString(replaced), nil
+	}
+
+	if column.binary {
+		if len(value) == 0 {
+			return "''", nil
+		}
+
+		return "0x" + hex.EncodeToString(value), nil
+	}
+
+	return quoteString(string(value)), nil
+}
+
+var stringEscaper = strings.NewReplacer(
+	"\x00", `\0`,
+	"\n", `\n`,
+	"\r", `\r`,
+	`\`, `\\`,
+	`'`, `\'`,
+	`"`, `\"`,
+	"\x1a", `\Z`,
+)
+
+func quoteString(value string) string {
+	return "'" + stringEscaper.Replace(value) + "'"
+}
+
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	c.count += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,226 @@
+package dbdump
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DirectoryImportOptions controls the import of a directory dump.
+type DirectoryImportOptions struct {
+	Parallel int
+	// OnTable is called after a table has been imported
+	OnTable func(table ManifestTable)
+}
+
+var sessionStatements = []string{
+	"SET NAMES utf8mb4",
+	"SET FOREIGN_KEY_CHECKS = 0",
+	"SET UNIQUE_CHECKS = 0",
+}
+
+// ImportDirectory imports a directory dump with parallel connections.
+// Tables are created without secondary indexes and foreign keys, which are added after the data has been loaded.
+func ImportDirectory(ctx context.Context, db *sql.DB, dir string, options DirectoryImportOptions) (*Manifest, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	constraints := make([][]string, len(manifest.Tables))
+
+	var next atomic.Int64
+
+	group, groupCtx := errgroup.WithContext(ctx)
+
+	for range max(options.Parallel, 1) {
+		group.Go(func() error {
+			conn, err := openImportConnection(groupCtx, db)
+			if err != nil {
+				return err
+			}
+
+			defer conn.Close()
+
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= len(manifest.Tables) {
+					return nil
+				}
+
+				table := manifest.Tables[i]
+
+				if constraints[i], err = importTable(groupCtx, conn, dir, table); err != nil {
+					return fmt.Errorf("import table %s: %w", table.Name, err)
+				}
+
+				if options.OnTable != nil {
+					options.OnTable(table)
+				}
+			}
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Foreign keys can only be created when all referenced tables exist
+	conn, err := openImportConnection(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	for i, table := range manifest.Tables {
+		if len(constraints[i]) == 0 {
+			continue
+		}
+
+		if _, err := conn.ExecContext(ctx, AddDefinitionsStatement(table.Name, constraints[i])); err != nil {
+			return nil, fmt.Errorf("add foreign keys to %s: %w", table.Name, err)
+		}
+	}
+
+	if err := createViews(ctx, conn, manifest.Views); err != nil {
+		return nil, err
+	}
+
+	for _, trigger := range manifest.Triggers {
+		if _, err := conn.ExecContext(ctx, trigger); err != nil {
+			return nil, fmt.Errorf("create trigger: %w", err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// createViews creates the views, a view selecting from another view is retried after the other views were created.
+func createViews(ctx context.Context, conn *sql.Conn, views []ManifestView) error {
+	for _, view := range views {
+		if _, err := conn.ExecContext(ctx, "DROP VIEW IF EXISTS "+QuoteIdentifier(view.Name)); err != nil {
+			return err
+		}
+	}
+
+	pending := views
+
+	for len(pending) > 0 {
+		failed := make([]ManifestView, 0)
+		var lastErr error
+
+		for _, view := range pending {
+			if _, err := conn.ExecContext(ctx, view.Schema); err != nil {
+				failed = append(failed, view)
+				lastErr = err
+			}
+		}
+
+		if len(failed) == len(pending) {
+			return fmt.Errorf("create view %s: %w", failed[0].Name, lastErr)
+		}
+
+		pending = failed
+	}
+
+	return nil
+}
+
+func openImportConnection(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, statement := range sessionStatements {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// importTable creates the table, loads the data and adds the secondary indexes. The foreign keys are returned to be created later.
+func importTable(ctx context.Context, conn *sql.Conn, dir string, table ManifestTable) ([]string, error) {
+	split := SplitCreateTable(table.Schema)
+
+	if _, err := conn.ExecContext(ctx, "DROP TABLE IF EXISTS "+QuoteIdentifier(table.Name)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.ExecContext(ctx, split.Create); err != nil {
+		return nil, err
+	}
+
+	if table.DataFile != "" {
+		if err := importTableData(ctx, conn, filepath.Join(dir, table.DataFile), table.Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(split.Indexes) > 0 {
+		if _, err := conn.ExecContext(ctx, AddDefinitionsStatement(table.Name, split.Indexes)); err != nil {
+			return nil, fmt.Errorf("add indexes: %w", err)
+		}
+	}
+
+	return split.Constraints, nil
+}
+
+func importTableData(ctx context.Context, conn *sql.Conn, file, checksum string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	hasher := sha256.New()
+
+	reader, err := NewDumpReader(io.TeeReader(f, hasher), 0)
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+
+	scanner := NewStatementScanner(reader)
+
+	for {
+		statement, err := scanner.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("%w\n%s", err, truncateStatement(statement))
+		}
+	}
+
+	// Consume the remaining bytes, e.g. the end of the compression frame, to hash the complete file
+	if _, err := io.Copy(hasher, f); err != nil {
+		return err
+	}
+
+	if actual := "sha256:" + hex.EncodeToString(hasher.Sum(nil)); checksum != "" && actual != checksum {
+		return fmt.Errorf("checksum mismatch of %s, expected %s got %s", filepath.Base(file), checksum, actual)
+	}
+
+	return nil
+}
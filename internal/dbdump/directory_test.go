@@ -0,0 +1,73 @@
+package dbdump
+
+import (
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValue(t *testing.T) {
+	formatted, err := formatValue(nil, tableColumn{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "NULL", formatted)
+
+	formatted, err = formatValue(sql.RawBytes("it's a \"test\"\n\\"), tableColumn{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `'it\'s a \"test\"\n\\'`, formatted)
+
+	formatted, err = formatValue(sql.RawBytes{0x01, 0xab}, tableColumn{binary: true}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0x01ab", formatted)
+
+	formatted, err = formatValue(sql.RawBytes("faker.Internet.Email()"), tableColumn{faker: true}, func(string) (string, error) {
+		return "fake@example.com", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "'fake@example.com'", formatted)
+}
+
+func TestRewriteExpression(t *testing.T) {
+	expression, faker := rewriteExpression("CONCAT('user-', id)")
+	assert.Equal(t, "CONCAT('user-', id)", expression)
+	assert.False(t, faker)
+
+	expression, faker = rewriteExpression("faker.Internet.Email()")
+	assert.Equal(t, "'faker.Internet.Email()'", expression)
+	assert.True(t, faker)
+
+	// An empty rewrite would produce SELECT  AS `column`
+	expression, faker = rewriteExpression(" ")
+	assert.Equal(t, "''", expression)
+	assert.False(t, faker)
+}
+
+func TestMatchesAny(t *testing.T) {
+	assert.True(t, matchesAny("log_entry", []string{"log_*"}))
+	assert.True(t, matchesAny("Cart", []string{"cart"}))
+	assert.False(t, matchesAny("product", []string{"log_*", "cart"}))
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	assert.False(t, IsDirectoryDump(dir))
+
+	content, err := json.Marshal(Manifest{Version: manifestVersion, Tables: []ManifestTable{{Name: "product", Rows: 2}}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), content, 0o644))
+
+	assert.True(t, IsDirectoryDump(dir))
+
+	manifest, err := ReadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), manifest.Tables[0].Rows)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ManifestFileName), []byte(`{"version": 99}`), 0o644))
+
+	_, err = ReadManifest(dir)
+	assert.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package dbdump
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ImportProgress is reported periodically while importing a dump.
+type ImportProgress struct {
+	Statements int
+	ReadBytes  int64
+	TotalBytes int64
+}
+
+// Percent returns the progress in percent or -1 when the size of the dump is unknown.
+func (p ImportProgress) Percent() float64 {
+	if p.TotalBytes <= 0 {
+		return -1
+	}
+
+	return float64(p.ReadBytes) / float64(p.TotalBytes) * 100
+}
+
+type ImportOptions struct {
+	// ProgressInterval is the interval in which OnProgress is called
+	ProgressInterval time.Duration
+	OnProgress       func(progress ImportProgress)
+}
+
+// Import executes all statements of the dump on a single connection, so session variables like FOREIGN_KEY_CHECKS survive between statements.
+func Import(ctx context.Context, db *sql.DB, dump *DumpReader, options ImportOptions) (ImportProgress, error) {
+	progress := ImportProgress{TotalBytes: dump.Size}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return progress, fmt.Errorf("connect to database: %w", err)
+	}
+
+	defer conn.Close()
+
+	scanner := NewStatementScanner(dump)
+	lastReport := time.Now()
+
+	for {
+		statement, err := scanner.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return progress, fmt.Errorf("read dump: %w", err)
+		}
+
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return progress, fmt.Errorf("statement %d failed: %w\n%s", progress.Statements+1, err, truncateStatement(statement))
+		}
+
+		progress.Statements++
+		progress.ReadBytes = dump.ReadBytes()
+
+		if options.OnProgress != nil && time.Since(lastReport) >= options.ProgressInterval {
+			options.OnProgress(progress)
+			lastReport = time.Now()
+		}
+	}
+
+	progress.ReadBytes = dump.ReadBytes()
+
+	return progress, nil
+}
+
+func truncateStatement(statement string) string {
+	const maxLength = 200
+
+	if len(statement) <= maxLength {
+		return statement
+	}
+
+	return strings.TrimSpace(statement[:maxLength]) + "..."
+}
+
+// QuoteIdentifier quotes a table or database name for MySQL.
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// RecreateDatabase drops the database if it exists and creates it empty again.
+func RecreateDatabase(ctx context.Context, db *sql.DB, name string) error {
+	if _, err := db.ExecContext(ctx, "DROP DATABASE IF EXISTS "+QuoteIdentifier(name)); err != nil {
+		return fmt.Errorf("drop database %s: %w", name, err)
+	}
+
+	if _, err := db.ExecContext(ctx, "CREATE DATABASE "+QuoteIdentifier(name)+" DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci"); err != nil {
+		return fmt.Errorf("create database %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package dbdump
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MetadataSuffix is appended to the dump name for its sidecar metadata file.
+const MetadataSuffix = ".meta.json"
+
+// DumpMetadata describes a dump, so a restore can be matched to the code version it belongs to.
+type DumpMetadata struct {
+	CreatedAt       time.Time            `json:"created_at"`
+	Profile         string               `json:"profile,omitempty"`
+	Database        string               `json:"database"`
+	ShopwareVersion string               `json:"shopware_version,omitempty"`
+	Extensions      []InstalledExtension `json:"extensions"`
+	Format          string               `json:"format"`
+	Compression     string               `json:"compression,omitempty"`
+	// Files are the names of the dump files relative to the metadata file
+	Files []string `json:"files"`
+}
+
+// InstalledExtension is a plugin or app installed in the dumped database.
+type InstalledExtension struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Active  bool   `json:"active"`
+}
+
+// MetadataFileName returns the name of the sidecar metadata file of a dump file or directory.
+func MetadataFileName(dump string) string {
+	return strings.TrimSuffix(dump, string(filepath.Separator)) + MetadataSuffix
+}
+
+// LoadInstalledExtensions returns the plugins and apps of the Shopware database.
+func LoadInstalledExtensions(ctx context.Context, db *sql.DB) ([]InstalledExtension, error) {
+	extensions := make([]InstalledExtension, 0)
+
+	for extensionType, table := range map[string]string{"plugin": "plugin", "app": "app"} {
+		rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT name, version, active FROM %s ORDER BY name", QuoteIdentifier(table)))
+		if err != nil {
+			return nil, fmt.Errorf("load installed %ss: %w", extensionType, err)
+		}
+
+		for rows.Next() {
+			extension := InstalledExtension{Type: extensionType}
+
+			if err := rows.Scan(&extension.Name, &extension.Version, &extension.Active); err != nil {
+				_ = rows.Close()
+
+				return nil, err
+			}
+
+			extensions = append(extensions, extension)
+		}
+
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Plugins first, then apps
+	slices.SortFunc(extensions, func(a, b InstalledExtension) int {
+		return cmp.Or(-cmp.Compare(a.Type, b.Type), cmp.Compare(a.Name, b.Name))
+	})
+
+	return extensions, nil
+}
+
+// WriteMetadata writes the metadata as sidecar file next to the dump.
+func WriteMetadata(dump string, metadata DumpMetadata) (string, error) {
+	content, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal dump metadata: %w", err)
+	}
+
+	file := MetadataFileName(dump)
+
+	if err := os.WriteFile(file, content, 0o644); err != nil {
+		return "", fmt.Errorf("write dump metadata: %w", err)
+	}
+
+	return file, nil
+}
+
+// ParseMetadata parses the content of a sidecar metadata file.
+func ParseMetadata(content []byte) (*DumpMetadata, error) {
+	var metadata DumpMetadata
+
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return nil, fmt.Errorf("parse dump metadata: %w", err)
+	}
+
+	return &metadata, nil
+}
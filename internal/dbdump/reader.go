@@ -0,0 +1,150 @@
+package dbdump
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// DetectCompression detects the compression of a dump by its magic bytes.
+func DetectCompression(header []byte) string {
+	if bytes.HasPrefix(header, gzipMagic) {
+		return CompressionGzip
+	}
+
+	if bytes.HasPrefix(header, zstdMagic) {
+		return CompressionZstd
+	}
+
+	return CompressionNone
+}
+
+// DumpReader reads a plain, gzip or zstd compressed dump and keeps track of the consumed bytes of the underlying file.
+type DumpReader struct {
+	io.Reader
+	// Compression is the detected compression of the dump
+	Compression string
+	// Size is the size of the file or 0 when it is unknown, e.g. for stdin
+	Size int64
+
+	counter *countingReader
+	closers []func() error
+}
+
+// OpenDump opens the dump file, "-" reads the dump from stdin.
+func OpenDump(file string) (*DumpReader, error) {
+	var source io.ReadCloser = os.Stdin
+	var size int64
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+
+		stat, err := f.Stat()
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		source = f
+		size = stat.Size()
+	}
+
+	reader, err := NewDumpReader(source, size)
+	if err != nil {
+		_ = source.Close()
+		return nil, err
+	}
+
+	reader.closers = append(reader.closers, source.Close)
+
+	return reader, nil
+}
+
+// NewDumpReader wraps r and transparently decompresses it.
+func NewDumpReader(r io.Reader, size int64) (*DumpReader, error) {
+	counter := &countingReader{reader: r}
+	buffered := bufio.NewReaderSize(counter, 1024*1024)
+
+	// Peek returns less bytes for small files, which is fine for the detection
+	header, _ := buffered.Peek(len(zstdMagic))
+
+	reader := &DumpReader{
+		Reader:      buffered,
+		Compression: DetectCompression(header),
+		Size:        size,
+		counter:     counter,
+	}
+
+	switch reader.Compression {
+	case CompressionGzip:
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("open gzip dump: %w", err)
+		}
+
+		reader.Reader = gzipReader
+		reader.closers = append(reader.closers, gzipReader.Close)
+	case CompressionZstd:
+		zstdReader, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("open zstd dump: %w", err)
+		}
+
+		reader.Reader = zstdReader
+		reader.closers = append(reader.closers, func() error {
+			zstdReader.Close()
+			return nil
+		})
+	}
+
+	return reader, nil
+}
+
+// ReadBytes returns the amount of bytes read from the underlying file, which can be compared against Size.
+func (r *DumpReader) ReadBytes() int64 {
+	return r.counter.count.Load()
+}
+
+func (r *DumpReader) Close() error {
+	var firstErr error
+
+	for _, closer := range r.closers {
+		if err := closer(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count.Add(int64(n))
+
+	return n, err
+}
@@ -0,0 +1,47 @@
+package dbdump
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDumpReaderDetectsCompression(t *testing.T) {
+	const content = "SELECT 1;\n"
+
+	var gzipped bytes.Buffer
+	gzipWriter := gzip.NewWriter(&gzipped)
+	_, err := gzipWriter.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	var zstded bytes.Buffer
+	zstdWriter, err := zstd.NewWriter(&zstded)
+	require.NoError(t, err)
+	_, err = zstdWriter.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zstdWriter.Close())
+
+	cases := map[string][]byte{
+		CompressionNone: []byte(content),
+		CompressionGzip: gzipped.Bytes(),
+		CompressionZstd: zstded.Bytes(),
+	}
+
+	for compression, data := range cases {
+		reader, err := NewDumpReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+		assert.Equal(t, compression, reader.Compression)
+
+		decoded, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content, string(decoded))
+		assert.Equal(t, int64(len(data)), reader.ReadBytes())
+		assert.NoError(t, reader.Close())
+	}
+}
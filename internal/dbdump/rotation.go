@@ -0,0 +1,136 @@
+package dbdump
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+// RotationPolicy keeps the newest dump of the last Daily days and of the last Weekly ISO weeks.
+type RotationPolicy struct {
+	Daily  int
+	Weekly int
+}
+
+// Enabled reports whether the policy removes anything, a policy without limits keeps all dumps.
+func (p RotationPolicy) Enabled() bool {
+	return p.Daily > 0 || p.Weekly > 0
+}
+
+// StoredDump is a dump found in a target by its metadata file.
+type StoredDump struct {
+	MetadataFile string
+	Metadata     DumpMetadata
+}
+
+// SelectExpiredDumps returns the dumps which are not kept by the policy.
+// The days and weeks are counted by the existing dumps, so missed runs do not remove older dumps.
+func SelectExpiredDumps(dumps []StoredDump, policy RotationPolicy) []StoredDump {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	sorted := slices.Clone(dumps)
+	slices.SortStableFunc(sorted, func(a, b StoredDump) int {
+		return b.Metadata.CreatedAt.Compare(a.Metadata.CreatedAt)
+	})
+
+	keptDays := map[string]bool{}
+	keptWeeks := map[string]bool{}
+	expired := make([]StoredDump, 0)
+
+	for _, dump := range sorted {
+		createdAt := dump.Metadata.CreatedAt.UTC()
+		day := createdAt.Format(time.DateOnly)
+		year, week := createdAt.ISOWeek()
+		weekKey := fmt.Sprintf("%d-W%02d", year, week)
+
+		keep := false
+
+		if !keptDays[day] && len(keptDays) < policy.Daily {
+			keptDays[day] = true
+			keep = true
+		}
+
+		if !keptWeeks[weekKey] && len(keptWeeks) < policy.Weekly {
+			keptWeeks[weekKey] = true
+			keep = true
+		}
+
+		if !keep {
+			expired = append(expired, dump)
+		}
+	}
+
+	return expired
+}
+
+// RotateDumps removes the dumps of the same database and profile as current, which are expired by the policy.
+func RotateDumps(ctx context.Context, target Target, current DumpMetadata, policy RotationPolicy) ([]StoredDump, error) {
+	if !policy.Enabled() {
+		return nil, nil
+	}
+
+	names, err := target.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list dumps: %w", err)
+	}
+
+	dumps := make([]StoredDump, 0)
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, MetadataSuffix) {
+			continue
+		}
+
+		content, err := target.Read(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+
+		metadata, err := ParseMetadata(content)
+		if err != nil {
+			logging.FromContext(ctx).Warnf("Skipping %s in rotation: %s", target.Location(name), err.Error())
+
+			continue
+		}
+
+		if metadata.Database != current.Database || metadata.Profile != current.Profile {
+			continue
+		}
+
+		dumps = append(dumps, StoredDump{MetadataFile: name, Metadata: *metadata})
+	}
+
+	expired := SelectExpiredDumps(dumps, policy)
+
+	for _, dump := range expired {
+		base := path.Dir(dump.MetadataFile)
+
+		for _, file := range dump.Metadata.Files {
+			// Never follow paths out of the dump location
+			if !filepath.IsLocal(file) {
+				return nil, fmt.Errorf("dump metadata %s contains the invalid file %s", dump.MetadataFile, file)
+			}
+
+			if err := target.Delete(ctx, path.Join(base, file)); err != nil {
+				return nil, fmt.Errorf("delete %s: %w", file, err)
+			}
+		}
+
+		// The metadata is removed last, so an interrupted rotation is retried by the next run
+		if err := target.Delete(ctx, dump.MetadataFile); err != nil {
+			return nil, fmt.Errorf("delete %s: %w", dump.MetadataFile, err)
+		}
+
+		logging.FromContext(ctx).Infof("Removed expired dump %s", target.Location(strings.TrimSuffix(dump.MetadataFile, MetadataSuffix)))
+	}
+
+	return expired, nil
+}
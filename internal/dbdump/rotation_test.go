@@ -0,0 +1,104 @@
+package dbdump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func storedDumpAt(name string, createdAt time.Time) StoredDump {
+	return StoredDump{MetadataFile: name + MetadataSuffix, Metadata: DumpMetadata{CreatedAt: createdAt, Database: "shopware", Files: []string{name}}}
+}
+
+func storedNames(dumps []StoredDump) []string {
+	names := make([]string, 0, len(dumps))
+
+	for _, dump := range dumps {
+		names = append(names, dump.Metadata.Files[0])
+	}
+
+	return names
+}
+
+func TestSelectExpiredDumps(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		// 2024-01-01 is a Monday
+		return time.Date(2024, 1, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	dumps := []StoredDump{
+		storedDumpAt("jan-01", day(1, 2)),
+		storedDumpAt("jan-07", day(7, 2)),
+		storedDumpAt("jan-08", day(8, 2)),
+		storedDumpAt("jan-14", day(14, 2)),
+		storedDumpAt("jan-15-early", day(15, 2)),
+		storedDumpAt("jan-15", day(15, 14)),
+		storedDumpAt("jan-16", day(16, 2)),
+	}
+
+	expired := SelectExpiredDumps(dumps, RotationPolicy{Daily: 2})
+	assert.Equal(t, []string{"jan-15-early", "jan-14", "jan-08", "jan-07", "jan-01"}, storedNames(expired))
+
+	// Weekly keeps the newest dump of the ISO week of jan-16, jan-14 and jan-07
+	expired = SelectExpiredDumps(dumps, RotationPolicy{Daily: 1, Weekly: 3})
+	assert.Equal(t, []string{"jan-15", "jan-15-early", "jan-08", "jan-01"}, storedNames(expired))
+
+	assert.Empty(t, SelectExpiredDumps(dumps, RotationPolicy{}))
+}
+
+func TestRotateDumpsInLocalTarget(t *testing.T) {
+	dir := t.TempDir()
+	target := LocalTarget{Dir: dir}
+
+	write := func(name string, metadata DumpMetadata) {
+		t.Helper()
+
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), os.ModePerm))
+		require.NoError(t, os.WriteFile(file, []byte("dump"), 0o644))
+
+		metadata.Database = "shopware"
+		metadata.Files = []string{name}
+
+		_, err := WriteMetadata(file, metadata)
+		require.NoError(t, err)
+	}
+
+	now := time.Date(2024, 1, 10, 2, 0, 0, 0, time.UTC)
+
+	write("old.sql.zst", DumpMetadata{CreatedAt: now.AddDate(0, 0, -2), Profile: "nightly"})
+	write("yesterday/manifest.json", DumpMetadata{CreatedAt: now.AddDate(0, 0, -1), Profile: "nightly"})
+	write("today.sql.zst", DumpMetadata{CreatedAt: now, Profile: "nightly"})
+	write("other.sql.zst", DumpMetadata{CreatedAt: now.AddDate(0, 0, -5), Profile: "dev"})
+
+	// Rotation only reads the sidecar files at the top of the target
+	require.NoError(t, os.Rename(filepath.Join(dir, "yesterday", "manifest.json"+MetadataSuffix), filepath.Join(dir, "yesterday"+MetadataSuffix)))
+
+	expired, err := RotateDumps(t.Context(), target, DumpMetadata{Database: "shopware", Profile: "nightly"}, RotationPolicy{Daily: 1})
+	require.NoError(t, err)
+	assert.Len(t, expired, 2)
+
+	assert.NoFileExists(t, filepath.Join(dir, "old.sql.zst"))
+	assert.NoFileExists(t, filepath.Join(dir, "old.sql.zst"+MetadataSuffix))
+	assert.NoDirExists(t, filepath.Join(dir, "yesterday"))
+	assert.NoFileExists(t, filepath.Join(dir, "yesterday"+MetadataSuffix))
+	assert.FileExists(t, filepath.Join(dir, "today.sql.zst"))
+	assert.FileExists(t, filepath.Join(dir, "other.sql.zst"))
+}
+
+func TestRotateDumpsRejectsFilesOutsideOfTarget(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := WriteMetadata(filepath.Join(dir, "evil"), DumpMetadata{Database: "shopware", Files: []string{"../outside"}})
+	require.NoError(t, err)
+
+	_, err = WriteMetadata(filepath.Join(dir, "current"), DumpMetadata{Database: "shopware", CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	_, err = RotateDumps(t.Context(), LocalTarget{Dir: dir}, DumpMetadata{Database: "shopware"}, RotationPolicy{Daily: 1})
+	assert.ErrorContains(t, err, "invalid file")
+}
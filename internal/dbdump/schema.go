@@ -0,0 +1,255 @@
+package dbdump
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	createTableIndexLine      = regexp.MustCompile(`^\s*(UNIQUE KEY|KEY|FULLTEXT KEY|SPATIAL KEY)\s`)
+	createTableConstraintLine = regexp.MustCompile(`^\s*CONSTRAINT\s`)
+	createTablePrimaryKeyLine = regexp.MustCompile(`^\s*PRIMARY KEY\s*\((.+)\)`)
+	createTableColumnLine     = regexp.MustCompile("^\\s*`([^`]+)`\\s")
+)
+
+// DeferredCreateTable is a CREATE TABLE statement split into the parts which can be created after the data is loaded.
+type DeferredCreateTable struct {
+	// Create contains the columns and the primary key
+	Create string
+	// Indexes are the secondary index definitions, e.g. KEY `idx.name` (`name`)
+	Indexes []string
+	// Constraints are the foreign key definitions
+	Constraints []string
+}
+
+// SplitCreateTable separates secondary indexes and foreign keys from the CREATE TABLE statement of SHOW CREATE TABLE.
+// Indexes are kept in the statement, when an auto increment column is not part of the primary key and therefore requires one of them.
+func SplitCreateTable(statement string) DeferredCreateTable {
+	lines := strings.Split(statement, "\n")
+	if len(lines) < 3 {
+		return DeferredCreateTable{Create: statement}
+	}
+
+	header, footer := lines[0], lines[len(lines)-1]
+	definitions := lines[1 : len(lines)-1]
+
+	split := DeferredCreateTable{}
+	kept := make([]string, 0, len(definitions))
+	indexes := make([]string, 0)
+
+	var primaryKey, autoIncrementColumn string
+
+	for _, line := range definitions {
+		definition := strings.TrimSuffix(strings.TrimSpace(line), ",")
+
+		switch {
+		case createTableConstraintLine.MatchString(definition):
+			split.Constraints = append(split.Constraints, definition)
+		case createTableIndexLine.MatchString(definition):
+			indexes = append(indexes, definition)
+		default:
+			if matches := createTablePrimaryKeyLine.FindStringSubmatch(definition); matches != nil {
+				primaryKey = matches[1]
+			}
+
+			if matches := createTableColumnLine.FindStringSubmatch(definition); matches != nil && strings.Contains(definition, "AUTO_INCREMENT") {
+				autoIncrementColumn = matches[1]
+			}
+
+			kept = append(kept, definition)
+		}
+	}
+
+	if autoIncrementColumn != "" && !strings.Contains(primaryKey, QuoteIdentifier(autoIncrementColumn)) {
+		kept = append(kept, indexes...)
+	} else {
+		split.Indexes = indexes
+	}
+
+	split.Create = header + "\n  " + strings.Join(kept, ",\n  ") + "\n" + footer
+
+	return split
+}
+
+// AddDefinitionsStatement returns an ALTER TABLE statement adding all index or constraint definitions at once.
+func AddDefinitionsStatement(table string, definitions []string) string {
+	var buf bytes.Buffer
+
+	buf.WriteString("ALTER TABLE ")
+	buf.WriteString(QuoteIdentifier(table))
+
+	for i, definition := range definitions {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+
+		buf.WriteString(" ADD ")
+		buf.WriteString(definition)
+	}
+
+	return buf.String()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ShowCreateTable returns the CREATE TABLE statement of the table.
+func ShowCreateTable(ctx context.Context, db queryer, table string) (string, error) {
+	var name, statement string
+
+	if err := db.QueryRowContext(ctx, "SHOW CREATE TABLE "+QuoteIdentifier(table)).Scan(&name, &statement); err != nil {
+		return "", fmt.Errorf("show create table %s: %w", table, err)
+	}
+
+	return statement, nil
+}
+
+// ListTables returns the base tables of the current database.
+func ListTables(ctx context.Context, db queryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SHOW FULL TABLES")
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	defer rows.Close()
+
+	tables := make([]string, 0)
+
+	for rows.Next() {
+		var name, tableType string
+
+		if err := rows.Scan(&name, &tableType); err != nil {
+			return nil, err
+		}
+
+		if tableType == "BASE TABLE" {
+			tables = append(tables, name)
+		}
+	}
+
+	return tables, rows.Err()
+}
+
+// ListViews returns the views of the current database.
+func ListViews(ctx context.Context, db queryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SHOW FULL TABLES WHERE Table_type = 'VIEW'")
+	if err != nil {
+		return nil, fmt.Errorf("list views: %w", err)
+	}
+
+	defer rows.Close()
+
+	views := make([]string, 0)
+
+	for rows.Next() {
+		var name, tableType string
+
+		if err := rows.Scan(&name, &tableType); err != nil {
+			return nil, err
+		}
+
+		views = append(views, name)
+	}
+
+	return views, rows.Err()
+}
+
+// ShowCreateView returns the CREATE VIEW statement of the view without definer, so it can be created by any user.
+func ShowCreateView(ctx context.Context, db queryer, view string) (string, error) {
+	var name, statement, charset, collation string
+
+	if err := db.QueryRowContext(ctx, "SHOW CREATE VIEW "+QuoteIdentifier(view)).Scan(&name, &statement, &charset, &collation); err != nil {
+		return "", fmt.Errorf("show create view %s: %w", view, err)
+	}
+
+	return stripViewDefiner(statement), nil
+}
+
+func stripViewDefiner(statement string) string {
+	return viewDefiner.ReplaceAllString(statement, " ")
+}
+
+// ListTriggers returns the CREATE TRIGGER statements of the current database without definer.
+func ListTriggers(ctx context.Context, db queryer) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SHOW TRIGGERS")
+	if err != nil {
+		return nil, fmt.Errorf("list triggers: %w", err)
+	}
+
+	names := make([]string, 0)
+
+	for rows.Next() {
+		columns, err := rows.Columns()
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		values := make([]sql.RawBytes, len(columns))
+		scanArgs := make([]any, len(values))
+
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		if err := rows.Scan(scanArgs...); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		names = append(names, string(values[0]))
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	triggers := make([]string, 0, len(names))
+
+	for _, name := range names {
+		triggerRows, err := db.QueryContext(ctx, "SHOW CREATE TRIGGER "+QuoteIdentifier(name))
+		if err != nil {
+			return nil, fmt.Errorf("show create trigger %s: %w", name, err)
+		}
+
+		columns, err := triggerRows.Columns()
+		if err != nil {
+			triggerRows.Close()
+			return nil, err
+		}
+
+		values := make([]sql.NullString, len(columns))
+		scanArgs := make([]any, len(values))
+
+		for i := range values {
+			scanArgs[i] = &values[i]
+		}
+
+		if triggerRows.Next() {
+			if err := triggerRows.Scan(scanArgs...); err != nil {
+				triggerRows.Close()
+				return nil, err
+			}
+
+			// The third column is the statement: Trigger, sql_mode, SQL Original Statement, ...
+			triggers = append(triggers, triggerDefiner.ReplaceAllString(values[2].String, "CREATE "))
+		}
+
+		triggerRows.Close()
+	}
+
+	return triggers, nil
+}
+
+var (
+	triggerDefiner = regexp.MustCompile("^CREATE\\s+DEFINER=\\S+\\s+")
+	viewDefiner    = regexp.MustCompile(`\s+DEFINER=\S+\s+(SQL SECURITY DEFINER\s+)?`)
+)
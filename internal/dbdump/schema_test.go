@@ -0,0 +1,58 @@
+package dbdump
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCreateTable(t *testing.T) {
+	statement := "CREATE TABLE `order_line_item` (\n" +
+		"  `id` binary(16) NOT NULL,\n" +
+		"  `order_id` binary(16) NOT NULL,\n" +
+		"  `label` varchar(255) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  KEY `idx.order_id` (`order_id`),\n" +
+		"  UNIQUE KEY `uniq.label` (`label`),\n" +
+		"  CONSTRAINT `fk.order_line_item.order_id` FOREIGN KEY (`order_id`) REFERENCES `order` (`id`) ON DELETE CASCADE\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+
+	split := SplitCreateTable(statement)
+
+	assert.Equal(t, "CREATE TABLE `order_line_item` (\n"+
+		"  `id` binary(16) NOT NULL,\n"+
+		"  `order_id` binary(16) NOT NULL,\n"+
+		"  `label` varchar(255) NOT NULL,\n"+
+		"  PRIMARY KEY (`id`)\n"+
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4", split.Create)
+	assert.Equal(t, []string{"KEY `idx.order_id` (`order_id`)", "UNIQUE KEY `uniq.label` (`label`)"}, split.Indexes)
+	assert.Equal(t, []string{"CONSTRAINT `fk.order_line_item.order_id` FOREIGN KEY (`order_id`) REFERENCES `order` (`id`) ON DELETE CASCADE"}, split.Constraints)
+
+	assert.Equal(t, "ALTER TABLE `order_line_item` ADD KEY `idx.order_id` (`order_id`), ADD UNIQUE KEY `uniq.label` (`label`)", AddDefinitionsStatement("order_line_item", split.Indexes))
+}
+
+func TestSplitCreateTableKeepsAutoIncrementKey(t *testing.T) {
+	statement := "CREATE TABLE `product` (\n" +
+		"  `id` binary(16) NOT NULL,\n" +
+		"  `auto_increment` int NOT NULL AUTO_INCREMENT,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `auto_increment` (`auto_increment`)\n" +
+		") ENGINE=InnoDB"
+
+	split := SplitCreateTable(statement)
+
+	assert.Contains(t, split.Create, "UNIQUE KEY `auto_increment` (`auto_increment`)")
+	assert.Empty(t, split.Indexes)
+}
+
+func TestStripViewDefiner(t *testing.T) {
+	assert.Equal(t,
+		"CREATE ALGORITHM=UNDEFINED VIEW `active_product` AS select `product`.`id` AS `id` from `product`",
+		stripViewDefiner("CREATE ALGORITHM=UNDEFINED DEFINER=`shopware`@`%` SQL SECURITY DEFINER VIEW `active_product` AS select `product`.`id` AS `id` from `product`"),
+	)
+
+	assert.Equal(t,
+		"CREATE ALGORITHM=MERGE SQL SECURITY INVOKER VIEW `v` AS select 1 AS `1`",
+		stripViewDefiner("CREATE ALGORITHM=MERGE DEFINER=`root`@`localhost` SQL SECURITY INVOKER VIEW `v` AS select 1 AS `1`"),
+	)
+}
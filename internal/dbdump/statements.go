@@ -0,0 +1,202 @@
+package dbdump
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+const defaultDelimiter = ";"
+
+// StatementScanner splits a SQL dump into single statements.
+// It understands quoted strings, comments and the DELIMITER command of the mysql client.
+type StatementScanner struct {
+	reader    *bufio.Reader
+	delimiter string
+	statement strings.Builder
+	err       error
+}
+
+func NewStatementScanner(r io.Reader) *StatementScanner {
+	return &StatementScanner{
+		reader:    bufio.NewReaderSize(r, 1024*1024),
+		delimiter: defaultDelimiter,
+	}
+}
+
+// Next returns the next statement without delimiter. It returns io.EOF after the last statement.
+func (s *StatementScanner) Next() (string, error) {
+	for {
+		statement, err := s.scan()
+		if err != nil {
+			return "", err
+		}
+
+		statement = strings.TrimSpace(statement)
+
+		if statement == "" {
+			continue
+		}
+
+		if delimiter, ok := parseDelimiterCommand(statement); ok {
+			s.delimiter = delimiter
+			continue
+		}
+
+		return statement, nil
+	}
+}
+
+func parseDelimiterCommand(statement string) (string, bool) {
+	if len(statement) < 10 || !strings.EqualFold(statement[:10], "DELIMITER ") {
+		return "", false
+	}
+
+	delimiter := strings.TrimSpace(statement[10:])
+
+	return delimiter, delimiter != ""
+}
+
+// scan reads until the current delimiter outside of quotes and comments.
+func (s *StatementScanner) scan() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	s.statement.Reset()
+
+	var quote byte
+	escaped := false
+	atLineStart := true
+	hasContent := false
+
+	for {
+		c, err := s.reader.ReadByte()
+		if err != nil {
+			s.err = err
+
+			if s.statement.Len() > 0 {
+				return s.statement.String(), nil
+			}
+
+			return "", err
+		}
+
+		if quote != 0 {
+			s.statement.WriteByte(c)
+
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\' && quote != '`':
+				escaped = true
+			case c == quote:
+				quote = 0
+			}
+
+			continue
+		}
+
+		// DELIMITER is a client command and ends at the line end
+		if atLineStart && !hasContent && (c == 'D' || c == 'd') {
+			if line, ok := s.readDelimiterLine(c); ok {
+				return line, nil
+			}
+		}
+
+		atLineStart = c == '\n'
+
+		switch c {
+		case '\'', '"', '`':
+			quote = c
+		case '-':
+			if next, _ := s.reader.Peek(2); len(next) == 2 && next[0] == '-' && (next[1] == ' ' || next[1] == '\t' || next[1] == '\n' || next[1] == '\r') {
+				s.skipLine()
+				atLineStart = true
+
+				continue
+			}
+		case '#':
+			s.skipLine()
+			atLineStart = true
+
+			continue
+		case '/':
+			if next, _ := s.reader.Peek(1); len(next) == 1 && next[0] == '*' {
+				s.readBlockComment()
+
+				continue
+			}
+		}
+
+		s.statement.WriteByte(c)
+		hasContent = hasContent || !isSpace(c)
+
+		if s.endsWithDelimiter() {
+			statement := s.statement.String()
+
+			return statement[:len(statement)-len(s.delimiter)], nil
+		}
+	}
+}
+
+func (s *StatementScanner) readDelimiterLine(first byte) (string, bool) {
+	peek, _ := s.reader.Peek(9)
+	if len(peek) < 9 || !strings.EqualFold(string(first)+string(peek), "DELIMITER ") {
+		return "", false
+	}
+
+	line, err := s.reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		s.err = err
+	}
+
+	return string(first) + line, true
+}
+
+func (s *StatementScanner) skipLine() {
+	if _, err := s.reader.ReadString('\n'); err != nil {
+		s.err = err
+	}
+}
+
+// readBlockComment keeps executable comments like /*!40101 SET NAMES utf8mb4 */ and drops regular ones.
+func (s *StatementScanner) readBlockComment() {
+	var comment bytes.Buffer
+	comment.WriteByte('/')
+
+	var previous byte
+
+	for {
+		c, err := s.reader.ReadByte()
+		if err != nil {
+			s.err = err
+			return
+		}
+
+		comment.WriteByte(c)
+
+		if previous == '*' && c == '/' && comment.Len() > 3 {
+			break
+		}
+
+		previous = c
+	}
+
+	if bytes.HasPrefix(comment.Bytes(), []byte("/*!")) {
+		s.statement.Write(comment.Bytes())
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (s *StatementScanner) endsWithDelimiter() bool {
+	if s.statement.Len() < len(s.delimiter) {
+		return false
+	}
+
+	return strings.HasSuffix(s.statement.String(), s.delimiter)
+}
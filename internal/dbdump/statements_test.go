@@ -0,0 +1,59 @@
+package dbdump
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectStatements(t *testing.T, dump string) []string {
+	t.Helper()
+
+	scanner := NewStatementScanner(strings.NewReader(dump))
+	statements := make([]string, 0)
+
+	for {
+		statement, err := scanner.Next()
+		if errors.Is(err, io.EOF) {
+			return statements
+		}
+
+		require.NoError(t, err)
+
+		statements = append(statements, statement)
+	}
+}
+
+func TestStatementScanner(t *testing.T) {
+	dump := `-- MySQL dump
+/*!40101 SET NAMES utf8mb4 */;
+/* regular comment */
+SET FOREIGN_KEY_CHECKS = 0;
+
+# hash comment
+INSERT INTO ` + "`product`" + ` VALUES ('a;b', "it\'s; fine", 'it''s'),
+('-- no comment', '/* no comment */');
+
+--
+-- Trigger ` + "`order_trigger`" + `
+--
+
+DELIMITER //
+CREATE TRIGGER order_trigger BEFORE INSERT ON ` + "`order`" + ` FOR EACH ROW BEGIN SET NEW.a = 1; SET NEW.b = 2; END//
+DELIMITER ;
+SET FOREIGN_KEY_CHECKS = 1;
+SELECT 1`
+
+	assert.Equal(t, []string{
+		"/*!40101 SET NAMES utf8mb4 */",
+		"SET FOREIGN_KEY_CHECKS = 0",
+		"INSERT INTO `product` VALUES ('a;b', \"it\\'s; fine\", 'it''s'),\n('-- no comment', '/* no comment */')",
+		"CREATE TRIGGER order_trigger BEFORE INSERT ON `order` FOR EACH ROW BEGIN SET NEW.a = 1; SET NEW.b = 2; END",
+		"SET FOREIGN_KEY_CHECKS = 1",
+		"SELECT 1",
+	}, collectStatements(t, dump))
+}
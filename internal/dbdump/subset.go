@@ -0,0 +1,318 @@
+package dbdump
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ForeignKey is a foreign key constraint, the columns are in the order of the constraint.
+type ForeignKey struct {
+	Name              string
+	Table             string
+	Columns           []string
+	ReferencedTable   string
+	ReferencedColumns []string
+}
+
+// LoadForeignKeys reads all foreign keys of the schema from information_schema.
+func LoadForeignKeys(ctx context.Context, db *sql.DB, schema string) ([]ForeignKey, error) {
+	rows, err := db.QueryContext(ctx, `SELECT CONSTRAINT_NAME, TABLE_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME
+FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = ? AND REFERENCED_TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION`, schema, schema)
+	if err != nil {
+		return nil, fmt.Errorf("load foreign keys: %w", err)
+	}
+
+	defer rows.Close()
+
+	keys := make([]ForeignKey, 0)
+
+	for rows.Next() {
+		var name, table, column, referencedTable, referencedColumn string
+
+		if err := rows.Scan(&name, &table, &column, &referencedTable, &referencedColumn); err != nil {
+			return nil, err
+		}
+
+		if len(keys) > 0 && keys[len(keys)-1].Name == name && keys[len(keys)-1].Table == table {
+			last := &keys[len(keys)-1]
+			last.Columns = append(last.Columns, column)
+			last.ReferencedColumns = append(last.ReferencedColumns, referencedColumn)
+
+			continue
+		}
+
+		keys = append(keys, ForeignKey{
+			Name:              name,
+			Table:             table,
+			Columns:           []string{column},
+			ReferencedTable:   referencedTable,
+			ReferencedColumns: []string{referencedColumn},
+		})
+	}
+
+	return keys, rows.Err()
+}
+
+// BuildSubsetWhere builds where conditions for a referentially consistent subset of the database.
+//
+// The root tables are filtered by their where condition. Tables referencing a filtered table only keep rows
+// pointing to exported rows. Parent tables are reduced to the rows referenced by the subset, when all tables
+// referencing them are part of the subset, otherwise they are exported completely.
+// Self references and foreign key cycles are not followed.
+//
+// The condition of each table is built once. Conditions depending on tables further away are not inlined,
+// the selected rows of those tables are common table expressions, so the conditions do not grow exponentially
+// with foreign key graphs where a table is reachable on several paths.
+func BuildSubsetWhere(roots map[string]string, keys []ForeignKey) map[string]string {
+	children := make(map[string][]ForeignKey)
+	parents := make(map[string][]ForeignKey)
+
+	for _, key := range keys {
+		if key.Table == key.ReferencedTable {
+			continue
+		}
+
+		children[key.ReferencedTable] = append(children[key.ReferencedTable], key)
+		parents[key.Table] = append(parents[key.Table], key)
+	}
+
+	// Collect all tables reachable through foreign keys from the roots
+	filtered := make(map[string]bool)
+	queue := slices.Sorted(maps.Keys(roots))
+
+	for _, root := range queue {
+		filtered[root] = true
+	}
+
+	for len(queue) > 0 {
+		table := queue[0]
+		queue = queue[1:]
+
+		for _, key := range children[table] {
+			if !filtered[key.Table] {
+				filtered[key.Table] = true
+				queue = append(queue, key.Table)
+			}
+		}
+	}
+
+	builder := subsetBuilder{
+		roots:      roots,
+		children:   children,
+		parents:    parents,
+		filtered:   filtered,
+		conditions: make(map[string]subsetCondition),
+		inProgress: make(map[string]bool),
+		rendered:   make(map[string]string),
+	}
+
+	for _, table := range slices.Sorted(maps.Keys(filtered)) {
+		builder.condition(table)
+	}
+
+	// Reduce parent tables which are only referenced by the subset
+	for changed := true; changed; {
+		changed = false
+
+		for _, table := range slices.Sorted(maps.Keys(children)) {
+			if filtered[table] || !builder.onlyReferencedBySubset(table) {
+				continue
+			}
+
+			filtered[table] = true
+			builder.conditions[table] = builder.referencedRowsCondition(table)
+			changed = true
+		}
+	}
+
+	where := make(map[string]string, len(builder.conditions))
+
+	for table := range builder.conditions {
+		where[table] = builder.render(table, false)
+	}
+
+	return where
+}
+
+// subsetCondition is the where condition of a table, either the condition of a root or parts joined by AND or OR.
+type subsetCondition struct {
+	expression string
+	parts      []subsetPart
+	operator   string
+}
+
+// subsetPart is a condition on the selected rows of another table, format contains the subquery as %s.
+type subsetPart struct {
+	format  string
+	table   string
+	columns []string
+}
+
+type subsetBuilder struct {
+	roots      map[string]string
+	children   map[string][]ForeignKey
+	parents    map[string][]ForeignKey
+	filtered   map[string]bool
+	conditions map[string]subsetCondition
+	inProgress map[string]bool
+	// rendered contains the conditions referencing the common table expressions of other tables
+	rendered map[string]string
+}
+
+// condition builds the where condition of a table reached from the roots, all referenced filtered tables must contain the row.
+func (b *subsetBuilder) condition(table string) {
+	if _, ok := b.conditions[table]; ok {
+		return
+	}
+
+	if root, ok := b.roots[table]; ok {
+		b.conditions[table] = subsetCondition{expression: root}
+
+		return
+	}
+
+	b.inProgress[table] = true
+	defer delete(b.inProgress, table)
+
+	parts := make([]subsetPart, 0)
+
+	for _, key := range b.parents[table] {
+		if !b.filtered[key.ReferencedTable] || b.inProgress[key.ReferencedTable] {
+			continue
+		}
+
+		b.condition(key.ReferencedTable)
+
+		parts = append(parts, subsetPart{
+			format:  fmt.Sprintf("(%s IS NULL OR %s IN (%%s))", QuoteIdentifier(key.Columns[0]), quoteColumns(key.Columns)),
+			table:   key.ReferencedTable,
+			columns: key.ReferencedColumns,
+		})
+	}
+
+	b.conditions[table] = subsetCondition{parts: parts, operator: " AND "}
+}
+
+func (b *subsetBuilder) onlyReferencedBySubset(table string) bool {
+	for _, key := range b.children[table] {
+		if !b.filtered[key.Table] {
+			return false
+		}
+	}
+
+	return len(b.children[table]) > 0
+}
+
+// referencedRowsCondition keeps the rows of a parent table which are referenced by any row of the subset.
+func (b *subsetBuilder) referencedRowsCondition(table string) subsetCondition {
+	parts := make([]subsetPart, 0, len(b.children[table]))
+
+	for _, key := range b.children[table] {
+		parts = append(parts, subsetPart{
+			format:  fmt.Sprintf("%s IN (%%s)", quoteColumns(key.ReferencedColumns)),
+			table:   key.Table,
+			columns: key.Columns,
+		})
+	}
+
+	return subsetCondition{parts: parts, operator: " OR "}
+}
+
+// render returns the condition of the table. Inside common table expressions the other tables are referenced by the name of their expression.
+func (b *subsetBuilder) render(table string, inExpression bool) string {
+	if inExpression {
+		if rendered, ok := b.rendered[table]; ok {
+			return rendered
+		}
+	}
+
+	condition := b.conditions[table]
+	result := condition.expression
+
+	if condition.expression == "" {
+		parts := make([]string, 0, len(condition.parts))
+
+		for _, part := range condition.parts {
+			columns := strings.Join(quoteIdentifiers(part.columns), ", ")
+
+			if inExpression {
+				parts = append(parts, fmt.Sprintf(part.format, fmt.Sprintf("SELECT %s FROM %s", columns, subsetExpressionName(part.table))))
+			} else {
+				parts = append(parts, fmt.Sprintf(part.format, b.subquery(part.table, columns)))
+			}
+		}
+
+		result = strings.Join(parts, condition.operator)
+		if result == "" {
+			result = "1 = 1"
+		}
+	}
+
+	if inExpression {
+		b.rendered[table] = result
+	}
+
+	return result
+}
+
+// subquery selects the columns of the rows of the table which are part of the subset, the tables it depends on are common table expressions.
+func (b *subsetBuilder) subquery(table, columns string) string {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s", columns, QuoteIdentifier(table), b.render(table, true))
+
+	dependencies := b.dependencies(table, make(map[string]bool), nil)
+	if len(dependencies) == 0 {
+		return query
+	}
+
+	expressions := make([]string, 0, len(dependencies))
+
+	for _, dependency := range dependencies {
+		expressions = append(expressions, fmt.Sprintf("%s AS (SELECT * FROM %s WHERE %s)", subsetExpressionName(dependency), QuoteIdentifier(dependency), b.render(dependency, true)))
+	}
+
+	return "WITH " + strings.Join(expressions, ", ") + " " + query
+}
+
+// dependencies returns the tables the condition of the table depends on, a table is always listed after its own dependencies.
+func (b *subsetBuilder) dependencies(table string, seen map[string]bool, result []string) []string {
+	for _, part := range b.conditions[table].parts {
+		if seen[part.table] {
+			continue
+		}
+
+		seen[part.table] = true
+		result = b.dependencies(part.table, seen, result)
+		result = append(result, part.table)
+	}
+
+	return result
+}
+
+func subsetExpressionName(table string) string {
+	return QuoteIdentifier("subset_" + table)
+}
+
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+
+	for i, name := range names {
+		quoted[i] = QuoteIdentifier(name)
+	}
+
+	return quoted
+}
+
+// quoteColumns returns a single column or a row constructor for composite keys.
+func quoteColumns(columns []string) string {
+	if len(columns) == 1 {
+		return QuoteIdentifier(columns[0])
+	}
+
+	return "(" + strings.Join(quoteIdentifiers(columns), ", ") + ")"
+}
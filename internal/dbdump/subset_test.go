@@ -0,0 +1,71 @@
+package dbdump
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSubsetWhere(t *testing.T) {
+	keys := []ForeignKey{
+		{Name: "fk.order_line_item.order_id", Table: "order_line_item", Columns: []string{"order_id", "order_version_id"}, ReferencedTable: "order", ReferencedColumns: []string{"id", "version_id"}},
+		{Name: "fk.order_line_item.product_id", Table: "order_line_item", Columns: []string{"product_id"}, ReferencedTable: "product", ReferencedColumns: []string{"id"}},
+		{Name: "fk.product_translation.product_id", Table: "product_translation", Columns: []string{"product_id"}, ReferencedTable: "product", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order_line_item.parent_id", Table: "order_line_item", Columns: []string{"parent_id"}, ReferencedTable: "order_line_item", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order_line_item_download.order_line_item_id", Table: "order_line_item_download", Columns: []string{"order_line_item_id"}, ReferencedTable: "order_line_item", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order_delivery.order_id", Table: "order_delivery", Columns: []string{"order_id"}, ReferencedTable: "order", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order.document_id", Table: "order", Columns: []string{"document_id"}, ReferencedTable: "document", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order_delivery.shipping_method_id", Table: "order_delivery", Columns: []string{"shipping_method_id"}, ReferencedTable: "shipping_method", ReferencedColumns: []string{"id"}},
+		{Name: "fk.order_delivery.delivery_note_id", Table: "order_delivery", Columns: []string{"delivery_note_id"}, ReferencedTable: "delivery_note", ReferencedColumns: []string{"id"}},
+		{Name: "fk.shipping_method_translation.shipping_method_id", Table: "shipping_method_translation", Columns: []string{"shipping_method_id"}, ReferencedTable: "shipping_method", ReferencedColumns: []string{"id"}},
+	}
+
+	where := BuildSubsetWhere(map[string]string{"order": "created_at > '2024-01-01'"}, keys)
+
+	orderCondition := "created_at > '2024-01-01'"
+	lineItemCondition := "(`order_id` IS NULL OR (`order_id`, `order_version_id`) IN (SELECT `id`, `version_id` FROM `order` WHERE " + orderCondition + "))"
+	deliveryCondition := "(`order_id` IS NULL OR `order_id` IN (SELECT `id` FROM `order` WHERE " + orderCondition + "))"
+	// Tables further away than a direct parent are common table expressions
+	orderExpression := "`subset_order` AS (SELECT * FROM `order` WHERE " + orderCondition + ")"
+
+	assert.Equal(t, map[string]string{
+		"order":           orderCondition,
+		"order_line_item": lineItemCondition,
+		"order_line_item_download": "(`order_line_item_id` IS NULL OR `order_line_item_id` IN (WITH " + orderExpression + " SELECT `id` FROM `order_line_item` WHERE " +
+			"(`order_id` IS NULL OR (`order_id`, `order_version_id`) IN (SELECT `id`, `version_id` FROM `subset_order`))))",
+		"order_delivery": deliveryCondition,
+		"document":       "`id` IN (SELECT `document_id` FROM `order` WHERE " + orderCondition + ")",
+		"delivery_note": "`id` IN (WITH " + orderExpression + " SELECT `delivery_note_id` FROM `order_delivery` WHERE " +
+			"(`order_id` IS NULL OR `order_id` IN (SELECT `id` FROM `subset_order`)))",
+	}, where)
+}
+
+func TestBuildSubsetWhereDiamond(t *testing.T) {
+	// Every level is reachable on two paths, inlining the conditions would double their size on each level
+	keys := make([]ForeignKey, 0)
+	previous := "level_0"
+
+	for i := 1; i <= 20; i++ {
+		left, right, level := fmt.Sprintf("left_%d", i), fmt.Sprintf("right_%d", i), fmt.Sprintf("level_%d", i)
+
+		keys = append(keys,
+			ForeignKey{Name: "fk." + left, Table: left, Columns: []string{"parent_id"}, ReferencedTable: previous, ReferencedColumns: []string{"id"}},
+			ForeignKey{Name: "fk." + right, Table: right, Columns: []string{"parent_id"}, ReferencedTable: previous, ReferencedColumns: []string{"id"}},
+			ForeignKey{Name: "fk." + level + ".left", Table: level, Columns: []string{"left_id"}, ReferencedTable: left, ReferencedColumns: []string{"id"}},
+			ForeignKey{Name: "fk." + level + ".right", Table: level, Columns: []string{"right_id"}, ReferencedTable: right, ReferencedColumns: []string{"id"}},
+		)
+
+		previous = level
+	}
+
+	where := BuildSubsetWhere(map[string]string{"level_0": "id = 1"}, keys)
+
+	condition := where["level_20"]
+	assert.Less(t, len(condition), 50000)
+
+	// Every table of the graph is selected once per subquery
+	assert.Equal(t, 2, strings.Count(condition, "`subset_level_0` AS"))
+	assert.Equal(t, 2, strings.Count(condition, "`subset_level_19` AS"))
+}
@@ -0,0 +1,176 @@
+package dbdump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/shopware/shopware-cli/internal/s3"
+)
+
+// Target stores finished dumps. Names are slash separated and relative to the root of the target.
+type Target interface {
+	// Store moves the local file into the target
+	Store(ctx context.Context, localFile, name string) error
+	// List returns the names of all files in the target
+	List(ctx context.Context) ([]string, error)
+	Read(ctx context.Context, name string) ([]byte, error)
+	Delete(ctx context.Context, name string) error
+	// Location returns a human-readable location of the file for log messages
+	Location(name string) string
+}
+
+// LocalTarget stores the dumps in a local directory.
+type LocalTarget struct {
+	Dir string
+}
+
+func (t LocalTarget) Store(_ context.Context, localFile, name string) error {
+	destination := filepath.Join(t.Dir, filepath.FromSlash(name))
+
+	source, err := filepath.Abs(localFile)
+	if err != nil {
+		return err
+	}
+
+	if destination, err = filepath.Abs(destination); err != nil {
+		return err
+	}
+
+	if source == destination {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.Rename(source, destination)
+}
+
+func (t LocalTarget) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(t.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names, nil
+}
+
+func (t LocalTarget) Read(_ context.Context, name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(t.Dir, filepath.FromSlash(name)))
+}
+
+// Delete removes the file and the directories which are empty afterwards.
+func (t LocalTarget) Delete(_ context.Context, name string) error {
+	file := filepath.Join(t.Dir, filepath.FromSlash(name))
+
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	for dir := path.Dir(name); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if err := os.Remove(filepath.Join(t.Dir, filepath.FromSlash(dir))); err != nil {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (t LocalTarget) Location(name string) string {
+	return filepath.Join(t.Dir, filepath.FromSlash(name))
+}
+
+// S3Target stores the dumps in a bucket of an S3-compatible storage.
+type S3Target struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+func (t S3Target) key(name string) string {
+	prefix := strings.Trim(t.Prefix, "/")
+
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "/" + name
+}
+
+// Store uploads the file and removes the local copy afterwards.
+func (t S3Target) Store(ctx context.Context, localFile, name string) error {
+	file, err := os.Open(localFile)
+	if err != nil {
+		return err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	err = t.Client.PutObject(ctx, t.Bucket, t.key(name), file, stat.Size())
+	_ = file.Close()
+
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", name, err)
+	}
+
+	return os.Remove(localFile)
+}
+
+func (t S3Target) List(ctx context.Context) ([]string, error) {
+	prefix := strings.Trim(t.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	objects, err := t.Client.ListObjects(ctx, t.Bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(objects))
+
+	for _, object := range objects {
+		names = append(names, strings.TrimPrefix(object.Key, prefix))
+	}
+
+	return names, nil
+}
+
+func (t S3Target) Read(ctx context.Context, name string) ([]byte, error) {
+	reader, err := t.Client.GetObject(ctx, t.Bucket, t.key(name))
+	if err != nil {
+		return nil, err
+	}
+
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+func (t S3Target) Delete(ctx context.Context, name string) error {
+	return t.Client.DeleteObject(ctx, t.Bucket, t.key(name))
+}
+
+func (t S3Target) Location(name string) string {
+	return fmt.Sprintf("s3://%s/%s", t.Bucket, t.key(name))
+}
@@ -17,8 +17,15 @@ import (
 type GitCommit struct {
 	Hash    string
 	Message string
+	// Body contains the commit message without the subject line
+	Body string
 }
 
+const (
+	commitFieldSeparator  = "\x1f"
+	commitRecordSeparator = "\x1e"
+)
+
 func runGit(ctx context.Context, repo string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = repo
@@ -111,24 +118,31 @@ func GetCommits(ctx context.Context, currentVersion, repo string) ([]GitCommit,
 	logging.FromContext(ctx).Debugf("Previous tag: %s", previousTag)
 	logging.FromContext(ctx).Debugf("Diffing %s..HEAD", previousTag)
 
-	commits, err := runGit(ctx, repo, "log", "--pretty=format:%h|%s", previousTag+"..HEAD", "--no-merges")
+	commits, err := runGit(ctx, repo, "log", "--pretty=format:%h%x1f%s%x1f%b%x1e", previousTag+"..HEAD", "--no-merges")
 	if err != nil {
 		return nil, fmt.Errorf("cannot get commits: %w", err)
 	}
 
-	if commits == "" {
-		return []GitCommit{}, nil
-	}
+	gitCommits := make([]GitCommit, 0)
 
-	commitsArray := strings.Split(commits, "\n")
-	gitCommits := make([]GitCommit, len(commitsArray))
+	for _, record := range strings.Split(commits, commitRecordSeparator) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, commitFieldSeparator, 3)
+		commit := GitCommit{Hash: fields[0]}
 
-	for commit := range commitsArray {
-		splitCommit := strings.Split(commitsArray[commit], "|")
-		gitCommits[commit] = GitCommit{
-			Hash:    splitCommit[0],
-			Message: strings.Join(splitCommit[1:], "|"),
+		if len(fields) > 1 {
+			commit.Message = fields[1]
 		}
+
+		if len(fields) > 2 {
+			commit.Body = strings.TrimSpace(fields[2])
+		}
+
+		gitCommits = append(gitCommits, commit)
 	}
 
 	return gitCommits, nil
@@ -195,3 +209,31 @@ func unshallowRepository(ctx context.Context, repo string) error {
 
 	return err
 }
+
+// HasUncommittedChanges reports whether the working tree of the repository contains changes.
+func HasUncommittedChanges(ctx context.Context, repo string) (bool, error) {
+	status, err := runGit(ctx, repo, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(status) != "", nil
+}
+
+// CommitFiles stages the given files and commits them with the message.
+func CommitFiles(ctx context.Context, repo, message string, files ...string) error {
+	if _, err := runGit(ctx, repo, append([]string{"add", "--"}, files...)...); err != nil {
+		return err
+	}
+
+	_, err := runGit(ctx, repo, "commit", "-m", message)
+
+	return err
+}
+
+// CreateTag creates an annotated tag on the current HEAD.
+func CreateTag(ctx context.Context, repo, tag, message string) error {
+	_, err := runGit(ctx, repo, "tag", "-a", tag, "-m", message)
+
+	return err
+}
@@ -91,6 +91,35 @@ func TestGetPublicVCSURL(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestCommitFilesAndCreateTag(t *testing.T) {
+	tmpDir := t.TempDir()
+	prepareRepository(t, tmpDir)
+	_ = os.WriteFile(filepath.Join(tmpDir, "a"), []byte(""), os.ModePerm)
+	runCommand(t, tmpDir, "add", "a")
+	runCommand(t, tmpDir, "commit", "-m", "initial commit", "--no-verify", "--no-gpg-sign")
+
+	dirty, err := HasUncommittedChanges(t.Context(), tmpDir)
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+
+	_ = os.WriteFile(filepath.Join(tmpDir, "a"), []byte("changed"), os.ModePerm)
+
+	dirty, err = HasUncommittedChanges(t.Context(), tmpDir)
+	assert.NoError(t, err)
+	assert.True(t, dirty)
+
+	assert.NoError(t, CommitFiles(t.Context(), tmpDir, "Release 1.0.0", "a"))
+	assert.NoError(t, CreateTag(t.Context(), tmpDir, "1.0.0", "Release 1.0.0"))
+
+	dirty, err = HasUncommittedChanges(t.Context(), tmpDir)
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+
+	currentTag, err := getTagForVersion(t.Context(), "1.0.0", tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.0.0", currentTag)
+}
+
 func runCommand(t *testing.T, tmpDir string, args ...string) {
 	t.Helper()
 
@@ -0,0 +1,278 @@
+// Package release manages a directory layout for atomic deployments.
+//
+// The base directory contains one folder per release in releases/, the paths shared between the releases in shared/
+// and the current symlink pointing to the active release.
+package release
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	cp "github.com/otiai10/copy"
+)
+
+const (
+	releasesDir = "releases"
+	sharedDir   = "shared"
+	currentLink = "current"
+
+	// NameFormat is the timestamp format of the release names, they sort in chronological order
+	NameFormat = "20060102150405"
+)
+
+// DefaultSharedPaths are shared between all releases, when nothing else is configured.
+var DefaultSharedPaths = []string{
+	"files",
+	"public/media",
+	"public/thumbnail",
+	"public/sitemap",
+	".env.local",
+	"var/log",
+}
+
+// ErrNoPreviousRelease is returned when there is no release to roll back to.
+var ErrNoPreviousRelease = errors.New("there is no previous release to roll back to")
+
+// Layout is the release directory structure below Base.
+type Layout struct {
+	Base string
+}
+
+// ReleasePath returns the directory of the release.
+func (l Layout) ReleasePath(name string) string {
+	return filepath.Join(l.Base, releasesDir, name)
+}
+
+// SharedPath returns the directory containing the shared paths.
+func (l Layout) SharedPath() string {
+	return filepath.Join(l.Base, sharedDir)
+}
+
+// CurrentPath returns the symlink to the active release.
+func (l Layout) CurrentPath() string {
+	return filepath.Join(l.Base, currentLink)
+}
+
+// NewName returns the name of a release created at the given time.
+func NewName(now time.Time) string {
+	return now.UTC().Format(NameFormat)
+}
+
+// Create copies the source project into a new release and links the shared paths into it.
+// Shared paths which are not shared yet are seeded with the content of the source project.
+func (l Layout) Create(source, name string, shared []string) (string, error) {
+	target := l.ReleasePath(name)
+
+	if _, err := os.Stat(target); err == nil {
+		return "", fmt.Errorf("release %s already exists", name)
+	}
+
+	// The source can be the current symlink of the layout
+	source, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return "", err
+	}
+
+	if source, err = filepath.Abs(source); err != nil {
+		return "", err
+	}
+
+	base, err := filepath.Abs(l.Base)
+	if err != nil {
+		return "", err
+	}
+
+	skipped := map[string]bool{".git": true}
+	for _, p := range shared {
+		skipped[filepath.Clean(p)] = true
+	}
+
+	err = cp.Copy(source, target, cp.Options{
+		OnSymlink: func(string) cp.SymlinkAction {
+			return cp.Shallow
+		},
+		Skip: func(_ os.FileInfo, src, _ string) (bool, error) {
+			// The layout can be inside of the project, never copy it into itself
+			if src == base {
+				return true, nil
+			}
+
+			rel, err := filepath.Rel(source, src)
+			if err != nil {
+				return false, err
+			}
+
+			return skipped[rel], nil
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("copy project into release: %w", err)
+	}
+
+	for _, p := range shared {
+		if err := l.linkShared(source, target, filepath.Clean(p)); err != nil {
+			return "", fmt.Errorf("link shared path %s: %w", p, err)
+		}
+	}
+
+	return target, nil
+}
+
+func (l Layout) linkShared(source, target, p string) error {
+	sharedPath := filepath.Join(l.SharedPath(), p)
+
+	if _, err := os.Lstat(sharedPath); errors.Is(err, os.ErrNotExist) {
+		if err := seedShared(filepath.Join(source, p), sharedPath); err != nil {
+			return err
+		}
+	}
+
+	link := filepath.Join(target, p)
+
+	if err := os.MkdirAll(filepath.Dir(link), os.ModePerm); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(link); err != nil {
+		return err
+	}
+
+	// Relative links keep working when the base directory is moved or mounted elsewhere
+	relative, err := filepath.Rel(filepath.Dir(link), sharedPath)
+	if err != nil {
+		return err
+	}
+
+	return os.Symlink(relative, link)
+}
+
+// seedShared creates the shared path from the source project, or empty when the project does not contain it.
+// Paths with a file extension like .env.local are created as files, all others as directories.
+func seedShared(source, sharedPath string) error {
+	if err := os.MkdirAll(filepath.Dir(sharedPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(source); err == nil {
+		return cp.Copy(source, sharedPath)
+	}
+
+	if filepath.Ext(sharedPath) != "" {
+		return os.WriteFile(sharedPath, nil, 0o644)
+	}
+
+	return os.MkdirAll(sharedPath, os.ModePerm)
+}
+
+// Activate atomically points the current symlink to the release.
+func (l Layout) Activate(name string) error {
+	if _, err := os.Stat(l.ReleasePath(name)); err != nil {
+		return fmt.Errorf("release %s not found: %w", name, err)
+	}
+
+	temporary := l.CurrentPath() + ".tmp"
+
+	if err := os.RemoveAll(temporary); err != nil {
+		return err
+	}
+
+	if err := os.Symlink(filepath.Join(releasesDir, name), temporary); err != nil {
+		return err
+	}
+
+	// Renaming replaces the existing symlink in a single step, so there is no moment without a current release
+	return os.Rename(temporary, l.CurrentPath())
+}
+
+// Current returns the name of the active release, or an empty string when no release is active.
+func (l Layout) Current() (string, error) {
+	target, err := os.Readlink(l.CurrentPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Base(target), nil
+}
+
+// List returns the names of all releases, the oldest first.
+func (l Layout) List() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(l.Base, releasesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// Prune removes the oldest releases, so keep releases are left besides the active one.
+func (l Layout) Prune(keep int) ([]string, error) {
+	names, err := l.List()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := l.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	names = slices.DeleteFunc(names, func(name string) bool {
+		return name == current
+	})
+
+	if len(names) <= keep {
+		return nil, nil
+	}
+
+	removed := names[:len(names)-keep]
+
+	for _, name := range removed {
+		if err := os.RemoveAll(l.ReleasePath(name)); err != nil {
+			return nil, fmt.Errorf("remove release %s: %w", name, err)
+		}
+	}
+
+	return removed, nil
+}
+
+// Previous returns the newest release older than the active one.
+func (l Layout) Previous() (string, error) {
+	names, err := l.List()
+	if err != nil {
+		return "", err
+	}
+
+	current, err := l.Current()
+	if err != nil {
+		return "", err
+	}
+
+	index := slices.Index(names, current)
+	if index <= 0 {
+		return "", ErrNoPreviousRelease
+	}
+
+	return names[index-1], nil
+}
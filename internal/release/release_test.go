@@ -0,0 +1,115 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createProject(t *testing.T) string {
+	t.Helper()
+
+	source := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(source, "public", "media"), os.ModePerm))
+	require.NoError(t, os.MkdirAll(filepath.Join(source, ".git"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "composer.json"), []byte("{}"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, "public", "media", "image.png"), []byte("png"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(source, ".env.local"), []byte("APP_ENV=prod"), 0o644))
+
+	return source
+}
+
+func TestCreateLinksSharedPaths(t *testing.T) {
+	source := createProject(t)
+	layout := Layout{Base: t.TempDir()}
+	shared := []string{"public/media", ".env.local", "var/log", "files"}
+
+	target, err := layout.Create(source, "20240101000000", shared)
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(target, "composer.json"))
+	assert.NoDirExists(t, filepath.Join(target, ".git"))
+
+	// Shared paths are seeded from the source project or created empty
+	assert.FileExists(t, filepath.Join(layout.SharedPath(), "public", "media", "image.png"))
+	assert.FileExists(t, filepath.Join(layout.SharedPath(), ".env.local"))
+	assert.DirExists(t, filepath.Join(layout.SharedPath(), "var", "log"))
+	assert.DirExists(t, filepath.Join(layout.SharedPath(), "files"))
+
+	for _, p := range shared {
+		info, err := os.Lstat(filepath.Join(target, p))
+		require.NoError(t, err)
+		assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink, p)
+	}
+
+	// Later releases reuse the shared content instead of the one of the source
+	require.NoError(t, os.WriteFile(filepath.Join(layout.SharedPath(), ".env.local"), []byte("APP_ENV=shared"), 0o644))
+
+	target, err = layout.Create(source, "20240102000000", shared)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(target, ".env.local"))
+	require.NoError(t, err)
+	assert.Equal(t, "APP_ENV=shared", string(content))
+
+	_, err = layout.Create(source, "20240102000000", shared)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestCreateSkipsLayoutInsideOfSource(t *testing.T) {
+	source := createProject(t)
+	layout := Layout{Base: filepath.Join(source, "deploy")}
+
+	target, err := layout.Create(source, "20240101000000", nil)
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(target, "deploy"))
+}
+
+func TestActivatePruneAndPrevious(t *testing.T) {
+	source := createProject(t)
+	layout := Layout{Base: t.TempDir()}
+
+	current, err := layout.Current()
+	require.NoError(t, err)
+	assert.Empty(t, current)
+
+	_, err = layout.Previous()
+	assert.ErrorIs(t, err, ErrNoPreviousRelease)
+
+	names := []string{"20240101000000", "20240102000000", "20240103000000", "20240104000000"}
+
+	for _, name := range names {
+		_, err := layout.Create(source, name, nil)
+		require.NoError(t, err)
+		require.NoError(t, layout.Activate(name))
+	}
+
+	assert.FileExists(t, filepath.Join(layout.CurrentPath(), "composer.json"))
+
+	previous, err := layout.Previous()
+	require.NoError(t, err)
+	assert.Equal(t, "20240103000000", previous)
+
+	// A rolled back release is never pruned
+	require.NoError(t, layout.Activate("20240102000000"))
+
+	removed, err := layout.Prune(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20240101000000", "20240103000000"}, removed)
+
+	list, err := layout.List()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"20240102000000", "20240104000000"}, list)
+
+	assert.Error(t, layout.Activate("20240101000000"))
+}
+
+func TestNewName(t *testing.T) {
+	assert.Equal(t, "20240102030405", NewName(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)))
+}
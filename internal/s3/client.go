@@ -0,0 +1,316 @@
+// Package s3 implements a small client for S3-compatible object storages like AWS S3, MinIO or Cloudflare R2.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRegion = "us-east-1"
+
+	// DefaultPartSize is the size of the parts of a multipart upload, objects up to this size are uploaded with a single request.
+	DefaultPartSize = 64 * 1024 * 1024
+)
+
+// Client talks to an S3-compatible storage.
+// Without an endpoint AWS S3 with virtual-hosted buckets is used, a custom endpoint uses path-style buckets as supported by all S3-compatible storages.
+type Client struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PartSize        int64
+	HTTPClient      *http.Client
+}
+
+// Object is an entry of a bucket listing.
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// Error is the error response of the storage.
+type Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3 request failed with status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("s3 request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// NewClientFromEnv creates a client with the credentials, region and endpoint of the AWS_* environment variables.
+func NewClientFromEnv() *Client {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+
+	return &Client{
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL"),
+		Region:          region,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+}
+
+// PutObject uploads the object, bigger objects are uploaded in multiple parts.
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body io.ReaderAt, size int64) error {
+	partSize := c.PartSize
+	if partSize <= 0 {
+		partSize = DefaultPartSize
+	}
+
+	if size > partSize {
+		return c.putMultipart(ctx, bucket, key, body, size, partSize)
+	}
+
+	resp, err := c.do(ctx, http.MethodPut, bucket, key, nil, io.NewSectionReader(body, 0, size), size, unsignedPayload)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// GetObject returns the content of the object, the caller has to close it.
+func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// DeleteObject removes the object, removing a missing object is not an error.
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, bucket, key, nil, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// ListObjects returns all objects of the bucket starting with the prefix.
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]Object, error) {
+	objects := make([]Object, 0)
+	continuationToken := ""
+
+	for {
+		query := url.Values{"list-type": {"2"}}
+
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		resp, err := c.do(ctx, http.MethodGet, bucket, "", query, nil, 0, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Contents              []Object `xml:"Contents"`
+			IsTruncated           bool     `xml:"IsTruncated"`
+			NextContinuationToken string   `xml:"NextContinuationToken"`
+		}
+
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		_ = resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("decode bucket listing: %w", err)
+		}
+
+		objects = append(objects, result.Contents...)
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *Client) putMultipart(ctx context.Context, bucket, key string, body io.ReaderAt, size, partSize int64) error {
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil, 0, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	_ = resp.Body.Close()
+
+	if err != nil {
+		return fmt.Errorf("decode multipart upload: %w", err)
+	}
+
+	if err := c.uploadParts(ctx, bucket, key, initiated.UploadID, body, size, partSize); err != nil {
+		// Remove the already uploaded parts, they would be billed otherwise
+		if resp, abortErr := c.do(context.WithoutCancel(ctx), http.MethodDelete, bucket, key, url.Values{"uploadId": {initiated.UploadID}}, nil, 0, emptyPayloadHash); abortErr == nil {
+			_ = resp.Body.Close()
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) uploadParts(ctx context.Context, bucket, key, uploadID string, body io.ReaderAt, size, partSize int64) error {
+	parts := make([]completedPart, 0, size/partSize+1)
+
+	for offset, number := int64(0), 1; offset < size; offset, number = offset+partSize, number+1 {
+		length := min(partSize, size-offset)
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+
+		resp, err := c.do(ctx, http.MethodPut, bucket, key, query, io.NewSectionReader(body, offset, length), length, unsignedPayload)
+		if err != nil {
+			return fmt.Errorf("upload part %d: %w", number, err)
+		}
+
+		_ = resp.Body.Close()
+
+		parts = append(parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+	}
+
+	completion, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(completion), int64(len(completion)), hashHex(completion))
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	// The completion can fail after the status code has been sent already
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Contains(content, []byte("<Error>")) {
+		return parseError(resp.StatusCode, content)
+	}
+
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, bucket, key string, query url.Values, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	target, err := c.objectURL(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if query != nil {
+		target.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.ContentLength = size
+	req.Header.Set("User-Agent", "Shopware CLI")
+
+	region := c.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	signRequest(req, c.AccessKeyID, c.SecretAccessKey, region, payloadHash, time.Now())
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+
+		content, _ := io.ReadAll(resp.Body)
+
+		return nil, parseError(resp.StatusCode, content)
+	}
+
+	return resp, nil
+}
+
+func (c *Client) objectURL(bucket, key string) (*url.URL, error) {
+	var target *url.URL
+
+	if c.Endpoint == "" {
+		region := c.Region
+		if region == "" {
+			region = defaultRegion
+		}
+
+		target = &url.URL{Scheme: "https", Host: fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, region), Path: "/" + key}
+	} else {
+		endpoint, err := url.Parse(c.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+		}
+
+		if endpoint.Scheme == "" || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid s3 endpoint %s, expected an url like https://s3.example.com", c.Endpoint)
+		}
+
+		target = &url.URL{Scheme: endpoint.Scheme, Host: endpoint.Host, Path: strings.TrimSuffix(endpoint.Path, "/") + "/" + bucket + "/" + key}
+	}
+
+	// Send the path exactly as it has been signed
+	target.RawPath = uriEncode(target.Path, false)
+
+	return target, nil
+}
+
+func parseError(statusCode int, content []byte) error {
+	s3Err := &Error{StatusCode: statusCode}
+
+	_ = xml.Unmarshal(content, s3Err)
+
+	return s3Err
+}
@@ -5,10 +5,19 @@ import (
 	"regexp"
 )
 
-var envVarRegex = regexp.MustCompile(`\${\w+}`)
+var (
+	envVarRegex       = regexp.MustCompile(`\${\w+}`)
+	envReferenceRegex = regexp.MustCompile(`^(\${\w+})+$`)
+)
 
 func ExpandEnv(s string) string {
 	return envVarRegex.ReplaceAllStringFunc(s, func(match string) string {
 		return os.Getenv(match[2 : len(match)-1])
 	})
 }
+
+// IsEnvReference reports whether the value consists only of ${NAME} expansions, so it contains no plaintext.
+// $NAME is not expanded by ExpandEnv and therefore no reference.
+func IsEnvReference(s string) bool {
+	return envReferenceRegex.MatchString(s)
+}
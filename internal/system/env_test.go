@@ -29,3 +29,12 @@ func TestExpandEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestIsEnvReference(t *testing.T) {
+	assert.True(t, IsEnvReference("${SHOPWARE_PASSWORD}"))
+	assert.True(t, IsEnvReference("${USER}${PASSWORD}"))
+	assert.False(t, IsEnvReference("$SHOPWARE_PASSWORD"))
+	assert.False(t, IsEnvReference("pa$$word"))
+	assert.False(t, IsEnvReference("secret-${SUFFIX}"))
+	assert.False(t, IsEnvReference("${FOO"))
+}
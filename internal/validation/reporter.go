@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -94,8 +95,13 @@ func doSummaryReport(result Check) error {
 			case SeverityWarning:
 				warningCount++
 			}
+			location := strconv.Itoa(r.Line)
+			if r.Column > 0 {
+				location = fmt.Sprintf("%d:%d", r.Line, r.Column)
+			}
+
 			//nolint:forbidigo
-			fmt.Printf("  %d  %-7s  %s  %s\n", r.Line, r.Severity, r.Message, r.Identifier)
+			fmt.Printf("  %s  %-7s  %s  %s\n", location, r.Severity, r.Message, r.Identifier)
 		}
 	}
 
@@ -148,6 +154,10 @@ func doGitHubReport(result Check) error {
 			line = fmt.Sprintf(",line=%d", r.Line)
 		}
 
+		if r.Column > 0 {
+			line += fmt.Sprintf(",col=%d", r.Column)
+		}
+
 		message := strings.ReplaceAll(r.Message, "\n", "%0A")
 		message = strings.ReplaceAll(message, "\r", "%0D")
 
@@ -213,6 +223,10 @@ func doMarkdownReport(result Check) error {
 				location = fmt.Sprintf(":%d", r.Line)
 			}
 
+			if r.Column > 0 {
+				location += fmt.Sprintf(":%d", r.Column)
+			}
+
 			fmt.Printf("- **%s** %s%s: %s (`%s`)\n", severity, r.Path, location, r.Message, r.Identifier)
 		}
 		fmt.Println()
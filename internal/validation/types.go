@@ -13,7 +13,9 @@ type CheckResult struct {
 	// The path to the file that was checked
 	Path string `json:"path"`
 	// The line number of the issue
-	Line    int    `json:"line"`
+	Line int `json:"line"`
+	// The column of the issue, when known
+	Column  int    `json:"column,omitempty"`
 	Message string `json:"message"`
 	// The severity of the issue
 	Severity string `json:"severity"`
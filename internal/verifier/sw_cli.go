@@ -20,6 +20,15 @@ func (s SWCLI) Check(ctx context.Context, check *Check, config ToolConfig) error
 
 	extension.RunValidation(ctx, config.Extension, check)
 
+	if config.ZipFile != "" {
+		report, err := extension.AnalyzeZip(config.ZipFile)
+		if err != nil {
+			return err
+		}
+
+		report.ValidateSizeBudget(config.Extension.GetExtensionConfig().Build.Zip.SizeBudget, check)
+	}
+
 	// Apply ignores from extension config
 	ignores := make([]validation.ToolConfigIgnore, 0)
 	for _, ignore := range config.Extension.GetExtensionConfig().Validation.Ignore {
@@ -26,6 +26,8 @@ type ToolConfig struct {
 	ToolDirectory string
 
 	InputWasDirectory bool
+	// Path to the zip file, when the input was a zip
+	ZipFile string
 
 	// The minimum version of Shopware that is supported
 	MinShopwareVersion string
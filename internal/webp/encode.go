@@ -0,0 +1,358 @@
+// Package webp encodes images as lossless WebP (VP8L) in pure Go.
+//
+// The encoder applies the subtract green transform and codes every pixel as literal with
+// one set of prefix codes. It does not use backward references or a color cache, so the files
+// are larger than the ones of libwebp, but it needs no cgo.
+package webp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+const (
+	maxDimension = 1 << 14
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+
+	// Alphabet sizes of green (with the length prefixes), red, blue, alpha and distance
+	greenAlphabetSize    = 256 + 24
+	colorAlphabetSize    = 256
+	distanceAlphabetSize = 40
+
+	transformSubtractGreen = 2
+)
+
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Encode writes the image as lossless WebP.
+func Encode(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width < 1 || height < 1 || width > maxDimension || height > maxDimension {
+		return fmt.Errorf("webp: cannot encode an image of %dx%d pixels", width, height)
+	}
+
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	}
+
+	data := encodeVP8L(nrgba)
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+len(data)+len(data)%2))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	if len(data)%2 == 1 {
+		data = append(data, 0)
+	}
+
+	_, err := w.Write(data)
+
+	return err
+}
+
+func encodeVP8L(img *image.NRGBA) []byte {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	pixels := make([][4]byte, 0, width*height)
+	opaque := true
+
+	for y := 0; y < height; y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+width*4]
+
+		for x := 0; x < width; x++ {
+			r, g, b, a := row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]
+			opaque = opaque && a == 0xff
+
+			// Order of the prefix codes: green, red, blue and alpha. Red and blue are stored with green subtracted.
+			pixels = append(pixels, [4]byte{g, r - g, b - g, a})
+		}
+	}
+
+	histograms := [4][]int{
+		make([]int, greenAlphabetSize),
+		make([]int, colorAlphabetSize),
+		make([]int, colorAlphabetSize),
+		make([]int, colorAlphabetSize),
+	}
+
+	for _, pixel := range pixels {
+		for i, value := range pixel {
+			histograms[i][value]++
+		}
+	}
+
+	bw := &bitWriter{}
+
+	bw.write(0x2f, 8)
+	bw.write(uint64(width-1), 14)
+	bw.write(uint64(height-1), 14)
+
+	if opaque {
+		bw.write(0, 1)
+	} else {
+		bw.write(1, 1)
+	}
+
+	// Version
+	bw.write(0, 3)
+
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+	bw.write(0, 1)
+
+	// No color cache and no meta prefix codes
+	bw.write(0, 1)
+	bw.write(0, 1)
+
+	codes := [4]prefixCode{}
+	for i, histogram := range histograms {
+		codes[i] = writePrefixCode(bw, histogram)
+	}
+
+	// The distance code is not used, as there are no backward references
+	writePrefixCode(bw, make([]int, distanceAlphabetSize))
+
+	for _, pixel := range pixels {
+		for i, value := range pixel {
+			codes[i].write(bw, int(value))
+		}
+	}
+
+	return bw.flush()
+}
+
+type prefixCode struct {
+	lengths []int
+	codes   []uint64
+}
+
+func (c prefixCode) write(bw *bitWriter, symbol int) {
+	if c.lengths[symbol] > 0 {
+		bw.write(c.codes[symbol], c.lengths[symbol])
+	}
+}
+
+// writePrefixCode writes the prefix code of the histogram and returns it for the symbols.
+func writePrefixCode(bw *bitWriter, histogram []int) prefixCode {
+	symbols := make([]int, 0, 2)
+
+	for symbol, count := range histogram {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	if len(symbols) == 0 {
+		symbols = append(symbols, 0)
+	}
+
+	// Up to two symbols below 256 use the simple code, a single symbol needs no bits at all
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		lengths := make([]int, len(histogram))
+
+		bw.write(1, 1)
+		bw.write(uint64(len(symbols)-1), 1)
+		bw.write(1, 1)
+
+		for _, symbol := range symbols {
+			bw.write(uint64(symbol), 8)
+
+			if len(symbols) == 2 {
+				lengths[symbol] = 1
+			}
+		}
+
+		return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+	}
+
+	lengths := codeLengths(histogram, maxCodeLength)
+
+	codeLengthHistogram := make([]int, len(codeLengthCodeOrder))
+	for _, length := range lengths {
+		codeLengthHistogram[length]++
+	}
+
+	codeLengthLengths := codeLengths(codeLengthHistogram, maxCodeLengthCodeLength)
+	codeLengthCodes := canonicalCodes(codeLengthLengths)
+
+	// A code with a single symbol is decoded without reading any bits
+	usedCodeLengths := 0
+	for _, length := range codeLengthLengths {
+		if length > 0 {
+			usedCodeLengths++
+		}
+	}
+
+	numCodes := len(codeLengthCodeOrder)
+	for numCodes > 4 && codeLengthLengths[codeLengthCodeOrder[numCodes-1]] == 0 {
+		numCodes--
+	}
+
+	bw.write(0, 1)
+	bw.write(uint64(numCodes-4), 4)
+
+	for _, symbol := range codeLengthCodeOrder[:numCodes] {
+		bw.write(uint64(codeLengthLengths[symbol]), 3)
+	}
+
+	// All code lengths are written, without a max symbol
+	bw.write(0, 1)
+
+	if usedCodeLengths > 1 {
+		for _, length := range lengths {
+			bw.write(codeLengthCodes[length], codeLengthLengths[length])
+		}
+	}
+
+	return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+// codeLengths returns the Huffman code lengths of the histogram, limited to maxLength. When the tree is too
+// deep, the small counts are raised until it fits.
+func codeLengths(histogram []int, maxLength int) []int {
+	for minCount := 1; ; minCount *= 2 {
+		lengths := huffmanLengths(histogram, minCount)
+
+		longest := 0
+		for _, length := range lengths {
+			longest = max(longest, length)
+		}
+
+		if longest <= maxLength {
+			return lengths
+		}
+	}
+}
+
+type huffmanNode struct {
+	count       int
+	symbol      int
+	left, right *huffmanNode
+}
+
+func huffmanLengths(histogram []int, minCount int) []int {
+	lengths := make([]int, len(histogram))
+	nodes := make([]*huffmanNode, 0, len(histogram))
+
+	for symbol, count := range histogram {
+		if count > 0 {
+			nodes = append(nodes, &huffmanNode{count: max(count, minCount), symbol: symbol})
+		}
+	}
+
+	if len(nodes) == 1 {
+		lengths[nodes[0].symbol] = 1
+
+		return lengths
+	}
+
+	for len(nodes) > 1 {
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return nodes[i].count < nodes[j].count
+		})
+
+		merged := &huffmanNode{count: nodes[0].count + nodes[1].count, symbol: -1, left: nodes[0], right: nodes[1]}
+		nodes = append([]*huffmanNode{merged}, nodes[2:]...)
+	}
+
+	var walk func(node *huffmanNode, depth int)
+	walk = func(node *huffmanNode, depth int) {
+		if node.symbol >= 0 {
+			lengths[node.symbol] = depth
+
+			return
+		}
+
+		walk(node.left, depth+1)
+		walk(node.right, depth+1)
+	}
+
+	walk(nodes[0], 0)
+
+	return lengths
+}
+
+// canonicalCodes assigns the codes like Deflate, reversed as the bits are written starting with the least significant.
+func canonicalCodes(lengths []int) []uint64 {
+	codes := make([]uint64, len(lengths))
+	lengthCount := make([]int, maxCodeLength+2)
+
+	for _, length := range lengths {
+		if length > 0 {
+			lengthCount[length]++
+		}
+	}
+
+	nextCode := make([]uint64, maxCodeLength+2)
+	code := uint64(0)
+
+	for length := 1; length <= maxCodeLength+1; length++ {
+		code = (code + uint64(lengthCount[length-1])) << 1
+		nextCode[length] = code
+	}
+
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+
+		codes[symbol] = reverseBits(nextCode[length], length)
+		nextCode[length]++
+	}
+
+	return codes
+}
+
+func reverseBits(code uint64, length int) uint64 {
+	reversed := uint64(0)
+
+	for i := 0; i < length; i++ {
+		reversed = reversed<<1 | code&1
+		code >>= 1
+	}
+
+	return reversed
+}
+
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nBits int
+}
+
+func (w *bitWriter) write(value uint64, n int) {
+	w.bits |= value << w.nBits
+	w.nBits += n
+
+	for w.nBits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nBits -= 8
+	}
+}
+
+func (w *bitWriter) flush() []byte {
+	if w.nBits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits = 0
+		w.nBits = 0
+	}
+
+	return w.buf
+}
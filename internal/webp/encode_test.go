@@ -0,0 +1,101 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func roundTrip(t *testing.T, img image.Image) image.Image {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img))
+
+	decoded, err := webp.Decode(&buf)
+	require.NoError(t, err)
+
+	return decoded
+}
+
+func assertSamePixels(t *testing.T, expected, actual image.Image) {
+	t.Helper()
+
+	require.Equal(t, expected.Bounds().Size(), actual.Bounds().Size())
+
+	for y := 0; y < expected.Bounds().Dy(); y++ {
+		for x := 0; x < expected.Bounds().Dx(); x++ {
+			want := color.NRGBAModel.Convert(expected.At(expected.Bounds().Min.X+x, expected.Bounds().Min.Y+y))
+			got := color.NRGBAModel.Convert(actual.At(actual.Bounds().Min.X+x, actual.Bounds().Min.Y+y))
+
+			if !assert.Equal(t, want, got, "pixel %d,%d", x, y) {
+				return
+			}
+		}
+	}
+}
+
+func TestEncodeGradient(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 67, 41))
+
+	for y := 0; y < 41; y++ {
+		for x := 0; x < 67; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 3), G: uint8(y * 5), B: uint8(x * y), A: uint8(255 - x)})
+		}
+	}
+
+	assertSamePixels(t, img, roundTrip(t, img))
+}
+
+func TestEncodeAllColors(t *testing.T) {
+	// Every value is used equally often, so all code lengths are the same
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x + y), A: uint8(y - x)})
+		}
+	}
+
+	assertSamePixels(t, img, roundTrip(t, img))
+}
+
+func TestEncodeSingleColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(10, 10, 30, 20))
+
+	for y := 10; y < 20; y++ {
+		for x := 10; x < 30; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	assertSamePixels(t, img, roundTrip(t, img))
+}
+
+func TestEncodeSkewedHistogram(t *testing.T) {
+	// A few colors are very frequent, which makes the Huffman tree deeper than allowed without limiting it
+	img := image.NewGray(image.Rect(0, 0, 512, 512))
+
+	count := 0
+	for value := 0; value < 256; value++ {
+		for i := 0; i < 1<<(value/16); i++ {
+			if count >= 512*512 {
+				break
+			}
+
+			img.Pix[count] = uint8(value)
+			count++
+		}
+	}
+
+	assertSamePixels(t, img, roundTrip(t, img))
+}
+
+func TestEncodeInvalidSize(t *testing.T) {
+	assert.Error(t, Encode(&bytes.Buffer{}, image.NewNRGBA(image.Rect(0, 0, 0, 10))))
+}
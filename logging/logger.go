@@ -31,7 +31,7 @@ func NewLogger(verbose bool) *zap.SugaredLogger {
 		loggerCfg.EncoderConfig.TimeKey = ""
 	}
 
-	logger, err := loggerCfg.Build()
+	logger, err := loggerCfg.Build(zap.WrapCore(newRedactCore))
 	if err != nil {
 		logger = zap.NewNop()
 	}
@@ -50,7 +50,7 @@ func FromContext(ctx context.Context) *zap.SugaredLogger {
 
 	if fallbackLogger == nil {
 		loggerCfg := zap.NewProductionConfig()
-		logger, _ := loggerCfg.Build()
+		logger, _ := loggerCfg.Build(zap.WrapCore(newRedactCore))
 
 		fallbackLogger = logger.Sugar()
 	}
@@ -94,6 +94,12 @@ func generateExtensionSchema() error {
 	}
 	schema.Definitions["ChangelogConfig"] = changelogSchema.Definitions["Config"]
 
+	for name, definition := range changelogSchema.Definitions {
+		if name != "Config" {
+			schema.Definitions[name] = definition
+		}
+	}
+
 	bytes, err := json.MarshalIndent(schema, "", "  ")
 	if err != nil {
 		return err
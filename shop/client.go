@@ -10,7 +10,7 @@ import (
 	adminSdk "github.com/friendsofshopware/go-shopware-admin-api-sdk"
 )
 
-func newShopCredentials(config *Config) (adminSdk.OAuthCredentials, error) {
+func newShopCredentials(ctx context.Context, config *Config) (adminSdk.OAuthCredentials, error) {
 	clientId, clientSecret := os.Getenv("SHOPWARE_CLI_API_CLIENT_ID"), os.Getenv("SHOPWARE_CLI_API_CLIENT_SECRET")
 
 	if clientId != "" && clientSecret != "" {
@@ -28,10 +28,20 @@ func newShopCredentials(config *Config) (adminSdk.OAuthCredentials, error) {
 	}
 
 	if config.AdminApi.Username != "" {
-		return adminSdk.NewPasswordCredentials(config.AdminApi.Username, config.AdminApi.Password, []string{"write"}), nil
+		password, err := ResolveSecret(ctx, config.AdminApi.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		return adminSdk.NewPasswordCredentials(config.AdminApi.Username, password, []string{"write"}), nil
 	}
 
-	return adminSdk.NewIntegrationCredentials(config.AdminApi.ClientId, config.AdminApi.ClientSecret, []string{"write"}), nil
+	secret, err := ResolveSecret(ctx, config.AdminApi.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return adminSdk.NewIntegrationCredentials(config.AdminApi.ClientId, secret, []string{"write"}), nil
 }
 
 func NewShopClient(ctx context.Context, config *Config) (*adminSdk.Client, error) {
@@ -53,16 +63,21 @@ func NewShopClient(ctx context.Context, config *Config) (*adminSdk.Client, error
 	}
 	client := &http.Client{Transport: tr}
 
-	shopUrl := os.Getenv("SHOPWARE_CLI_API_URL")
-
-	if shopUrl == "" {
-		shopUrl = config.URL
-	}
+	shopUrl := ShopURL(config)
 
-	creds, err := newShopCredentials(config)
+	creds, err := newShopCredentials(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("newShopCredentials: %v", err)
 	}
 
 	return adminSdk.NewApiClient(ctx, shopUrl, creds, client)
 }
+
+// ShopURL returns the URL of the Admin API, the SHOPWARE_CLI_API_URL environment variable overrides the url of the config.
+func ShopURL(config *Config) string {
+	if shopUrl := os.Getenv("SHOPWARE_CLI_API_URL"); shopUrl != "" {
+		return shopUrl
+	}
+
+	return config.URL
+}
@@ -48,7 +48,7 @@ func Test_newShopCredentials_envIntegration(t *testing.T) {
 	t.Setenv("SHOPWARE_CLI_API_CLIENT_SECRET", "secret")
 
 	cfg := &Config{}
-	creds, err := newShopCredentials(cfg)
+	creds, err := newShopCredentials(t.Context(), cfg)
 	assert.NoError(t, err)
 	assert.NotNil(t, creds)
 }
@@ -58,7 +58,7 @@ func Test_newShopCredentials_envPassword(t *testing.T) {
 	t.Setenv("SHOPWARE_CLI_API_PASSWORD", "pass")
 
 	cfg := &Config{}
-	creds, err := newShopCredentials(cfg)
+	creds, err := newShopCredentials(t.Context(), cfg)
 	assert.NoError(t, err)
 	assert.NotNil(t, creds)
 }
@@ -76,7 +76,7 @@ func Test_newShopCredentials_configPassword(t *testing.T) {
 			Password: "pass",
 		},
 	}
-	creds, err := newShopCredentials(cfg)
+	creds, err := newShopCredentials(t.Context(), cfg)
 	assert.NoError(t, err)
 	assert.NotNil(t, creds)
 }
@@ -94,7 +94,7 @@ func Test_newShopCredentials_configIntegration(t *testing.T) {
 			ClientSecret: "secret",
 		},
 	}
-	creds, err := newShopCredentials(cfg)
+	creds, err := newShopCredentials(t.Context(), cfg)
 	assert.NoError(t, err)
 	assert.NotNil(t, creds)
 }
@@ -107,7 +107,7 @@ func Test_newShopCredentials_noConfig(t *testing.T) {
 	t.Setenv("SHOPWARE_CLI_API_PASSWORD", "")
 
 	cfg := &Config{}
-	_, err := newShopCredentials(cfg)
+	_, err := newShopCredentials(t.Context(), cfg)
 	assert.Error(t, err)
 }
 
@@ -26,9 +26,13 @@ type Config struct {
 	ConfigDump       *ConfigDump       `yaml:"dump,omitempty"`
 	Sync             *ConfigSync       `yaml:"sync,omitempty"`
 	ConfigDeployment *ConfigDeployment `yaml:"deployment,omitempty"`
+	Extensions       *ConfigExtensions `yaml:"extensions,omitempty"`
 	Validation       *ConfigValidation `yaml:"validation,omitempty"`
 	ImageProxy       *ConfigImageProxy `yaml:"image_proxy,omitempty"`
-	foundConfig      bool
+	Worker           *ConfigWorker     `yaml:"worker,omitempty"`
+	// Overlays per environment, selected with --env or the SHOPWARE_ENV environment variable
+	Environments map[string]ConfigEnvironment `yaml:"environments,omitempty"`
+	foundConfig  bool
 }
 
 func (c *Config) IsAdminAPIConfigured() bool {
@@ -73,18 +77,18 @@ type ConfigBuildExtension struct {
 type ConfigAdminApi struct {
 	// Client ID of integration
 	ClientId string `yaml:"client_id,omitempty"`
-	// Client Secret of integration
+	// Client Secret of integration, supports references like secret://env/NAME, secret://file/path or secret://cmd/command
 	ClientSecret string `yaml:"client_secret,omitempty"`
 	// Username of admin user
 	Username string `yaml:"username,omitempty"`
-	// Password of admin user
+	// Password of admin user, supports references like secret://env/NAME, secret://file/path or secret://cmd/command
 	Password string `yaml:"password,omitempty"`
 	// Disable SSL certificate check
 	DisableSSLCheck bool `yaml:"disable_ssl_check,omitempty"`
 }
 
 type ConfigDump struct {
-	// Allows to rewrite single columns, perfect for GDPR compliance
+	// Allows to rewrite single columns, perfect for GDPR compliance. Values can be secret:// references
 	Rewrite map[string]core.Rewrite `yaml:"rewrite,omitempty"`
 	// Only export the schema of these tables
 	NoData []string `yaml:"nodata,omitempty"`
@@ -92,14 +96,108 @@ type ConfigDump struct {
 	Ignore []string `yaml:"ignore,omitempty"`
 	// Add an where condition to that table, schema is table name as key, and where statement as value
 	Where map[string]string `yaml:"where,omitempty"`
+	// Export only a referentially consistent subset of the database
+	Subset *ConfigDumpSubset `yaml:"subset,omitempty"`
+	// Configures the anonymization used with --anonymize
+	Anonymization *ConfigDumpAnonymization `yaml:"anonymization,omitempty"`
+	// Named dump profiles selectable with --profile
+	Profiles map[string]ConfigDumpProfile `yaml:"profiles,omitempty"`
+	// Where the dumps are stored, defaults to the local output file
+	Target *ConfigDumpTarget `yaml:"target,omitempty"`
+	// Removes older dumps of the same profile from the target after a successful dump
+	Rotation *ConfigDumpRotation `yaml:"rotation,omitempty"`
+}
+
+type ConfigDumpProfile struct {
+	// Name of the profile to inherit from
+	Extends string `yaml:"extends,omitempty"`
+	// Skips the data of temporary tables like cart, messenger_messages, message_queue_stats,...
+	Clean *bool `yaml:"clean,omitempty"`
+	// Anonymizes the data
+	Anonymize *bool `yaml:"anonymize,omitempty"`
+	// Anonymization preset to use, implies anonymize
+	AnonymizePreset string `yaml:"anonymize_preset,omitempty"`
+	// Exports only the schema without any data
+	SchemaOnly *bool `yaml:"schema_only,omitempty"`
+	// Compression of the dump
+	Compression string `yaml:"compression,omitempty" jsonschema:"enum=gzip,enum=zstd"`
+	// Format of the dump
+	Format string `yaml:"format,omitempty" jsonschema:"enum=sql,enum=directory"`
+	// Output file name template, supports {{.Profile}}, {{.Database}}, {{.Date}} and {{.Time}}, e.g. dumps/{{.Profile}}-{{.Date}}.sql
+	Output string `yaml:"output,omitempty"`
+	// Allows to rewrite single columns, merged with the rewrites of the inherited profile
+	Rewrite map[string]core.Rewrite `yaml:"rewrite,omitempty"`
+	// Only export the schema of these tables
+	NoData []string `yaml:"nodata,omitempty"`
+	// Ignore these tables from export
+	Ignore []string `yaml:"ignore,omitempty"`
+	// Add an where condition to that table, schema is table name as key, and where statement as value
+	Where map[string]string `yaml:"where,omitempty"`
+	// Export only a referentially consistent subset of the database
+	Subset *ConfigDumpSubset `yaml:"subset,omitempty"`
+	// Where the dumps of this profile are stored
+	Target *ConfigDumpTarget `yaml:"target,omitempty"`
+	// Removes older dumps of this profile from the target after a successful dump
+	Rotation *ConfigDumpRotation `yaml:"rotation,omitempty"`
+}
+
+type ConfigDumpTarget struct {
+	// Type of the target
+	Type string `yaml:"type" jsonschema:"enum=local,enum=s3"`
+	// Name of the S3 bucket
+	Bucket string `yaml:"bucket,omitempty"`
+	// Prefix of the object keys in the bucket, e.g. backups/shop
+	Prefix string `yaml:"prefix,omitempty"`
+	// Endpoint of an S3-compatible storage like MinIO, defaults to AWS S3. Falls back to the AWS_ENDPOINT_URL environment variable
+	Endpoint string `yaml:"endpoint,omitempty"`
+	// Region of the bucket. Falls back to the AWS_REGION environment variable
+	Region string `yaml:"region,omitempty"`
+	// Access key id. Falls back to the AWS_ACCESS_KEY_ID environment variable
+	AccessKeyID string `yaml:"access_key_id,omitempty"`
+	// Secret access key, can be a secret:// reference. Falls back to the AWS_SECRET_ACCESS_KEY environment variable
+	SecretAccessKey string `yaml:"secret_access_key,omitempty"`
+}
+
+type ConfigDumpRotation struct {
+	// Number of days for which the newest dump of the day is kept
+	Daily int `yaml:"daily,omitempty"`
+	// Number of weeks for which the newest dump of the week is kept
+	Weekly int `yaml:"weekly,omitempty"`
+}
+
+type ConfigDumpAnonymization struct {
+	// Key of the pseudonymization hash, the same key results in the same pseudonyms across dumps. Falls back to the SHOPWARE_CLI_ANONYMIZATION_KEY environment variable
+	Key string `yaml:"key,omitempty"`
+	// Preset used by --anonymize, defaults to the built-in default preset
+	Preset string `yaml:"preset,omitempty"`
+	// Custom anonymization presets by name
+	Presets map[string]ConfigDumpAnonymizationPreset `yaml:"presets,omitempty"`
+}
+
+type ConfigDumpAnonymizationPreset struct {
+	// Name of the preset to extend, e.g. default
+	Extends string `yaml:"extends,omitempty"`
+	// Table name as key, and a map of column names to anonymization strategies (first_name, last_name, company, street, zipcode, city, phone, email, username, ip, password, secret, clear, null, sql:<expression>) as value
+	Rules map[string]map[string]string `yaml:"rules,omitempty"`
+}
+
+type ConfigDumpSubset struct {
+	// Tables to start the subset from, schema is table name as key, and where statement as value. Tables referencing them by foreign keys are reduced to the related rows
+	Roots map[string]string `yaml:"roots"`
 }
 
 type ConfigSync struct {
-	Enabled      *[]string          `yaml:"enabled,omitempty" jsonschema:"enum=system_config,enum=mail_template,enum=theme,enum=entity"`
+	Enabled      *[]string          `yaml:"enabled,omitempty" jsonschema:"enum=system_config,enum=mail_template,enum=theme,enum=entity,enum=cms,enum=rule,enum=flow"`
 	Config       []ConfigSyncConfig `yaml:"config,omitempty"`
 	Theme        []ThemeConfig      `yaml:"theme,omitempty"`
 	MailTemplate []MailTemplate     `yaml:"mail_template,omitempty"`
 	Entity       []EntitySync       `yaml:"entity,omitempty"`
+	// CMS layouts with their sections, blocks and slots
+	Cms []CmsLayout `yaml:"cms,omitempty"`
+	// Rule Builder rules with their conditions
+	Rule []Rule `yaml:"rule,omitempty"`
+	// Flow Builder flows with their sequences
+	Flow []Flow `yaml:"flow,omitempty"`
 }
 
 type ConfigDeployment struct {
@@ -145,6 +243,32 @@ type ConfigDeployment struct {
 		Id     string `yaml:"id" jsonschema:"required"`
 		Script string `yaml:"script" jsonschema:"required"`
 	} `yaml:"one-time-tasks"`
+
+	// The release directory layout used by project deploy --release
+	Releases struct {
+		// Directory containing the releases, the shared paths and the current symlink
+		Path string `yaml:"path,omitempty"`
+		// How many old releases are kept besides the active one, defaults to 5
+		Keep int `yaml:"keep,omitempty"`
+		// Paths shared between all releases, defaults to files, public/media, public/thumbnail, public/sitemap, .env.local and var/log
+		Shared []string `yaml:"shared,omitempty"`
+	} `yaml:"releases,omitempty"`
+}
+
+type ConfigExtensions struct {
+	// Desired state of the extensions by name, applied with project extension apply. Extensions not listed are left untouched
+	State map[string]ConfigExtensionState `yaml:"state,omitempty"`
+}
+
+type ConfigExtensionState struct {
+	// Whether the extension is installed, defaults to true when the extension should be active
+	Installed *bool `yaml:"installed,omitempty"`
+	// Whether the extension is active
+	Active *bool `yaml:"active,omitempty"`
+	// Exact version the extension should have, updates the extension when the version is available
+	Version string `yaml:"version,omitempty"`
+	// Extensions which have to be installed and activated before this extension, the composer requirements of project plugins are added automatically
+	DependsOn []string `yaml:"depends_on,omitempty"`
 }
 
 type ConfigDeploymentOverrides map[string]struct {
@@ -191,6 +315,71 @@ type MailTemplate struct {
 	Translations []MailTemplateTranslation `yaml:"translations"`
 }
 
+type CmsLayout struct {
+	// Stable technical key of the layout, the same key results in the same layout in every environment
+	Key  string `yaml:"key" jsonschema:"required"`
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+	// Further fields of the layout like cssClass or config
+	Fields   map[string]interface{} `yaml:"fields,omitempty"`
+	Sections []CmsSection           `yaml:"sections,omitempty"`
+}
+
+type CmsSection struct {
+	Type   string                 `yaml:"type"`
+	Fields map[string]interface{} `yaml:"fields,omitempty"`
+	Blocks []CmsBlock             `yaml:"blocks,omitempty"`
+}
+
+type CmsBlock struct {
+	Type   string                 `yaml:"type"`
+	Fields map[string]interface{} `yaml:"fields,omitempty"`
+	Slots  []CmsSlot              `yaml:"slots,omitempty"`
+}
+
+type CmsSlot struct {
+	Slot   string                 `yaml:"slot"`
+	Type   string                 `yaml:"type"`
+	Config map[string]interface{} `yaml:"config,omitempty"`
+}
+
+type Rule struct {
+	// Stable technical key of the rule, flows reference rules by this key
+	Key         string          `yaml:"key" jsonschema:"required"`
+	Name        string          `yaml:"name"`
+	Priority    int             `yaml:"priority"`
+	Description string          `yaml:"description,omitempty"`
+	Conditions  []RuleCondition `yaml:"conditions,omitempty"`
+}
+
+type RuleCondition struct {
+	Type     string                 `yaml:"type"`
+	Value    map[string]interface{} `yaml:"value,omitempty"`
+	Children []RuleCondition        `yaml:"children,omitempty"`
+}
+
+type Flow struct {
+	// Stable technical key of the flow
+	Key         string         `yaml:"key" jsonschema:"required"`
+	Name        string         `yaml:"name"`
+	EventName   string         `yaml:"event_name"`
+	Priority    int            `yaml:"priority"`
+	Active      bool           `yaml:"active"`
+	Description string         `yaml:"description,omitempty"`
+	Sequences   []FlowSequence `yaml:"sequences,omitempty"`
+}
+
+type FlowSequence struct {
+	// Key of the rule of a condition, the sequences in true and false are executed depending on it
+	Rule string `yaml:"rule,omitempty"`
+	// Name of the action like action.mail.send
+	Action       string                 `yaml:"action,omitempty"`
+	Config       map[string]interface{} `yaml:"config,omitempty"`
+	DisplayGroup int                    `yaml:"display_group,omitempty"`
+	True         []FlowSequence         `yaml:"true,omitempty"`
+	False        []FlowSequence         `yaml:"false,omitempty"`
+}
+
 type EntitySync struct {
 	Entity  string                 `yaml:"entity"`
 	Exists  *[]EntitySyncFilter    `yaml:"exists,omitempty"`
@@ -287,12 +476,69 @@ type ConfigValidationIgnoreExtension struct {
 	Name string `yaml:"name"`
 }
 
+type ConfigWorker struct {
+	// Worker pools started by project worker, each pool consumes its transports with its own amount of workers
+	Pools []ConfigWorkerPool `yaml:"pools,omitempty"`
+	// Seconds between two checks of the queue depth, defaults to 10
+	PollInterval int `yaml:"poll_interval,omitempty"`
+	// Where the queue depth is read from, messenger_messages counts the messages of the Doctrine transport per queue,
+	// message_queue_stats uses the statistics of Shopware which are not separated by transport. Defaults to messenger_messages
+	DepthSource string `yaml:"depth_source,omitempty" jsonschema:"enum=messenger_messages,enum=message_queue_stats"`
+	// Address of the HTTP listener serving /healthz and the Prometheus /metrics, like :9090. Disabled when empty
+	MetricsListen string `yaml:"metrics_listen,omitempty"`
+	// When enabled, project worker also runs scheduled-task:run like --with-scheduler
+	Scheduler bool `yaml:"scheduler,omitempty"`
+	// Seconds a scheduled task may be past its next execution time before it is reported as overdue, defaults to 600
+	OverdueAfter int `yaml:"overdue_after,omitempty"`
+}
+
+type ConfigWorkerPool struct {
+	// Name of the pool, used for the consumer names
+	Name string `yaml:"name" jsonschema:"required"`
+	// Transports consumed by the pool like async, low_priority or failed
+	Transports []string `yaml:"transports" jsonschema:"required"`
+	// Workers which are always running, defaults to 1
+	MinWorkers int `yaml:"min_workers,omitempty"`
+	// Upper limit of workers, the pool is scaled by the queue depth when it is higher than min_workers
+	MaxWorkers int `yaml:"max_workers,omitempty"`
+	// Queued messages per worker, a worker is added when the queue has more messages per worker. Defaults to 100
+	MessagesPerWorker int `yaml:"messages_per_worker,omitempty"`
+	// Seconds the queue has to stay low before a worker is stopped, defaults to 60
+	ScaleDownDelay int `yaml:"scale_down_delay,omitempty"`
+	// Memory limit of a worker, defaults to the --memory-limit flag
+	MemoryLimit string `yaml:"memory_limit,omitempty"`
+	// Time limit of a worker in seconds, defaults to the --time-limit flag
+	TimeLimit string `yaml:"time_limit,omitempty"`
+}
+
 type ConfigImageProxy struct {
 	// The URL of the upstream server to proxy requests to when files are not found locally
 	URL string `yaml:"url,omitempty"`
+	// Format of the thumbnails generated from the original media, when neither the public folder nor the upstream server has them.
+	// original keeps the format of the thumbnail URL, webp converts them to lossless WebP. Defaults to original
+	ThumbnailFormat string `yaml:"thumbnail_format,omitempty" jsonschema:"enum=original,enum=webp"`
 }
 
+// ReadConfig reads the config with the overlay of the environment selected by SetEnvironment or the SHOPWARE_ENV environment variable.
 func ReadConfig(fileName string, allowFallback bool) (*Config, error) {
+	return ReadConfigWithEnvironment(fileName, SelectedEnvironment(), allowFallback)
+}
+
+// ReadConfigWithEnvironment reads the config and applies the overlay of the environment, an empty environment applies no overlay.
+func ReadConfigWithEnvironment(fileName, environment string, allowFallback bool) (*Config, error) {
+	config, err := readConfigFile(fileName, allowFallback)
+	if err != nil {
+		return nil, err
+	}
+
+	if environment == "" || config.IsFallback() {
+		return config, nil
+	}
+
+	return applyConfigEnvironment(config, environment, nil)
+}
+
+func readConfigFile(fileName string, allowFallback bool) (*Config, error) {
 	config := &Config{foundConfig: false}
 
 	_, err := os.Stat(fileName)
@@ -316,12 +562,18 @@ func ReadConfig(fileName string, allowFallback bool) (*Config, error) {
 
 	config.foundConfig = true
 
+	if strictSecrets || os.Getenv(StrictSecretsVariable) == "true" {
+		if err := checkPlaintextSecrets(fileName, fileHandle); err != nil {
+			return nil, err
+		}
+	}
+
 	substitutedConfig := system.ExpandEnv(string(fileHandle))
 	err = yaml.Unmarshal([]byte(substitutedConfig), &config)
 
 	if len(config.AdditionalConfigs) > 0 {
 		for _, additionalConfigFile := range config.AdditionalConfigs {
-			additionalConfig, err := ReadConfig(additionalConfigFile, allowFallback)
+			additionalConfig, err := readConfigFile(additionalConfigFile, allowFallback)
 			if err != nil {
 				return nil, fmt.Errorf("error while reading included config: %s", err.Error())
 			}
@@ -345,6 +597,9 @@ const (
 	SyncOptionMailTemplate = "mail_template"
 	SyncOptionSystemConfig = "system_config"
 	SyncOptionTheme        = "theme"
+	SyncOptionCms          = "cms"
+	SyncOptionRule         = "rule"
+	SyncOptionFlow         = "flow"
 )
 
 func fillEmptyConfig(c *Config) *Config {
@@ -0,0 +1,120 @@
+package shop
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/doutorfinancas/go-mad/core"
+)
+
+// ResolveProfile returns the dump configuration of the profile merged with the profiles it extends.
+// The table rules of the dump section are the base of every profile, an empty name returns only them.
+func (c *ConfigDump) ResolveProfile(name string) (*ConfigDumpProfile, error) {
+	resolved := &ConfigDumpProfile{
+		Rewrite:  map[string]core.Rewrite{},
+		Where:    map[string]string{},
+		NoData:   slices.Clone(c.NoData),
+		Ignore:   slices.Clone(c.Ignore),
+		Subset:   c.Subset,
+		Target:   c.Target,
+		Rotation: c.Rotation,
+	}
+
+	resolved.merge(ConfigDumpProfile{Rewrite: c.Rewrite, Where: c.Where})
+
+	if name == "" {
+		return resolved, nil
+	}
+
+	chain := make([]ConfigDumpProfile, 0)
+	visited := map[string]bool{}
+
+	for current := name; current != ""; {
+		if visited[current] {
+			return nil, fmt.Errorf("dump profile %s has a circular extends", name)
+		}
+
+		profile, ok := c.Profiles[current]
+		if !ok {
+			return nil, fmt.Errorf("dump profile %s not found, available profiles: %v", current, slices.Sorted(maps.Keys(c.Profiles)))
+		}
+
+		visited[current] = true
+		chain = append(chain, profile)
+		current = profile.Extends
+	}
+
+	// Apply the base profiles first, so the selected profile wins
+	for i := len(chain) - 1; i >= 0; i-- {
+		resolved.merge(chain[i])
+	}
+
+	resolved.Extends = ""
+
+	return resolved, nil
+}
+
+func (p *ConfigDumpProfile) merge(other ConfigDumpProfile) {
+	for table, columns := range other.Rewrite {
+		if p.Rewrite[table] == nil {
+			p.Rewrite[table] = core.Rewrite{}
+		}
+
+		maps.Copy(p.Rewrite[table], columns)
+	}
+
+	maps.Copy(p.Where, other.Where)
+
+	for _, table := range other.NoData {
+		if !slices.Contains(p.NoData, table) {
+			p.NoData = append(p.NoData, table)
+		}
+	}
+
+	for _, table := range other.Ignore {
+		if !slices.Contains(p.Ignore, table) {
+			p.Ignore = append(p.Ignore, table)
+		}
+	}
+
+	if other.Subset != nil {
+		p.Subset = other.Subset
+	}
+
+	if other.Target != nil {
+		p.Target = other.Target
+	}
+
+	if other.Rotation != nil {
+		p.Rotation = other.Rotation
+	}
+
+	if other.Clean != nil {
+		p.Clean = other.Clean
+	}
+
+	if other.Anonymize != nil {
+		p.Anonymize = other.Anonymize
+	}
+
+	if other.SchemaOnly != nil {
+		p.SchemaOnly = other.SchemaOnly
+	}
+
+	if other.AnonymizePreset != "" {
+		p.AnonymizePreset = other.AnonymizePreset
+	}
+
+	if other.Compression != "" {
+		p.Compression = other.Compression
+	}
+
+	if other.Format != "" {
+		p.Format = other.Format
+	}
+
+	if other.Output != "" {
+		p.Output = other.Output
+	}
+}
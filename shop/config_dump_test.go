@@ -0,0 +1,64 @@
+package shop
+
+import (
+	"testing"
+
+	"github.com/doutorfinancas/go-mad/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDumpProfile(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	cfg := &ConfigDump{
+		NoData:   []string{"cart"},
+		Rewrite:  map[string]core.Rewrite{"customer": {"email": "'a@example.com'"}},
+		Target:   &ConfigDumpTarget{Type: "s3", Bucket: "backups"},
+		Rotation: &ConfigDumpRotation{Daily: 7},
+		Profiles: map[string]ConfigDumpProfile{
+			"dev": {
+				Clean:       &enabled,
+				Anonymize:   &enabled,
+				Compression: "zstd",
+				Output:      "dumps/{{.Profile}}-{{.Date}}.sql",
+				NoData:      []string{"log_entry", "cart"},
+				Rewrite:     map[string]core.Rewrite{"customer": {"first_name": "'Max'"}},
+			},
+			"qa": {
+				Extends:   "dev",
+				Anonymize: &disabled,
+				Where:     map[string]string{"order": "created_at > '2024-01-01'"},
+				Rotation:  &ConfigDumpRotation{Daily: 3, Weekly: 4},
+			},
+			"loop": {Extends: "loop"},
+		},
+	}
+
+	base, err := cfg.ResolveProfile("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cart"}, base.NoData)
+	assert.Nil(t, base.Clean)
+
+	qa, err := cfg.ResolveProfile("qa")
+	require.NoError(t, err)
+	assert.True(t, *qa.Clean)
+	assert.False(t, *qa.Anonymize)
+	assert.Equal(t, "zstd", qa.Compression)
+	assert.Equal(t, "dumps/{{.Profile}}-{{.Date}}.sql", qa.Output)
+	assert.Equal(t, []string{"cart", "log_entry"}, qa.NoData)
+	assert.Equal(t, core.Rewrite{"email": "'a@example.com'", "first_name": "'Max'"}, qa.Rewrite["customer"])
+	assert.Equal(t, "created_at > '2024-01-01'", qa.Where["order"])
+	assert.Equal(t, "backups", qa.Target.Bucket)
+	assert.Equal(t, &ConfigDumpRotation{Daily: 3, Weekly: 4}, qa.Rotation)
+
+	// Resolving does not modify the configuration
+	assert.Equal(t, core.Rewrite{"email": "'a@example.com'"}, cfg.Rewrite["customer"])
+
+	_, err = cfg.ResolveProfile("loop")
+	assert.ErrorContains(t, err, "circular")
+
+	_, err = cfg.ResolveProfile("missing")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,366 @@
+package shop
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shopware/shopware-cli/internal/system"
+)
+
+// EnvironmentVariable selects the environment overlay, when --env is not given.
+const EnvironmentVariable = "SHOPWARE_ENV"
+
+// explicitEnvironment is the environment selected with SetEnvironment, it takes precedence over SHOPWARE_ENV.
+var explicitEnvironment string
+
+// SetEnvironment selects the environment overlay used by ReadConfig, like the --env flag. Unlike SHOPWARE_ENV,
+// which is often set for the whole machine and is ignored when the config defines no environments, an unknown environment is an error then.
+func SetEnvironment(environment string) {
+	explicitEnvironment = environment
+}
+
+// SelectedEnvironment returns the environment of SetEnvironment or the SHOPWARE_ENV environment variable.
+func SelectedEnvironment() string {
+	if explicitEnvironment != "" {
+		return explicitEnvironment
+	}
+
+	return os.Getenv(EnvironmentVariable)
+}
+
+const (
+	ListMergeReplace = "replace"
+	ListMergeAppend  = "append"
+	ListMergePrepend = "prepend"
+	// ListMergeByKey merges the items with the same value of a field, e.g. merge:name
+	ListMergeByKey = "merge:"
+)
+
+type ConfigEnvironment struct {
+	// Merge strategy of lists by their dotted path without indexes, e.g. sync.config: append. Supported are replace, append, prepend and merge:<field>, lists are replaced by default
+	Lists map[string]string `yaml:"lists,omitempty"`
+	// Overlay with the structure of the project config, which is merged over the config. A null value removes the value
+	Config map[string]interface{} `yaml:"config,omitempty"`
+}
+
+func (ConfigEnvironment) JSONSchemaExtend(schema *jsonschema.Schema) {
+	if config, ok := schema.Properties.Get("config"); ok {
+		config.Type = ""
+		config.Ref = "#/$defs/Config"
+	}
+}
+
+// ConfigSources contains the source file of each value of the config by its dotted path, list items use their index.
+type ConfigSources map[string]string
+
+// ReadConfigWithSources reads the config like ReadConfigWithEnvironment and returns from which file each value is coming from.
+func ReadConfigWithSources(fileName, environment string) (*Config, ConfigSources, error) {
+	config, err := readConfigFile(fileName, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sources := ConfigSources{}
+	if err := collectConfigSources(sources, fileName); err != nil {
+		return nil, nil, err
+	}
+
+	if environment == "" {
+		return config, sources, nil
+	}
+
+	config, err = applyConfigEnvironment(config, environment, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config, sources, nil
+}
+
+// collectConfigSources records the values of the file and its includes in the order ReadConfig merges them, non-empty values of includes win.
+func collectConfigSources(sources ConfigSources, fileName string) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("ReadConfig (%s): %v", fileName, err)
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal([]byte(system.ExpandEnv(string(content))), &document); err != nil {
+		return fmt.Errorf("ReadConfig(%s): %v", fileName, err)
+	}
+
+	walkConfigValues(document, "", func(path string, value interface{}) {
+		if !isEmptyConfigValue(value) {
+			sources[strings.TrimPrefix(path, ".")] = fileName
+		}
+	})
+
+	includes, _ := document["include"].([]interface{})
+
+	for _, include := range includes {
+		if includeFile, ok := include.(string); ok {
+			if err := collectConfigSources(sources, includeFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func applyConfigEnvironment(config *Config, environment string, sources ConfigSources) (*Config, error) {
+	overlay, ok := config.Environments[environment]
+	if !ok && len(config.Environments) == 0 && explicitEnvironment == "" {
+		return config, nil
+	}
+
+	if !ok && len(config.Environments) == 0 {
+		return nil, fmt.Errorf("unknown environment \"%s\", the config defines no environments", environment)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("unknown environment \"%s\", available environments: %s", environment, strings.Join(slices.Sorted(maps.Keys(config.Environments)), ", "))
+	}
+
+	for path, strategy := range overlay.Lists {
+		if !isValidListMerge(strategy) {
+			return nil, fmt.Errorf("environment %s: unknown merge strategy \"%s\" for list %s", environment, strategy, path)
+		}
+	}
+
+	content, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	merger := configOverlay{lists: overlay.Lists, sources: sources, environment: environment}
+
+	merged := merger.merge(document, overlay.Config, "", "", "environments."+environment+".config")
+
+	content, err = yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Config{foundConfig: true}
+	if err := yaml.Unmarshal(content, result); err != nil {
+		return nil, fmt.Errorf("environment %s: %w", environment, err)
+	}
+
+	return fillEmptyConfig(result), nil
+}
+
+func isValidListMerge(strategy string) bool {
+	switch strategy {
+	case ListMergeReplace, ListMergeAppend, ListMergePrepend:
+		return true
+	}
+
+	return strings.HasPrefix(strategy, ListMergeByKey) && len(strategy) > len(ListMergeByKey)
+}
+
+// configOverlay merges an environment overlay and keeps the sources of the values up to date, when they are tracked.
+type configOverlay struct {
+	lists       map[string]string
+	sources     ConfigSources
+	environment string
+}
+
+// merge merges the overlay into the base. The path is the position in the config, the list path the same without list indexes
+// and the origin the position of the overlay value in the config file.
+func (o configOverlay) merge(base, overlay interface{}, path, listPath, origin string) interface{} {
+	switch overlayValue := overlay.(type) {
+	case map[string]interface{}:
+		baseMap, ok := base.(map[string]interface{})
+		if !ok {
+			baseMap = map[string]interface{}{}
+			o.forget(path)
+		}
+
+		merged := maps.Clone(baseMap)
+
+		for _, key := range slices.Sorted(maps.Keys(overlayValue)) {
+			if overlayValue[key] == nil {
+				delete(merged, key)
+				o.forget(joinConfigPath(path, key))
+
+				continue
+			}
+
+			merged[key] = o.merge(merged[key], overlayValue[key], joinConfigPath(path, key), joinConfigPath(listPath, key), joinConfigPath(origin, key))
+		}
+
+		return merged
+	case []interface{}:
+		baseList, _ := base.([]interface{})
+
+		return o.mergeList(baseList, overlayValue, path, listPath, origin)
+	default:
+		o.replace(path, origin, overlay)
+
+		return overlay
+	}
+}
+
+func (o configOverlay) mergeList(base, overlay []interface{}, path, listPath, origin string) []interface{} {
+	strategy := cmp.Or(o.lists[listPath], ListMergeReplace)
+
+	switch strategy {
+	case ListMergeReplace:
+		o.replace(path, origin, overlay)
+
+		return overlay
+	case ListMergeAppend:
+		for i, item := range overlay {
+			o.replace(joinConfigPath(path, strconv.Itoa(len(base)+i)), joinConfigPath(origin, strconv.Itoa(i)), item)
+		}
+
+		return append(slices.Clone(base), overlay...)
+	case ListMergePrepend:
+		o.shift(path, len(overlay))
+
+		for i, item := range overlay {
+			o.replace(joinConfigPath(path, strconv.Itoa(i)), joinConfigPath(origin, strconv.Itoa(i)), item)
+		}
+
+		return append(slices.Clone(overlay), base...)
+	}
+
+	field := strings.TrimPrefix(strategy, ListMergeByKey)
+	merged := slices.Clone(base)
+
+	for i, item := range overlay {
+		index := slices.IndexFunc(merged, func(candidate interface{}) bool {
+			return sameConfigKey(candidate, item, field)
+		})
+
+		if index == -1 {
+			index = len(merged)
+			merged = append(merged, nil)
+		}
+
+		merged[index] = o.merge(merged[index], item, joinConfigPath(path, strconv.Itoa(index)), listPath, joinConfigPath(origin, strconv.Itoa(i)))
+	}
+
+	return merged
+}
+
+func sameConfigKey(a, b interface{}, field string) bool {
+	aMap, aOk := a.(map[string]interface{})
+	bMap, bOk := b.(map[string]interface{})
+
+	if !aOk || !bOk || aMap[field] == nil {
+		return false
+	}
+
+	return fmt.Sprint(aMap[field]) == fmt.Sprint(bMap[field])
+}
+
+// replace records the values of the overlay as the sources below the path.
+func (o configOverlay) replace(path, origin string, value interface{}) {
+	if o.sources == nil {
+		return
+	}
+
+	o.forget(path)
+
+	walkConfigValues(value, "", func(relative string, _ interface{}) {
+		file := cmp.Or(o.sources[origin+relative], "unknown")
+		o.sources[path+relative] = fmt.Sprintf("%s (environment %s)", file, o.environment)
+	})
+}
+
+func (o configOverlay) forget(path string) {
+	for key := range o.sources {
+		if key == path || strings.HasPrefix(key, path+".") {
+			delete(o.sources, key)
+		}
+	}
+}
+
+// shift moves the sources of the list items at the path by the offset.
+func (o configOverlay) shift(path string, offset int) {
+	if o.sources == nil {
+		return
+	}
+
+	shifted := ConfigSources{}
+
+	for key, source := range o.sources {
+		relative, found := strings.CutPrefix(key, path+".")
+		if !found {
+			continue
+		}
+
+		index, rest, ok := strings.Cut(relative, ".")
+
+		position, err := strconv.Atoi(index)
+		if err != nil {
+			continue
+		}
+
+		delete(o.sources, key)
+
+		if ok {
+			rest = "." + rest
+		}
+
+		shifted[joinConfigPath(path, strconv.Itoa(position+offset))+rest] = source
+	}
+
+	maps.Copy(o.sources, shifted)
+}
+
+// walkConfigValues calls the callback for each scalar value with the prefix and its dotted path below the value, e.g. .sync.config.
+func walkConfigValues(value interface{}, prefix string, callback func(path string, value interface{})) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			walkConfigValues(child, prefix+"."+key, callback)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			walkConfigValues(child, prefix+"."+strconv.Itoa(i), callback)
+		}
+	default:
+		callback(prefix, value)
+	}
+}
+
+func isEmptyConfigValue(value interface{}) bool {
+	switch typed := value.(type) {
+	case nil:
+		return true
+	case string:
+		return typed == ""
+	case bool:
+		return !typed
+	case int:
+		return typed == 0
+	case float64:
+		return typed == 0
+	}
+
+	return false
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
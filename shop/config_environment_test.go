@@ -0,0 +1,144 @@
+package shop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEnvironmentConfig(t *testing.T) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "base.yml"), []byte(`
+admin_api:
+  client_id: abc
+  client_secret: secret
+build:
+  cleanup_paths: [a, b]
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".shopware-project.yml"), []byte(`
+url: https://dev.local
+include:
+  - base.yml
+sync:
+  theme:
+    - name: Storefront
+      settings:
+        sw-color-brand-primary:
+          value: "#000"
+  config:
+    - settings:
+        core.basicInformation.email: dev@example.com
+environments:
+  prod:
+    lists:
+      sync.config: append
+      sync.theme: merge:name
+      build.cleanup_paths: prepend
+    config:
+      url: https://shop.example.com
+      admin_api:
+        client_secret: null
+      build:
+        cleanup_paths: [var/prod]
+      sync:
+        theme:
+          - name: Storefront
+            settings:
+              sw-color-brand-secondary:
+                value: "#fff"
+        config:
+          - settings:
+              core.mailerSettings.host: smtp.example.com
+`), 0o644))
+
+	return ".shopware-project.yml"
+}
+
+func TestConfigEnvironmentOverlay(t *testing.T) {
+	fileName := writeEnvironmentConfig(t)
+
+	config, err := ReadConfigWithEnvironment(fileName, "", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev.local", config.URL)
+
+	config, err = ReadConfigWithEnvironment(fileName, "prod", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://shop.example.com", config.URL)
+	assert.Equal(t, "abc", config.AdminApi.ClientId)
+	assert.Empty(t, config.AdminApi.ClientSecret)
+	assert.Equal(t, []string{"var/prod", "a", "b"}, config.Build.CleanupPaths)
+	assert.False(t, config.IsFallback())
+
+	require.Len(t, config.Sync.Config, 2)
+	assert.Equal(t, "smtp.example.com", config.Sync.Config[1].Settings["core.mailerSettings.host"])
+
+	require.Len(t, config.Sync.Theme, 1)
+	assert.Len(t, config.Sync.Theme[0].Settings, 2)
+
+	t.Setenv(EnvironmentVariable, "prod")
+
+	config, err = ReadConfig(fileName, false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://shop.example.com", config.URL)
+
+	_, err = ReadConfigWithEnvironment(fileName, "staging", false)
+	assert.ErrorContains(t, err, `unknown environment "staging", available environments: prod`)
+}
+
+func TestConfigEnvironmentSources(t *testing.T) {
+	fileName := writeEnvironmentConfig(t)
+
+	_, sources, err := ReadConfigWithSources(fileName, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, fileName, sources["url"])
+	assert.Equal(t, "base.yml", sources["build.cleanup_paths.1"])
+
+	_, sources, err = ReadConfigWithSources(fileName, "prod")
+	require.NoError(t, err)
+
+	overlay := fileName + " (environment prod)"
+
+	assert.Equal(t, overlay, sources["url"])
+	assert.Equal(t, "base.yml", sources["admin_api.client_id"])
+	assert.NotContains(t, sources, "admin_api.client_secret")
+	assert.Equal(t, overlay, sources["build.cleanup_paths.0"])
+	assert.Equal(t, "base.yml", sources["build.cleanup_paths.2"])
+	assert.Equal(t, fileName, sources["sync.config.0.settings.core.basicInformation.email"])
+	assert.Equal(t, overlay, sources["sync.config.1.settings.core.mailerSettings.host"])
+	assert.Equal(t, fileName, sources["sync.theme.0.settings.sw-color-brand-primary.value"])
+	assert.Equal(t, overlay, sources["sync.theme.0.settings.sw-color-brand-secondary.value"])
+}
+
+func TestConfigEnvironmentUnknownListMerge(t *testing.T) {
+	config := &Config{Environments: map[string]ConfigEnvironment{
+		"prod": {Lists: map[string]string{"build.cleanup_paths": "merge:"}},
+	}}
+
+	_, err := applyConfigEnvironment(config, "prod", nil)
+	assert.ErrorContains(t, err, `unknown merge strategy "merge:" for list build.cleanup_paths`)
+}
+
+func TestConfigEnvironmentWithoutEnvironments(t *testing.T) {
+	config := &Config{URL: "https://dev.local"}
+
+	// SHOPWARE_ENV is often set for the whole machine, without environments in the config it is ignored
+	result, err := applyConfigEnvironment(config, "prod", nil)
+	require.NoError(t, err)
+	assert.Same(t, config, result)
+
+	SetEnvironment("prod")
+	t.Cleanup(func() { SetEnvironment("") })
+
+	_, err = applyConfigEnvironment(config, "prod", nil)
+	assert.ErrorContains(t, err, `unknown environment "prod", the config defines no environments`)
+}
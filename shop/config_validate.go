@@ -0,0 +1,516 @@
+package shop
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/shopware/shopware-cli/internal/system"
+	"github.com/shopware/shopware-cli/internal/validation"
+)
+
+//go:embed shopware-project-schema.json
+var projectSchemaJSON []byte
+
+const (
+	ConfigIdentifierSyntax      = "config.syntax"
+	ConfigIdentifierSchema      = "config.schema"
+	ConfigIdentifierUnknownKey  = "config.unknown-key"
+	ConfigIdentifierDeprecation = "config.deprecated-key"
+)
+
+// configMigration renames a deprecated key, the path points to the mapping containing the key.
+type configMigration struct {
+	Path []string
+	From string
+	To   string
+}
+
+var configMigrations = []configMigration{
+	{Path: []string{"deployment", "extension-management"}, From: "force_updates", To: "force-update"},
+}
+
+var yamlErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+
+type configSchema struct {
+	Ref                  string                   `json:"$ref"`
+	Defs                 map[string]*configSchema `json:"$defs"`
+	Type                 string                   `json:"type"`
+	Properties           map[string]*configSchema `json:"properties"`
+	AdditionalProperties *configSchema            `json:"additionalProperties"`
+	Items                *configSchema            `json:"items"`
+	Enum                 []interface{}            `json:"enum"`
+	Required             []string                 `json:"required"`
+	// Rejected is set by the boolean schema false, which matches no value
+	Rejected bool `json:"-"`
+}
+
+// UnmarshalJSON supports the boolean schemas true and false besides schema objects.
+func (s *configSchema) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true":
+		*s = configSchema{}
+
+		return nil
+	case "false":
+		*s = configSchema{Rejected: true}
+
+		return nil
+	}
+
+	type plainSchema configSchema
+
+	return json.Unmarshal(data, (*plainSchema)(s))
+}
+
+// ValidateConfigFile validates the config file and its includes against the bundled JSON schema.
+// Unknown keys, wrong types and deprecated keys are reported with their position in the file.
+func ValidateConfigFile(fileName string) ([]validation.CheckResult, error) {
+	var schema configSchema
+	if err := json.Unmarshal(projectSchemaJSON, &schema); err != nil {
+		return nil, fmt.Errorf("cannot parse the project config schema: %w", err)
+	}
+
+	validator := configValidator{root: &schema}
+
+	if err := validator.validateFile(fileName, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	return validator.results, nil
+}
+
+type configValidator struct {
+	root    *configSchema
+	file    string
+	results []validation.CheckResult
+}
+
+func (v *configValidator) validateFile(fileName string, visited map[string]bool) error {
+	if visited[fileName] {
+		return nil
+	}
+
+	visited[fileName] = true
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("cannot read config %s: %w", fileName, err)
+	}
+
+	v.file = fileName
+	expanded := []byte(system.ExpandEnv(string(content)))
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(expanded, &document); err != nil {
+		v.addError(yamlErrorLine(err.Error()), 0, ConfigIdentifierSyntax, err.Error())
+
+		return nil
+	}
+
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+
+	reported := len(v.results)
+	v.validate(root, v.root, "")
+
+	// Decoding errors repeat the schema errors, they only add value for a file matching the schema
+	if len(v.results) == reported {
+		v.checkStrictDecoding(expanded)
+	}
+
+	for _, migration := range configMigrations {
+		if key, _ := findMigrationKey(root, migration); key != nil {
+			v.results = append(v.results, validation.CheckResult{
+				Path:       v.file,
+				Line:       key.Line,
+				Column:     key.Column,
+				Message:    fmt.Sprintf("%s is deprecated, use %s instead", strings.Join(append(slices.Clone(migration.Path), migration.From), "."), migration.To),
+				Severity:   validation.SeverityWarning,
+				Identifier: ConfigIdentifierDeprecation,
+			})
+		}
+	}
+
+	var includes struct {
+		Include []string `yaml:"include"`
+	}
+
+	if err := root.Decode(&includes); err != nil {
+		return nil
+	}
+
+	for _, include := range includes.Include {
+		if err := v.validateFile(include, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkStrictDecoding reports the errors of decoding the file into the config, unknown keys are already reported by the schema.
+func (v *configValidator) checkStrictDecoding(content []byte) {
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+
+	var config Config
+
+	err := decoder.Decode(&config)
+	if err == nil {
+		return
+	}
+
+	var typeError *yaml.TypeError
+	if !errors.As(err, &typeError) {
+		v.addError(yamlErrorLine(err.Error()), 0, ConfigIdentifierSyntax, err.Error())
+
+		return
+	}
+
+	for _, message := range typeError.Errors {
+		if strings.Contains(message, "not found in type") {
+			continue
+		}
+
+		v.addError(yamlErrorLine(message), 0, ConfigIdentifierSchema, message)
+	}
+}
+
+func (v *configValidator) resolve(schema *configSchema) *configSchema {
+	for schema != nil && schema.Ref != "" {
+		schema = v.root.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")]
+	}
+
+	return schema
+}
+
+func (v *configValidator) validate(node *yaml.Node, schema *configSchema, path string) {
+	schema = v.resolve(schema)
+
+	if schema == nil || node.Tag == "!!null" {
+		return
+	}
+
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if schema.Rejected {
+		v.addError(node.Line, node.Column, ConfigIdentifierSchema, fmt.Sprintf("%s is not allowed", displayConfigPath(path)))
+
+		return
+	}
+
+	if !matchesSchemaType(node, schema.Type) {
+		v.addError(node.Line, node.Column, ConfigIdentifierSchema, fmt.Sprintf("%s must be of type %s", displayConfigPath(path), schema.Type))
+
+		return
+	}
+
+	if len(schema.Enum) > 0 && node.Kind == yaml.ScalarNode && !slices.ContainsFunc(schema.Enum, func(value interface{}) bool { return fmt.Sprint(value) == node.Value }) {
+		v.addError(node.Line, node.Column, ConfigIdentifierSchema, fmt.Sprintf("%s must be one of %s, got %s", displayConfigPath(path), formatEnum(schema.Enum), node.Value))
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		v.validateMapping(node, schema, path)
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			v.validate(item, schema.Items, joinConfigPath(path, strconv.Itoa(i)))
+		}
+	case yaml.DocumentNode, yaml.ScalarNode, yaml.AliasNode:
+	}
+}
+
+func (v *configValidator) validateMapping(node *yaml.Node, schema *configSchema, path string) {
+	keys := map[string]bool{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		keys[key.Value] = true
+		keyPath := joinConfigPath(path, key.Value)
+
+		if property, ok := schema.Properties[key.Value]; ok {
+			v.validate(value, property, keyPath)
+
+			continue
+		}
+
+		if schema.AdditionalProperties == nil {
+			continue
+		}
+
+		if !schema.AdditionalProperties.Rejected {
+			v.validate(value, schema.AdditionalProperties, keyPath)
+
+			continue
+		}
+
+		message := fmt.Sprintf("unknown key %s", keyPath)
+
+		if suggestion := suggestConfigKey(key.Value, schema.Properties); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+
+		v.addError(key.Line, key.Column, ConfigIdentifierUnknownKey, message)
+	}
+
+	for _, required := range schema.Required {
+		if !keys[required] {
+			v.addError(node.Line, node.Column, ConfigIdentifierSchema, fmt.Sprintf("%s is required", joinConfigPath(path, required)))
+		}
+	}
+}
+
+func (v *configValidator) addError(line, column int, identifier, message string) {
+	v.results = append(v.results, validation.CheckResult{
+		Path:       v.file,
+		Line:       line,
+		Column:     column,
+		Message:    message,
+		Severity:   validation.SeverityError,
+		Identifier: identifier,
+	})
+}
+
+func matchesSchemaType(node *yaml.Node, schemaType string) bool {
+	switch schemaType {
+	case "object":
+		return node.Kind == yaml.MappingNode
+	case "array":
+		return node.Kind == yaml.SequenceNode
+	case "string":
+		return node.Kind == yaml.ScalarNode
+	case "boolean":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!bool"
+	case "integer":
+		return node.Kind == yaml.ScalarNode && node.Tag == "!!int"
+	case "number":
+		return node.Kind == yaml.ScalarNode && (node.Tag == "!!int" || node.Tag == "!!float")
+	}
+
+	return true
+}
+
+func formatEnum(values []interface{}) string {
+	formatted := make([]string, 0, len(values))
+
+	for _, value := range values {
+		formatted = append(formatted, fmt.Sprint(value))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+func displayConfigPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+
+	return path
+}
+
+func yamlErrorLine(message string) int {
+	matches := yamlErrorLineRegex.FindStringSubmatch(message)
+	if matches == nil {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(matches[1])
+
+	return line
+}
+
+// suggestConfigKey returns the known key closest to a misspelled key.
+func suggestConfigKey(key string, properties map[string]*configSchema) string {
+	best, bestDistance := "", 3
+
+	for _, candidate := range slices.Sorted(maps.Keys(properties)) {
+		if distance := levenshtein(key, candidate); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+// FixConfigFile migrates the deprecated keys of the config file and its includes, comments are kept.
+// It returns the files which have been changed.
+func FixConfigFile(fileName string) ([]string, error) {
+	return fixConfigFile(fileName, map[string]bool{})
+}
+
+func fixConfigFile(fileName string, visited map[string]bool) ([]string, error) {
+	if visited[fileName] {
+		return nil, nil
+	}
+
+	visited[fileName] = true
+
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config %s: %w", fileName, err)
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("cannot parse config %s: %w", fileName, err)
+	}
+
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	changed := false
+
+	for _, migration := range configMigrations {
+		if migrateConfigKey(document.Content[0], migration) {
+			changed = true
+		}
+	}
+
+	var fixed []string
+
+	if changed {
+		var buf strings.Builder
+
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+
+		if err := encoder.Encode(&document); err != nil {
+			return nil, err
+		}
+
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(fileName, []byte(buf.String()), 0o644); err != nil {
+			return nil, err
+		}
+
+		fixed = append(fixed, fileName)
+	}
+
+	var includes struct {
+		Include []string `yaml:"include"`
+	}
+
+	if err := document.Content[0].Decode(&includes); err != nil {
+		return fixed, nil
+	}
+
+	for _, include := range includes.Include {
+		includeFixed, err := fixConfigFile(include, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		fixed = append(fixed, includeFixed...)
+	}
+
+	return fixed, nil
+}
+
+// findMigrationKey returns the deprecated key node and the mapping containing it.
+func findMigrationKey(root *yaml.Node, migration configMigration) (*yaml.Node, *yaml.Node) {
+	mapping := root
+
+	for _, key := range migration.Path {
+		mapping = mappingValue(mapping, key)
+		if mapping == nil {
+			return nil, nil
+		}
+	}
+
+	if mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == migration.From {
+			return mapping.Content[i], mapping
+		}
+	}
+
+	return nil, nil
+}
+
+// migrateConfigKey renames the deprecated key, lists are appended to an already existing new key.
+func migrateConfigKey(root *yaml.Node, migration configMigration) bool {
+	key, mapping := findMigrationKey(root, migration)
+	if key == nil {
+		return false
+	}
+
+	index := slices.Index(mapping.Content, key)
+	value := mapping.Content[index+1]
+
+	existing := mappingValue(mapping, migration.To)
+	if existing == nil {
+		key.Value = migration.To
+
+		return true
+	}
+
+	if existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode {
+		existing.Content = append(existing.Content, value.Content...)
+	}
+
+	mapping.Content = slices.Delete(mapping.Content, index, index+2)
+
+	return true
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package shop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shopware/shopware-cli/internal/validation"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "base.yml"), []byte(`
+admin_api:
+  disable_ssl_check: "no"
+`), 0o644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".shopware-project.yml"), []byte(`url: https://localhost
+include:
+  - base.yml
+build:
+  disable_storefront_biuld: true
+deployment:
+  extension-management:
+    force_updates: [Foo]
+sync:
+  enabled: [theme, bogus]
+environments:
+  prod:
+    config:
+      url: https://shop.example.com
+      admin_api: null
+`), 0o644))
+
+	results, err := ValidateConfigFile(".shopware-project.yml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []validation.CheckResult{
+		{Path: ".shopware-project.yml", Line: 5, Column: 3, Severity: validation.SeverityError, Identifier: ConfigIdentifierUnknownKey, Message: "unknown key build.disable_storefront_biuld, did you mean disable_storefront_build?"},
+		{Path: ".shopware-project.yml", Line: 10, Column: 20, Severity: validation.SeverityError, Identifier: ConfigIdentifierSchema, Message: "sync.enabled.1 must be one of system_config, mail_template, theme, entity, cms, rule, flow, got bogus"},
+		{Path: ".shopware-project.yml", Line: 8, Column: 5, Severity: validation.SeverityWarning, Identifier: ConfigIdentifierDeprecation, Message: "deployment.extension-management.force_updates is deprecated, use force-update instead"},
+		{Path: "base.yml", Line: 3, Column: 22, Severity: validation.SeverityError, Identifier: ConfigIdentifierSchema, Message: "admin_api.disable_ssl_check must be of type boolean"},
+	}, results)
+}
+
+func TestValidateConfigFileSyntaxError(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ".shopware-project.yml")
+	require.NoError(t, os.WriteFile(fileName, []byte("url: [\n"), 0o644))
+
+	results, err := ValidateConfigFile(fileName)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, ConfigIdentifierSyntax, results[0].Identifier)
+}
+
+func TestFixConfigFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), ".shopware-project.yml")
+
+	require.NoError(t, os.WriteFile(fileName, []byte(`deployment:
+  extension-management:
+    # always update
+    force_updates:
+      - Foo
+    force-update:
+      - Bar
+`), 0o644))
+
+	fixed, err := FixConfigFile(fileName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{fileName}, fixed)
+
+	config, err := ReadConfig(fileName, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Bar", "Foo"}, config.ConfigDeployment.ExtensionManagement.ForceUpdate)
+	assert.Empty(t, config.ConfigDeployment.ExtensionManagement.ForceUpdatesDeprecated)
+
+	fixed, err = FixConfigFile(fileName)
+	require.NoError(t, err)
+	assert.Empty(t, fixed)
+}
@@ -0,0 +1,215 @@
+package shop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/doutorfinancas/go-mad/core"
+	"gopkg.in/yaml.v3"
+
+	"github.com/shopware/shopware-cli/internal/system"
+	"github.com/shopware/shopware-cli/logging"
+)
+
+// SecretPrefix starts a reference to a secret like secret://env/NAME, secret://file/path or secret://cmd/command.
+const SecretPrefix = "secret://"
+
+// StrictSecretsVariable rejects plaintext secrets in the config files, when set to true.
+const StrictSecretsVariable = "SHOPWARE_STRICT_SECRETS"
+
+// strictSecrets is enabled with SetStrictSecrets, like setting StrictSecretsVariable.
+var strictSecrets bool
+
+// SetStrictSecrets rejects plaintext secrets in the config files read afterwards, like the --strict-secrets flag.
+func SetStrictSecrets(strict bool) {
+	strictSecrets = strict
+}
+
+// secretFields are the paths of the config values which are secrets.
+var secretFields = [][]string{
+	{"admin_api", "client_secret"},
+	{"admin_api", "password"},
+	{"dump", "target", "secret_access_key"},
+}
+
+var (
+	resolvedSecretsMu sync.Mutex
+	resolvedSecrets   = map[string]string{}
+)
+
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, SecretPrefix)
+}
+
+// ResolveSecret returns the value of a secret reference, other values are returned unchanged.
+// Resolved values are cached and masked in the log output.
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	if !IsSecretReference(value) {
+		return value, nil
+	}
+
+	resolvedSecretsMu.Lock()
+	defer resolvedSecretsMu.Unlock()
+
+	if resolved, ok := resolvedSecrets[value]; ok {
+		return resolved, nil
+	}
+
+	provider, target, _ := strings.Cut(strings.TrimPrefix(value, SecretPrefix), "/")
+
+	if target == "" {
+		return "", fmt.Errorf("secret reference %s has no target", value)
+	}
+
+	var resolved string
+
+	switch provider {
+	case "env":
+		var ok bool
+		if resolved, ok = os.LookupEnv(target); !ok {
+			return "", fmt.Errorf("secret reference %s: environment variable %s is not set", value, target)
+		}
+	case "file":
+		content, err := os.ReadFile(target)
+		if err != nil {
+			return "", fmt.Errorf("secret reference %s: %w", value, err)
+		}
+
+		resolved = strings.TrimRight(string(content), "\r\n")
+	case "cmd":
+		output, err := runSecretCommand(ctx, target)
+		if err != nil {
+			return "", fmt.Errorf("secret reference %s: %w", value, err)
+		}
+
+		resolved = output
+	default:
+		return "", fmt.Errorf("secret reference %s has the unknown provider %s, supported are env, file and cmd", value, provider)
+	}
+
+	logging.RegisterSecret(resolved)
+	resolvedSecrets[value] = resolved
+
+	return resolved, nil
+}
+
+func runSecretCommand(ctx context.Context, command string) (string, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("command %s failed: %w", command, err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// ResolveRewriteSecrets replaces the secret references in the dump rewrites with their values.
+func ResolveRewriteSecrets(ctx context.Context, rewrites map[string]core.Rewrite) error {
+	for table, columns := range rewrites {
+		for column, rewrite := range columns {
+			resolved, err := ResolveSecret(ctx, rewrite)
+			if err != nil {
+				return fmt.Errorf("rewrite of %s.%s: %w", table, column, err)
+			}
+
+			columns[column] = resolved
+		}
+	}
+
+	return nil
+}
+
+// RedactSecrets replaces the plaintext secrets of the config, references are kept as they do not contain the secret.
+func (c *Config) RedactSecrets() {
+	var fields []*string
+
+	if c.AdminApi != nil {
+		fields = append(fields, &c.AdminApi.ClientSecret, &c.AdminApi.Password)
+	}
+
+	if c.ConfigDump != nil {
+		if c.ConfigDump.Target != nil {
+			fields = append(fields, &c.ConfigDump.Target.SecretAccessKey)
+		}
+
+		for _, profile := range c.ConfigDump.Profiles {
+			if profile.Target != nil {
+				fields = append(fields, &profile.Target.SecretAccessKey)
+			}
+		}
+	}
+
+	for _, field := range fields {
+		if *field != "" && !IsSecretReference(*field) {
+			*field = "[redacted]"
+		}
+	}
+}
+
+// checkPlaintextSecrets rejects secrets written directly into the config file. Secret references and
+// values consisting only of ${NAME} environment variables are allowed, as their values are not part of the file.
+func checkPlaintextSecrets(fileName string, content []byte) error {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return fmt.Errorf("ReadConfig(%s): %v", fileName, err)
+	}
+
+	roots := map[string]interface{}{"": document}
+
+	environments, _ := document["environments"].(map[string]interface{})
+	for name, environment := range environments {
+		if environmentMap, ok := environment.(map[string]interface{}); ok {
+			roots["environments."+name+".config."] = environmentMap["config"]
+		}
+	}
+
+	for prefix, root := range roots {
+		fields := slices.Clone(secretFields)
+
+		// Every dump profile can have its own target
+		profiles, _ := lookupConfigValue(root, []string{"dump", "profiles"}).(map[string]interface{})
+		for _, name := range slices.Sorted(maps.Keys(profiles)) {
+			fields = append(fields, []string{"dump", "profiles", name, "target", "secret_access_key"})
+		}
+
+		for _, field := range fields {
+			value, _ := lookupConfigValue(root, field).(string)
+
+			if value != "" && !IsSecretReference(value) && !system.IsEnvReference(value) {
+				return fmt.Errorf("%s contains the plaintext secret %s%s, use a secret:// reference or an environment variable instead", fileName, prefix, strings.Join(field, "."))
+			}
+		}
+	}
+
+	return nil
+}
+
+func lookupConfigValue(value interface{}, path []string) interface{} {
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		value = m[key]
+	}
+
+	return value
+}
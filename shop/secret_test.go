@@ -0,0 +1,135 @@
+package shop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doutorfinancas/go-mad/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("SHOPWARE_TEST_SECRET", "from-env")
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0o600))
+
+	value, err := ResolveSecret(t.Context(), "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	value, err = ResolveSecret(t.Context(), "secret://env/SHOPWARE_TEST_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+
+	value, err = ResolveSecret(t.Context(), "secret://file/"+secretFile)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", value)
+
+	value, err = ResolveSecret(t.Context(), "secret://cmd/echo from-cmd")
+	require.NoError(t, err)
+	assert.Equal(t, "from-cmd", value)
+
+	_, err = ResolveSecret(t.Context(), "secret://env/SHOPWARE_TEST_SECRET_MISSING")
+	assert.ErrorContains(t, err, "environment variable SHOPWARE_TEST_SECRET_MISSING is not set")
+
+	_, err = ResolveSecret(t.Context(), "secret://vault/key")
+	assert.ErrorContains(t, err, "unknown provider vault")
+
+	rewrites := map[string]core.Rewrite{"customer": {"password": "secret://env/SHOPWARE_TEST_SECRET", "email": "faker.Internet().Email()"}}
+	require.NoError(t, ResolveRewriteSecrets(t.Context(), rewrites))
+	assert.Equal(t, core.Rewrite{"password": "from-env", "email": "faker.Internet().Email()"}, rewrites["customer"])
+}
+
+func TestConfigStrictSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+	t.Setenv(StrictSecretsVariable, "true")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "allowed.yml"), []byte(`
+admin_api:
+  client_id: id
+  client_secret: secret://env/SHOPWARE_CLIENT_SECRET
+  password: ${SHOPWARE_PASSWORD}
+`), 0o644))
+
+	config, err := ReadConfig("allowed.yml", false)
+	require.NoError(t, err)
+	assert.Equal(t, "secret://env/SHOPWARE_CLIENT_SECRET", config.AdminApi.ClientSecret)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "plaintext.yml"), []byte(`
+environments:
+  prod:
+    config:
+      admin_api:
+        client_secret: very-secret
+`), 0o644))
+
+	_, err = ReadConfig("plaintext.yml", false)
+	assert.ErrorContains(t, err, "plaintext secret environments.prod.config.admin_api.client_secret")
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "include.yml"), []byte(`
+include:
+  - plaintext.yml
+`), 0o644))
+
+	_, err = ReadConfig("include.yml", false)
+	assert.ErrorContains(t, err, "plaintext.yml contains the plaintext secret")
+
+	// Generated passwords often contain a $, which is no environment variable
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "dollar.yml"), []byte("admin_api:\n  password: 'pa$$w0rd'\n"), 0o644))
+
+	_, err = ReadConfig("dollar.yml", false)
+	assert.ErrorContains(t, err, "plaintext secret admin_api.password")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "dump.yml"), []byte(`
+dump:
+  target:
+    type: s3
+    secret_access_key: secret://env/AWS_SECRET
+  profiles:
+    nightly:
+      target:
+        type: s3
+        secret_access_key: very-secret
+`), 0o644))
+
+	_, err = ReadConfig("dump.yml", false)
+	assert.ErrorContains(t, err, "plaintext secret dump.profiles.nightly.target.secret_access_key")
+}
+
+func TestConfigRedactSecrets(t *testing.T) {
+	config := &Config{
+		AdminApi: &ConfigAdminApi{ClientId: "id", ClientSecret: "very-secret", Password: "secret://cmd/pass shop"},
+		ConfigDump: &ConfigDump{
+			Target:   &ConfigDumpTarget{Type: "s3", AccessKeyID: "key", SecretAccessKey: "s3-secret"},
+			Profiles: map[string]ConfigDumpProfile{"nightly": {Target: &ConfigDumpTarget{Type: "s3", SecretAccessKey: "profile-secret"}}},
+		},
+	}
+	config.RedactSecrets()
+
+	assert.Equal(t, "id", config.AdminApi.ClientId)
+	assert.Equal(t, "[redacted]", config.AdminApi.ClientSecret)
+	assert.Equal(t, "secret://cmd/pass shop", config.AdminApi.Password)
+	assert.Equal(t, "key", config.ConfigDump.Target.AccessKeyID)
+	assert.Equal(t, "[redacted]", config.ConfigDump.Target.SecretAccessKey)
+	assert.Equal(t, "[redacted]", config.ConfigDump.Profiles["nightly"].Target.SecretAccessKey)
+}
+
+func TestSetStrictSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "plaintext.yml"), []byte("admin_api:\n  client_secret: very-secret\n"), 0o644))
+
+	_, err := ReadConfig("plaintext.yml", false)
+	require.NoError(t, err)
+
+	SetStrictSecrets(true)
+	t.Cleanup(func() { SetStrictSecrets(false) })
+
+	_, err = ReadConfig("plaintext.yml", false)
+	assert.ErrorContains(t, err, "plaintext secret admin_api.client_secret")
+	assert.Empty(t, os.Getenv(StrictSecretsVariable))
+}